@@ -0,0 +1,65 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// ElasticIPsAttribute holds the allocation IDs of the Elastic IPs associated
+// with an instance. aws_instance has no such attribute, so in state it is
+// derived from the aws_eip and aws_eip_association resources.
+const ElasticIPsAttribute = "eip_allocation_ids"
+
+// WithElasticIPLookup fetches the instance's Elastic IP associations with
+// DescribeAddresses, costing one extra call per instance
+func WithElasticIPLookup() Option {
+	return func(c *Client) {
+		c.lookupElasticIPs = true
+	}
+}
+
+// NeedsElasticIPLookup reports whether any of the attributes to check,
+// including glob patterns, refers to Elastic IP associations
+func NeedsElasticIPLookup(attributes []string) bool {
+	for _, attr := range attributes {
+		root := strings.SplitN(attr, ".", 2)[0]
+		if matched, _ := path.Match(root, ElasticIPsAttribute); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// getElasticIPAllocations returns the sorted allocation IDs of the Elastic
+// IPs associated with an instance
+func (c *Client) getElasticIPAllocations(ctx context.Context, instanceID string) ([]string, error) {
+	var resp *ec2.DescribeAddressesOutput
+	err := c.retry(ctx, "DescribeAddresses", func() error {
+		var err error
+		resp, err = c.ec2Client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{
+			Filters: []types.Filter{
+				{Name: aws.String("instance-id"), Values: []string{instanceID}},
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing addresses for instance %s: %w", instanceID, err)
+	}
+
+	allocationIDs := make([]string, 0, len(resp.Addresses))
+	for _, address := range resp.Addresses {
+		if id := aws.ToString(address.AllocationId); id != "" {
+			allocationIDs = append(allocationIDs, id)
+		}
+	}
+	sort.Strings(allocationIDs)
+	return allocationIDs, nil
+}
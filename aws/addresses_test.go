@@ -0,0 +1,51 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeedsElasticIPLookup(t *testing.T) {
+	assert.True(t, NeedsElasticIPLookup([]string{"instance_type", "eip_allocation_ids"}))
+	assert.True(t, NeedsElasticIPLookup([]string{"eip_*"}))
+	assert.True(t, NeedsElasticIPLookup([]string{"*"}))
+	assert.False(t, NeedsElasticIPLookup([]string{"instance_type", "associate_public_ip_address"}))
+}
+
+func TestGetElasticIPAllocations(t *testing.T) {
+	var request string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		request = string(body)
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<DescribeAddressesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>1</requestId>
+  <addressesSet>
+    <item><publicIp>203.0.113.2</publicIp><allocationId>eipalloc-2</allocationId><instanceId>i-12345</instanceId></item>
+    <item><publicIp>203.0.113.1</publicIp><allocationId>eipalloc-1</allocationId><instanceId>i-12345</instanceId></item>
+  </addressesSet>
+</DescribeAddressesResponse>`))
+	}))
+	defer server.Close()
+
+	client := &Client{ec2Client: ec2.New(ec2.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})}
+	WithCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown)(client)
+
+	allocationIDs, err := client.getElasticIPAllocations(context.Background(), "i-12345")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"eipalloc-1", "eipalloc-2"}, allocationIDs)
+	assert.Contains(t, request, "Filter.1.Name=instance-id")
+	assert.Contains(t, request, "Filter.1.Value.1=i-12345")
+}
@@ -0,0 +1,141 @@
+package aws
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// Attribute describes an aws_instance attribute that the client maps from EC2
+type Attribute struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// SupportedAttributes lists the attributes GetEC2InstanceConfig populates,
+// named after their aws_instance counterparts in Terraform
+var SupportedAttributes = []Attribute{
+	{Name: "instance_type", Description: "EC2 instance type (e.g. t3.micro)"},
+	{Name: "ami", Description: "AMI ID the instance was launched from"},
+	{Name: "architecture", Description: "CPU architecture of the instance (e.g. x86_64, arm64); not stored by aws_instance, so compare it against a previous state or HCL"},
+	{Name: "virtualization_type", Description: "Virtualization type (hvm or paravirtual); not stored by aws_instance"},
+	{Name: "ami_name", Description: "Name of the instance's AMI (only with --resolve-ami-names)"},
+	{Name: "instance_state", Description: "Power state (running, stopped, ...); compared against --expect-state, not Terraform"},
+	{Name: "subnet_id", Description: "Subnet the instance runs in"},
+	{Name: "associate_public_ip_address", Description: "Whether an Amazon-provided public IP is associated with the primary network interface (Elastic IPs do not count)"},
+	{Name: "eip_allocation_ids", Description: "Allocation IDs of the associated Elastic IPs, compared against the aws_eip and aws_eip_association resources in state (one DescribeAddresses call per instance, only when checked)"},
+	{Name: "vpc_security_group_ids", Description: "IDs of the attached security groups"},
+	{Name: "tags", Description: "Instance tags; use tags.<key> to check a single tag"},
+	{Name: "private_ip", Description: "Primary private IPv4 address"},
+	{Name: "network_interface", Description: "Attached network interfaces (network_interface_id, device_index, delete_on_termination, subnet_id, private_ips, security_groups)"},
+	{Name: "availability_zone", Description: "Availability zone the instance runs in"},
+	{Name: "tenancy", Description: "Instance tenancy (default, dedicated or host)"},
+	{Name: "placement_group", Description: "Placement group name, if any"},
+	{Name: "host_id", Description: "Dedicated host ID, for instances with host tenancy"},
+	{Name: "host_resource_group_arn", Description: "Host resource group the instance is launched into, for instances with host tenancy"},
+	{Name: "capacity_reservation_specification", Description: "Capacity reservation targeting (capacity_reservation_preference, capacity_reservation_target with capacity_reservation_id or capacity_reservation_resource_group_arn)"},
+	{Name: "placement_partition_number", Description: "Partition number within a partition placement group"},
+	{Name: "instance_lifecycle", Description: "spot for spot instances, empty for on-demand"},
+	{Name: "instance_market_options", Description: "Spot market options (market_type, spot_options with instance_interruption_behavior, spot_instance_type, max_price, valid_until)"},
+	{Name: "monitoring", Description: "Whether detailed CloudWatch monitoring is enabled"},
+	{Name: "ebs_optimized", Description: "Whether the instance is EBS-optimized"},
+	{Name: "source_dest_check", Description: "Whether source/destination checking is enabled"},
+	{Name: "hibernation", Description: "Whether the instance is enabled for hibernation; changing it replaces the instance"},
+	{Name: "cpu_options", Description: "CPU configuration (core_count, threads_per_core, amd_sev_snp); changing it replaces the instance"},
+	{Name: "metadata_options", Description: "Instance metadata service settings (http_endpoint, http_tokens, http_put_response_hop_limit, http_protocol_ipv6, instance_metadata_tags)"},
+	{Name: "disable_api_termination", Description: "Whether termination protection is enabled"},
+	{Name: "user_data", Description: "SHA1 hash of the instance user data, as stored in Terraform state"},
+	{Name: "root_block_device", Description: "The EBS root volume (device_name, volume_id, delete_on_termination, volume_size, volume_type, encrypted, iops)"},
+	{Name: "ebs_block_device", Description: "Attached EBS volumes other than the root volume (device_name, volume_id, delete_on_termination, volume_size, volume_type, encrypted, iops)"},
+}
+
+// IsSupportedAttribute reports whether name is a supported attribute
+func IsSupportedAttribute(name string) bool {
+	for _, attr := range SupportedAttributes {
+		if attr.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// UnrecognizedAttributes returns the attributes whose top-level attribute,
+// which may be a glob, matches no supported attribute
+func UnrecognizedAttributes(attributes []string) []string {
+	names := supportedAttributeNames()
+
+	var unknown []string
+	for _, attr := range attributes {
+		attr = strings.TrimSpace(attr)
+		root, _, _ := strings.Cut(attr, ".")
+		if !slices.ContainsFunc(names, func(name string) bool {
+			matched, _ := path.Match(root, name)
+			return matched
+		}) {
+			unknown = append(unknown, attr)
+		}
+	}
+	return unknown
+}
+
+// ClosestAttribute returns the supported attribute within a few edits of
+// name, or "" if none is that close
+func ClosestAttribute(name string) string {
+	best, bestDistance := "", len(name)/3+1
+	for _, known := range supportedAttributeNames() {
+		if distance := editDistance(name, known); distance < bestDistance {
+			best, bestDistance = known, distance
+		}
+	}
+	return best
+}
+
+// supportedAttributeNames returns the names of the supported attributes
+func supportedAttributeNames() []string {
+	names := make([]string, len(SupportedAttributes))
+	for i, attr := range SupportedAttributes {
+		names[i] = attr.Name
+	}
+	return names
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// volumeAttributes are the attributes filled in from DescribeVolumes
+var volumeAttributes = []string{"root_block_device", "ebs_block_device"}
+
+// NeedsVolumeLookup reports whether any of the attributes to check, including
+// glob patterns, refers to block device or volume details
+func NeedsVolumeLookup(attributes []string) bool {
+	for _, attr := range attributes {
+		root := strings.SplitN(attr, ".", 2)[0]
+		if strings.HasPrefix(root, "volume_") {
+			return true
+		}
+		for _, volumeAttr := range volumeAttributes {
+			if matched, _ := path.Match(root, volumeAttr); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
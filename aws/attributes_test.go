@@ -0,0 +1,66 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeedsVolumeLookup(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes []string
+		expected   bool
+	}{
+		{name: "Instance attributes only", attributes: []string{"instance_type", "ami", "tags"}, expected: false},
+		{name: "EBS block devices", attributes: []string{"instance_type", "ebs_block_device"}, expected: true},
+		{name: "Nested root block device", attributes: []string{"root_block_device.0.encrypted"}, expected: true},
+		{name: "Volume attribute", attributes: []string{"volume_tags"}, expected: true},
+		{name: "Glob matching block devices", attributes: []string{"*_block_device"}, expected: true},
+		{name: "No attributes", attributes: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NeedsVolumeLookup(tt.attributes))
+		})
+	}
+}
+
+func TestUnrecognizedAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes []string
+		expected   []string
+	}{
+		{name: "Exact match", attributes: []string{"instance_type", " ami "}, expected: nil},
+		{name: "Glob", attributes: []string{"tags.*", "*_block_device", "instance_*"}, expected: nil},
+		{name: "Nested path", attributes: []string{"tags.Name", "root_block_device.0.encrypted"}, expected: nil},
+		{name: "Typo", attributes: []string{"instance_type", "instanc_type"}, expected: []string{"instanc_type"}},
+		{name: "Glob matching nothing", attributes: []string{"volume_*"}, expected: []string{"volume_*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, UnrecognizedAttributes(tt.attributes))
+		})
+	}
+}
+
+func TestClosestAttribute(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "instanc_type", expected: "instance_type"},
+		{name: "tag", expected: "tags"},
+		{name: "subnetid", expected: "subnet_id"},
+		{name: "something_else", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClosestAttribute(tt.name))
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/katungi/aws-terror/pkg/metrics"
+)
+
+// DefaultBreakerThreshold and DefaultBreakerCooldown configure the circuit
+// breaker unless WithCircuitBreaker is given
+const (
+	DefaultBreakerThreshold = 10
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// ErrThrottled is returned without calling AWS while the circuit breaker is
+// open after sustained throttling
+var ErrThrottled = errors.New("AWS throttling, backing off")
+
+// throttlingErrorCodes are the API error codes AWS uses for rate limiting
+var throttlingErrorCodes = map[string]bool{
+	"Throttling":                true,
+	"ThrottlingException":       true,
+	"ThrottledException":        true,
+	"RequestThrottled":          true,
+	"RequestThrottledException": true,
+	"RequestLimitExceeded":      true,
+	"TooManyRequestsException":  true,
+	"SlowDown":                  true,
+	"EC2ThrottledException":     true,
+}
+
+// circuitBreaker opens after threshold consecutive throttling errors across
+// all of a client's calls, and fails calls fast until cooldown has passed
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// WithCircuitBreaker sets how many consecutive throttling errors open the
+// circuit breaker and how long it stays open. A threshold of 0 disables it.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		if threshold <= 0 {
+			c.breaker = nil
+			return
+		}
+		c.breaker = newCircuitBreaker(threshold, cooldown)
+	}
+}
+
+// allow returns ErrThrottled while the breaker is open
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if remaining := b.openUntil.Sub(b.now()); remaining > 0 {
+		return fmt.Errorf("%w (retrying in %s)", ErrThrottled, remaining.Round(time.Second))
+	}
+	return nil
+}
+
+// record counts consecutive throttling errors, opening the breaker when
+// the threshold is reached. Any other outcome resets the count.
+func (b *circuitBreaker) record(err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !isThrottlingError(err) {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+		b.failures = 0
+	}
+}
+
+func isThrottlingError(err error) bool {
+	var apiErr smithy.APIError
+	return err != nil && errors.As(err, &apiErr) && throttlingErrorCodes[apiErr.ErrorCode()]
+}
+
+// retry runs operation with retry, or once with WithoutRetry, failing fast
+// while the client's circuit breaker is open. Every attempt that reaches AWS
+// is recorded as a call to api.
+func (c *Client) retry(ctx context.Context, api string, operation func() error) error {
+	attempt := func() error {
+		if err := c.breaker.allow(); err != nil {
+			return backoff.Permanent(err)
+		}
+		start := time.Now()
+		err := operation()
+		recordAPICall(api, start, err)
+		c.breaker.record(err)
+		return err
+	}
+	if c.noRetry {
+		return backoff.Retry(attempt, backoff.WithContext(&backoff.StopBackOff{}, ctx))
+	}
+	return retry(ctx, attempt)
+}
+
+// recordAPICall records a call to api that started at start and returned err
+func recordAPICall(api string, start time.Time, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	metrics.RecordAWSAPICall(api, status, time.Since(start).Seconds())
+}
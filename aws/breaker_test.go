@@ -0,0 +1,90 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/katungi/aws-terror/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker(3, 30*time.Second)
+	b.now = func() time.Time { return now }
+
+	throttled := &smithy.GenericAPIError{Code: "RequestLimitExceeded"}
+
+	b.record(throttled)
+	b.record(throttled)
+	b.record(nil) // a success resets the count
+	b.record(throttled)
+	b.record(throttled)
+	assert.NoError(t, b.allow())
+
+	b.record(throttled)
+	err := b.allow()
+	assert.ErrorIs(t, err, ErrThrottled)
+	assert.Contains(t, err.Error(), "retrying in 30s")
+
+	now = now.Add(31 * time.Second)
+	assert.NoError(t, b.allow())
+}
+
+func TestClientRetry_FailsFastWhenBreakerOpen(t *testing.T) {
+	client := &Client{}
+	WithCircuitBreaker(1, time.Minute)(client)
+
+	calls := 0
+	err := client.retry(context.Background(), "DescribeInstances", func() error {
+		calls++
+		return &smithy.GenericAPIError{Code: "Throttling"}
+	})
+
+	assert.True(t, errors.Is(err, ErrThrottled))
+	assert.Equal(t, 1, calls)
+}
+
+func TestIsThrottlingError(t *testing.T) {
+	assert.True(t, isThrottlingError(&smithy.GenericAPIError{Code: "ThrottlingException"}))
+	assert.False(t, isThrottlingError(&smithy.GenericAPIError{Code: "InvalidInstanceID.NotFound"}))
+	assert.False(t, isThrottlingError(errors.New("connection reset")))
+	assert.False(t, isThrottlingError(nil))
+}
+
+func TestClientRetry_WithoutRetry(t *testing.T) {
+	client := &Client{}
+	WithoutRetry()(client)
+
+	calls := 0
+	throttled := &smithy.GenericAPIError{Code: "Throttling"}
+	err := client.retry(context.Background(), "DescribeInstances", func() error {
+		calls++
+		return throttled
+	})
+
+	assert.Equal(t, throttled, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestClientRetry_RecordsEveryAttempt(t *testing.T) {
+	client := &Client{}
+	totalBefore, errorsBefore := metrics.AWSAPICallCounts()
+
+	calls := 0
+	err := client.retry(context.Background(), "DescribeInstances", func() error {
+		calls++
+		if calls == 1 {
+			return &smithy.GenericAPIError{Code: "Throttling"}
+		}
+		return nil
+	})
+
+	total, failed := metrics.AWSAPICallCounts()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total-totalBefore)
+	assert.Equal(t, 1, failed-errorsBefore)
+}
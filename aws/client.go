@@ -2,50 +2,174 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
-	"github.com/cenkalti/backoff/v4"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/sirupsen/logrus"
-
-	"github.com/katungi/aws-terror/pkg/metrics"
+	"golang.org/x/sync/errgroup"
 )
 
+// UncheckedAttributesKey holds the attributes, if any, that could not be
+// read from AWS and so must not be compared
+const UncheckedAttributesKey = "unchecked_attributes"
+
+// MetadataKey holds the InstanceMetadata of an instance, which describes it
+// in reports but is not compared against Terraform
+const MetadataKey = "instance_metadata"
+
+// InstanceMetadata identifies and describes an instance in reports
+type InstanceMetadata struct {
+	AccountID  string `json:"account_id,omitempty"`
+	ARN        string `json:"arn,omitempty"`
+	LaunchTime string `json:"launch_time,omitempty"`
+}
+
+// defaultVolumeConcurrency bounds the concurrent DescribeVolumes calls made
+// for a single instance
+const defaultVolumeConcurrency = 4
+
 type Client struct {
 	ec2Client *ec2.Client
+	stsClient *sts.Client
 	logger    *logrus.Logger
 	region    string
+
+	credentialPreflight bool
+	resolveAMINames     bool
+	volumeConcurrency   int
+	skipVolumeLookup    bool
+	noRetry             bool
+	lookupElasticIPs    bool
+	breaker             *circuitBreaker
+	describedAttributes []string
+	profile             string
+	configFile          string
+	credentialsFile     string
+}
+
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithCredentialPreflight verifies the credentials with sts:GetCallerIdentity
+// when the client is created, so missing or expired credentials fail fast
+// with an actionable message instead of an opaque error on the first call
+func WithCredentialPreflight() Option {
+	return func(c *Client) {
+		c.credentialPreflight = true
+	}
+}
+
+// WithoutVolumeLookup skips the DescribeVolumes calls, leaving block devices
+// with only the fields DescribeInstances returns
+func WithoutVolumeLookup() Option {
+	return func(c *Client) {
+		c.skipVolumeLookup = true
+	}
 }
 
-func NewClient(region string, logger *logrus.Logger) (*Client, error) {
+// WithProfile loads credentials and settings from a named shared config
+// profile instead of the default chain
+func WithProfile(profile string) Option {
+	return func(c *Client) {
+		c.profile = profile
+	}
+}
+
+// WithSharedConfigFile loads shared config from path instead of
+// ~/.aws/config
+func WithSharedConfigFile(path string) Option {
+	return func(c *Client) {
+		c.configFile = path
+	}
+}
+
+// WithSharedCredentialsFile loads shared credentials from path instead of
+// ~/.aws/credentials
+func WithSharedCredentialsFile(path string) Option {
+	return func(c *Client) {
+		c.credentialsFile = path
+	}
+}
+
+// WithVolumeConcurrency sets how many volumes of one instance are described
+// concurrently; 1 fetches them sequentially
+func WithVolumeConcurrency(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.volumeConcurrency = n
+		}
+	}
+}
+
+func NewClient(region string, logger *logrus.Logger, opts ...Option) (*Client, error) {
 	if logger == nil {
 		logger = logrus.New()
 		logger.SetLevel(logrus.InfoLevel)
 	}
 
-	cfg, err := loadAWSConfig(region)
+	client := &Client{
+		logger: logger,
+
+		volumeConcurrency: defaultVolumeConcurrency,
+		breaker:           newCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown),
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	cfg, err := loadAWSConfig(region, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
+	client.ec2Client = ec2.NewFromConfig(cfg)
+	client.stsClient = sts.NewFromConfig(cfg)
+	client.region = cfg.Region
 
-	return &Client{
-		ec2Client: ec2.NewFromConfig(cfg),
-		logger:    logger,
-		region:    cfg.Region,
-	}, nil
+	if client.credentialPreflight {
+		if err := client.checkCredentials(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	return client, nil
 }
 
-func loadAWSConfig(region string) (aws.Config, error) {
+func loadAWSConfig(region string, client *Client) (aws.Config, error) {
 	ctx := context.Background()
 	opts := []func(*config.LoadOptions) error{}
 	
 	if region != "" {
 		opts = append(opts, config.WithRegion(region))
 	}
+	if client.profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(client.profile))
+	}
+	if client.noRetry {
+		opts = append(opts, config.WithRetryMaxAttempts(1))
+	}
+	// The SDK skips shared files that don't exist, which would quietly fall
+	// back to other credentials
+	if client.configFile != "" {
+		if _, err := os.Stat(client.configFile); err != nil {
+			return aws.Config{}, fmt.Errorf("AWS config file: %w", err)
+		}
+		opts = append(opts, config.WithSharedConfigFiles([]string{client.configFile}))
+	}
+	if client.credentialsFile != "" {
+		if _, err := os.Stat(client.credentialsFile); err != nil {
+			return aws.Config{}, fmt.Errorf("AWS credentials file: %w", err)
+		}
+		opts = append(opts, config.WithSharedCredentialsFiles([]string{client.credentialsFile}))
+	}
 	
 	cfg, err := config.LoadDefaultConfig(ctx, opts...)
 	if err != nil {
@@ -56,13 +180,9 @@ func loadAWSConfig(region string) (aws.Config, error) {
 }
 
 func (c *Client) GetEC2InstanceConfig(ctx context.Context, instanceID string) (map[string]any, error) {
-	start := time.Now()
 	var resp *ec2.DescribeInstancesOutput
 	var err error
 
-	backoffConfig := backoff.NewExponentialBackOff()
-	backoffConfig.MaxElapsedTime = 30 * time.Second
-
 	operation := func() error {
 		resp, err = c.ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
 			InstanceIds: []string{instanceID},
@@ -70,30 +190,81 @@ func (c *Client) GetEC2InstanceConfig(ctx context.Context, instanceID string) (m
 		return err
 	}
 
-	err = backoff.Retry(operation, backoffConfig)
-
-	latency := time.Since(start).Seconds()
+	err = c.retry(ctx, "DescribeInstances", operation)
 	if err != nil {
-		metrics.RecordAWSAPICall("DescribeInstances", "error", latency)
 		return nil, fmt.Errorf("error describing instance %s: %w", instanceID, err)
 	}
-	metrics.RecordAWSAPICall("DescribeInstances", "success", latency)
 
 	if len(resp.Reservations) == 0 || len(resp.Reservations[0].Instances) == 0 {
 		return nil, fmt.Errorf("instance %s not found", instanceID)
 	}
 
 	instance := resp.Reservations[0].Instances[0]
-	return c.mapInstanceToConfig(instance)
+	warn := &warnings{}
+	config, err := c.mapInstanceToConfig(ctx, instance, warn)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerID := aws.ToString(resp.Reservations[0].OwnerId)
+	config[MetadataKey] = c.instanceMetadata(ownerID, instance)
+
+	if c.resolveAMINames && instance.ImageId != nil {
+		name, err := c.getImageName(ctx, ownerID, aws.ToString(instance.ImageId))
+		if errors.Is(err, errImageDeregistered) {
+			c.warnf(warn, "AMI %s has been deregistered, so its name can't be resolved; comparing the AMI ID", aws.ToString(instance.ImageId))
+		} else if err != nil {
+			c.warnf(warn, "Failed to resolve AMI name for %s: %v", aws.ToString(instance.ImageId), err)
+		} else {
+			config["ami_name"] = name
+		}
+	}
+
+	if c.lookupElasticIPs {
+		allocationIDs, err := c.getElasticIPAllocations(ctx, instanceID)
+		if err != nil {
+			// Without the associations every Elastic IP in state would look
+			// disassociated
+			c.warnf(warn, "Failed to fetch Elastic IPs for %s: %v", instanceID, err)
+			unchecked, _ := config[UncheckedAttributesKey].([]string)
+			config[UncheckedAttributesKey] = append(unchecked, ElasticIPsAttribute)
+		} else if len(allocationIDs) > 0 {
+			// Only set when associated, as in state
+			config[ElasticIPsAttribute] = allocationIDs
+		}
+	}
+
+	for _, name := range c.describedAttributes {
+		// Skip attributes DescribeInstances already returned
+		if _, exists := config[name]; exists {
+			continue
+		}
+		value, ok, err := c.getInstanceAttribute(ctx, instanceID, name)
+		if err != nil {
+			c.warnf(warn, "Failed to fetch %s for %s: %v", name, instanceID, err)
+		} else if ok {
+			config[name] = value
+		}
+	}
+
+	if messages := warn.list(); len(messages) > 0 {
+		config[WarningsKey] = messages
+	}
+	return config, nil
 }
 
-func (c *Client) mapInstanceToConfig(instance types.Instance) (map[string]any, error) {
+func (c *Client) mapInstanceToConfig(ctx context.Context, instance types.Instance, warn *warnings) (map[string]any, error) {
 	config := make(map[string]any)
 	
 	config["instance_type"] = string(instance.InstanceType)
 	config["ami"] = aws.ToString(instance.ImageId)
+	config["architecture"] = string(instance.Architecture)
+	config["virtualization_type"] = string(instance.VirtualizationType)
 	config["subnet_id"] = aws.ToString(instance.SubnetId)
-	config["associate_public_ip_address"] = instance.PublicIpAddress != nil
+	config["associate_public_ip_address"] = associatesPublicIP(instance)
+	if instance.State != nil {
+		config["instance_state"] = string(instance.State.Name)
+	}
 	
 	securityGroups := make([]string, 0, len(instance.SecurityGroups))
 	for _, sg := range instance.SecurityGroups {
@@ -107,36 +278,242 @@ func (c *Client) mapInstanceToConfig(instance types.Instance) (map[string]any, e
 	}
 	config["tags"] = tags
 
+	// As in Terraform, the root volume is the root_block_device and
+	// ebs_block_device holds the other volumes
 	blockDevices := make([]map[string]any, 0, len(instance.BlockDeviceMappings))
+	volumeIDs := make([]string, 0, len(instance.BlockDeviceMappings))
+	ebsDevices := make([]map[string]any, 0, len(instance.BlockDeviceMappings))
+	var rootDevices []map[string]any
 	for _, bdm := range instance.BlockDeviceMappings {
 		if bdm.Ebs != nil {
 			device := make(map[string]interface{})
 			device["device_name"] = aws.ToString(bdm.DeviceName)
 			device["volume_id"] = aws.ToString(bdm.Ebs.VolumeId)
 			device["delete_on_termination"] = aws.ToBool(bdm.Ebs.DeleteOnTermination)
-			
-			volumeInfo, err := c.getVolumeInfo(aws.ToString(bdm.Ebs.VolumeId))
-			if err != nil {
-				c.logger.Warnf("Failed to get volume information for %s: %v", aws.ToString(bdm.Ebs.VolumeId), err)
+
+			blockDevices = append(blockDevices, device)
+			volumeIDs = append(volumeIDs, aws.ToString(bdm.Ebs.VolumeId))
+			if instance.RootDeviceName != nil && aws.ToString(bdm.DeviceName) == aws.ToString(instance.RootDeviceName) {
+				rootDevices = append(rootDevices, device)
 			} else {
-				for k, v := range volumeInfo {
-					device[k] = v
-				}
+				ebsDevices = append(ebsDevices, device)
 			}
-			
-			blockDevices = append(blockDevices, device)
 		}
 	}
-	config["ebs_block_device"] = blockDevices
+
+	if !c.skipVolumeLookup {
+		denied, err := c.enrichBlockDevices(ctx, warn, blockDevices, volumeIDs)
+		if err != nil {
+			return nil, err
+		}
+		// Without volume details the block devices can't be compared, which
+		// must not look like drift
+		if denied {
+			config[UncheckedAttributesKey] = []string{"ebs_block_device", "root_block_device"}
+		}
+	}
+	config["ebs_block_device"] = ebsDevices
+	if len(rootDevices) > 0 {
+		config["root_block_device"] = rootDevices
+	}
+
+	if placement := instance.Placement; placement != nil {
+		config["availability_zone"] = aws.ToString(placement.AvailabilityZone)
+		config["tenancy"] = string(placement.Tenancy)
+		if placement.GroupName != nil && *placement.GroupName != "" {
+			config["placement_group"] = aws.ToString(placement.GroupName)
+		}
+		if placement.HostId != nil {
+			config["host_id"] = aws.ToString(placement.HostId)
+		}
+		if placement.HostResourceGroupArn != nil {
+			config["host_resource_group_arn"] = aws.ToString(placement.HostResourceGroupArn)
+		}
+		if placement.PartitionNumber != nil {
+			config["placement_partition_number"] = aws.ToInt32(placement.PartitionNumber)
+		}
+	}
+
+	config["instance_lifecycle"] = string(instance.InstanceLifecycle)
+	config["instance_market_options"] = c.mapMarketOptions(ctx, warn, instance)
+
+	config["ebs_optimized"] = aws.ToBool(instance.EbsOptimized)
+	if instance.SourceDestCheck != nil {
+		config["source_dest_check"] = aws.ToBool(instance.SourceDestCheck)
+	}
+	if instance.Monitoring != nil {
+		config["monitoring"] = instance.Monitoring.State == types.MonitoringStateEnabled
+	}
+
+	config["private_ip"] = aws.ToString(instance.PrivateIpAddress)
+	config["network_interface"] = mapNetworkInterfaces(instance.NetworkInterfaces)
+	if instance.MetadataOptions != nil {
+		config["metadata_options"] = mapMetadataOptions(instance.MetadataOptions)
+	}
+	if instance.HibernationOptions != nil {
+		config["hibernation"] = aws.ToBool(instance.HibernationOptions.Configured)
+	}
+	if instance.CpuOptions != nil {
+		config["cpu_options"] = mapCPUOptions(instance.CpuOptions)
+	}
+	if instance.CapacityReservationSpecification != nil {
+		config["capacity_reservation_specification"] = mapCapacityReservation(instance.CapacityReservationSpecification)
+	}
 	
 	return config, nil
 }
 
-func (c *Client) getVolumeInfo(volumeID string) (map[string]any, error) {
-	ctx := context.Background()
-	
-	resp, err := c.ec2Client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{
-		VolumeIds: []string{volumeID},
+// mapMetadataOptions maps instance metadata service (IMDS) settings to the
+// aws_instance metadata_options block
+func mapMetadataOptions(options *types.InstanceMetadataOptionsResponse) []map[string]any {
+	return []map[string]any{{
+		"http_endpoint":               string(options.HttpEndpoint),
+		"http_tokens":                 string(options.HttpTokens),
+		"http_put_response_hop_limit": aws.ToInt32(options.HttpPutResponseHopLimit),
+		"http_protocol_ipv6":          string(options.HttpProtocolIpv6),
+		"instance_metadata_tags":      string(options.InstanceMetadataTags),
+	}}
+}
+
+// mapCPUOptions maps the instance's CPU configuration to the aws_instance
+// cpu_options block
+func mapCPUOptions(options *types.CpuOptions) []map[string]any {
+	return []map[string]any{{
+		"core_count":       aws.ToInt32(options.CoreCount),
+		"threads_per_core": aws.ToInt32(options.ThreadsPerCore),
+		"amd_sev_snp":      string(options.AmdSevSnp),
+	}}
+}
+
+// mapCapacityReservation maps the instance's capacity reservation targeting
+// to the aws_instance capacity_reservation_specification block. As in state,
+// capacity_reservation_target is empty unless a reservation is targeted.
+func mapCapacityReservation(spec *types.CapacityReservationSpecificationResponse) []map[string]any {
+	target := []map[string]any{}
+	if t := spec.CapacityReservationTarget; t != nil {
+		target = append(target, map[string]any{
+			"capacity_reservation_id":                 aws.ToString(t.CapacityReservationId),
+			"capacity_reservation_resource_group_arn": aws.ToString(t.CapacityReservationResourceGroupArn),
+		})
+	}
+	return []map[string]any{{
+		"capacity_reservation_preference": string(spec.CapacityReservationPreference),
+		"capacity_reservation_target":     target,
+	}}
+}
+
+// instanceMetadata describes an instance owned by the account ownerID
+func (c *Client) instanceMetadata(ownerID string, instance types.Instance) InstanceMetadata {
+	var metadata InstanceMetadata
+	if ownerID != "" {
+		metadata.AccountID = ownerID
+		metadata.ARN = instanceARN(c.region, ownerID, aws.ToString(instance.InstanceId))
+	}
+	if instance.LaunchTime != nil {
+		metadata.LaunchTime = instance.LaunchTime.UTC().Format(time.RFC3339)
+	}
+	return metadata
+}
+
+// instanceARN builds the ARN of an EC2 instance, using the partition of the
+// region
+func instanceARN(region, accountID, instanceID string) string {
+	partition := "aws"
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		partition = "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		partition = "aws-us-gov"
+	}
+	return fmt.Sprintf("arn:%s:ec2:%s:%s:instance/%s", partition, region, accountID, instanceID)
+}
+
+// enrichBlockDevices adds DescribeVolumes details to each device
+// concurrently; each goroutine writes only its own map. It reports whether
+// any lookup was denied for lack of permissions.
+func (c *Client) enrichBlockDevices(ctx context.Context, warn *warnings, blockDevices []map[string]any, volumeIDs []string) (bool, error) {
+	var denied atomic.Bool
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(c.volumeConcurrency)
+	for i, device := range blockDevices {
+		volumeID := volumeIDs[i]
+		g.Go(func() error {
+			volumeInfo, err := c.getVolumeInfo(gctx, volumeID)
+			if isPermissionError(err) {
+				denied.Store(true)
+				c.warnf(warn, "Not permitted to describe volume %s; block devices will be unchecked: %v", volumeID, err)
+				return nil
+			}
+			if err != nil {
+				c.warnf(warn, "Failed to get volume information for %s: %v", volumeID, err)
+				return nil
+			}
+			for k, v := range volumeInfo {
+				device[k] = v
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	return denied.Load(), ctx.Err()
+}
+
+// mapNetworkInterfaces maps attached ENIs to the fields of Terraform's
+// network_interface blocks, plus the subnet, private IPs and security groups
+// of each interface
+func mapNetworkInterfaces(interfaces []types.InstanceNetworkInterface) []map[string]any {
+	result := make([]map[string]any, 0, len(interfaces))
+	for _, ni := range interfaces {
+		eni := make(map[string]any)
+		eni["network_interface_id"] = aws.ToString(ni.NetworkInterfaceId)
+		eni["subnet_id"] = aws.ToString(ni.SubnetId)
+		if ni.Attachment != nil {
+			eni["device_index"] = aws.ToInt32(ni.Attachment.DeviceIndex)
+			eni["delete_on_termination"] = aws.ToBool(ni.Attachment.DeleteOnTermination)
+		}
+
+		privateIPs := make([]string, 0, len(ni.PrivateIpAddresses))
+		for _, ip := range ni.PrivateIpAddresses {
+			privateIPs = append(privateIPs, aws.ToString(ip.PrivateIpAddress))
+		}
+		eni["private_ips"] = privateIPs
+
+		securityGroups := make([]string, 0, len(ni.Groups))
+		for _, sg := range ni.Groups {
+			securityGroups = append(securityGroups, aws.ToString(sg.GroupId))
+		}
+		eni["security_groups"] = securityGroups
+
+		result = append(result, eni)
+	}
+	return result
+}
+
+// associatesPublicIP approximates Terraform's associate_public_ip_address,
+// which records whether a public IP was requested at launch. It is derived
+// from the primary network interface: an Amazon-owned public IP association
+// means one was auto-assigned, while an Elastic IP (owned by the account)
+// does not count. Instances without network interface data fall back to
+// whether a public IP is currently attached.
+func associatesPublicIP(instance types.Instance) bool {
+	for _, ni := range instance.NetworkInterfaces {
+		if ni.Attachment == nil || aws.ToInt32(ni.Attachment.DeviceIndex) != 0 {
+			continue
+		}
+		return ni.Association != nil && aws.ToString(ni.Association.IpOwnerId) == "amazon"
+	}
+
+	return instance.PublicIpAddress != nil
+}
+
+func (c *Client) getVolumeInfo(ctx context.Context, volumeID string) (map[string]any, error) {
+	var resp *ec2.DescribeVolumesOutput
+	err := c.retry(ctx, "DescribeVolumes", func() error {
+		var err error
+		resp, err = c.ec2Client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{
+			VolumeIds: []string{volumeID},
+		})
+		return err
 	})
 	
 	if err != nil {
@@ -150,12 +527,12 @@ func (c *Client) getVolumeInfo(volumeID string) (map[string]any, error) {
 	volume := resp.Volumes[0]
 	volumeInfo := make(map[string]any)
 	
-	volumeInfo["volume_size"] = volume.Size
+	volumeInfo["volume_size"] = aws.ToInt32(volume.Size)
 	volumeInfo["volume_type"] = string(volume.VolumeType)
-	volumeInfo["encrypted"] = volume.Encrypted
+	volumeInfo["encrypted"] = aws.ToBool(volume.Encrypted)
 	
 	if volume.Iops != nil {
-		volumeInfo["iops"] = volume.Iops
+		volumeInfo["iops"] = aws.ToInt32(volume.Iops)
 	}
 	
 	return volumeInfo, nil
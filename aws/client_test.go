@@ -0,0 +1,388 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssociatesPublicIP(t *testing.T) {
+	primaryWith := func(association *types.InstanceNetworkInterfaceAssociation) []types.InstanceNetworkInterface {
+		return []types.InstanceNetworkInterface{
+			{
+				Attachment:  &types.InstanceNetworkInterfaceAttachment{DeviceIndex: aws.Int32(0)},
+				Association: association,
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		instance types.Instance
+		expected bool
+	}{
+		{
+			name: "Auto-assigned public IP",
+			instance: types.Instance{
+				PublicIpAddress:   aws.String("54.0.0.1"),
+				NetworkInterfaces: primaryWith(&types.InstanceNetworkInterfaceAssociation{IpOwnerId: aws.String("amazon")}),
+			},
+			expected: true,
+		},
+		{
+			name: "Elastic IP is not an auto-assigned address",
+			instance: types.Instance{
+				PublicIpAddress:   aws.String("54.0.0.1"),
+				NetworkInterfaces: primaryWith(&types.InstanceNetworkInterfaceAssociation{IpOwnerId: aws.String("123456789012")}),
+			},
+			expected: false,
+		},
+		{
+			name:     "No public IP",
+			instance: types.Instance{NetworkInterfaces: primaryWith(nil)},
+			expected: false,
+		},
+		{
+			name:     "No network interface data",
+			instance: types.Instance{PublicIpAddress: aws.String("54.0.0.1")},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, associatesPublicIP(tt.instance))
+		})
+	}
+}
+
+func TestMapNetworkInterfaces(t *testing.T) {
+	interfaces := []types.InstanceNetworkInterface{
+		{
+			NetworkInterfaceId: aws.String("eni-1"),
+			SubnetId:           aws.String("subnet-1"),
+			Attachment: &types.InstanceNetworkInterfaceAttachment{
+				DeviceIndex:         aws.Int32(1),
+				DeleteOnTermination: aws.Bool(false),
+			},
+			PrivateIpAddresses: []types.InstancePrivateIpAddress{
+				{PrivateIpAddress: aws.String("10.0.0.10")},
+				{PrivateIpAddress: aws.String("10.0.0.11")},
+			},
+			Groups: []types.GroupIdentifier{{GroupId: aws.String("sg-1")}},
+		},
+	}
+
+	result := mapNetworkInterfaces(interfaces)
+
+	assert.Equal(t, []map[string]any{
+		{
+			"network_interface_id":  "eni-1",
+			"subnet_id":             "subnet-1",
+			"device_index":          int32(1),
+			"delete_on_termination": false,
+			"private_ips":           []string{"10.0.0.10", "10.0.0.11"},
+			"security_groups":       []string{"sg-1"},
+		},
+	}, result)
+}
+
+func TestMapInstanceToConfig_Placement(t *testing.T) {
+	client := &Client{logger: logrus.New(), volumeConcurrency: 1}
+	instance := types.Instance{
+		InstanceType: types.InstanceTypeT3Micro,
+		Placement: &types.Placement{
+			AvailabilityZone: aws.String("us-east-1a"),
+			Tenancy:          types.TenancyDedicated,
+			GroupName:        aws.String("web-spread"),
+		},
+	}
+
+	config, err := client.mapInstanceToConfig(context.Background(), instance, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "us-east-1a", config["availability_zone"])
+	assert.Equal(t, "dedicated", config["tenancy"])
+	assert.Equal(t, "web-spread", config["placement_group"])
+	assert.NotContains(t, config, "host_id")
+}
+
+func TestInstanceMetadata_LaunchTime(t *testing.T) {
+	client := &Client{logger: logrus.New(), volumeConcurrency: 1}
+	launched := time.Date(2025, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	instance := types.Instance{LaunchTime: &launched}
+
+	config, err := client.mapInstanceToConfig(context.Background(), instance, nil)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, config, "launch_time")
+	assert.Equal(t, "2025-03-01T08:30:00Z", client.instanceMetadata("", instance).LaunchTime)
+}
+
+func TestMapInstanceToConfig_WithoutVolumeLookup(t *testing.T) {
+	// No EC2 client: any DescribeVolumes call would panic
+	client := &Client{logger: logrus.New(), volumeConcurrency: 1}
+	WithoutVolumeLookup()(client)
+
+	instance := types.Instance{
+		RootDeviceName: aws.String("/dev/xvda"),
+		BlockDeviceMappings: []types.InstanceBlockDeviceMapping{
+			{
+				DeviceName: aws.String("/dev/xvda"),
+				Ebs:        &types.EbsInstanceBlockDevice{VolumeId: aws.String("vol-root"), DeleteOnTermination: aws.Bool(true)},
+			},
+			{
+				DeviceName: aws.String("/dev/sdf"),
+				Ebs:        &types.EbsInstanceBlockDevice{VolumeId: aws.String("vol-0123"), DeleteOnTermination: aws.Bool(true)},
+			},
+		},
+	}
+
+	config, err := client.mapInstanceToConfig(context.Background(), instance, nil)
+
+	assert.NoError(t, err)
+	devices := config["ebs_block_device"].([]map[string]any)
+	assert.Len(t, devices, 1)
+	assert.Equal(t, "vol-0123", devices[0]["volume_id"])
+	assert.NotContains(t, devices[0], "volume_size")
+	// The root volume is reported as the root_block_device, as in state
+	root := config["root_block_device"].([]map[string]any)
+	assert.Len(t, root, 1)
+	assert.Equal(t, "vol-root", root[0]["volume_id"])
+}
+
+func TestInstanceMetadata(t *testing.T) {
+	client := &Client{region: "us-east-1"}
+	instance := types.Instance{InstanceId: aws.String("i-0123")}
+
+	metadata := client.instanceMetadata("123456789012", instance)
+
+	assert.Equal(t, "123456789012", metadata.AccountID)
+	assert.Equal(t, "arn:aws:ec2:us-east-1:123456789012:instance/i-0123", metadata.ARN)
+	assert.Equal(t, InstanceMetadata{}, client.instanceMetadata("", instance))
+}
+
+func TestInstanceARN(t *testing.T) {
+	assert.Equal(t, "arn:aws:ec2:us-east-1:123456789012:instance/i-0123", instanceARN("us-east-1", "123456789012", "i-0123"))
+	assert.Equal(t, "arn:aws-cn:ec2:cn-north-1:123456789012:instance/i-0123", instanceARN("cn-north-1", "123456789012", "i-0123"))
+	assert.Equal(t, "arn:aws-us-gov:ec2:us-gov-west-1:123456789012:instance/i-0123", instanceARN("us-gov-west-1", "123456789012", "i-0123"))
+}
+
+func TestMapInstanceToConfig_MarketOptions(t *testing.T) {
+	client := &Client{logger: logrus.New(), volumeConcurrency: 1}
+
+	config, err := client.mapInstanceToConfig(context.Background(), types.Instance{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", config["instance_lifecycle"])
+	assert.Empty(t, config["instance_market_options"])
+
+	// Without a spot request ID no lookup is made
+	config, err = client.mapInstanceToConfig(context.Background(), types.Instance{InstanceLifecycle: types.InstanceLifecycleTypeSpot}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "spot", config["instance_lifecycle"])
+	assert.Equal(t, []map[string]any{{"market_type": "spot"}}, config["instance_market_options"])
+}
+
+func TestMapInstanceToConfig_Architecture(t *testing.T) {
+	client := &Client{logger: logrus.New(), volumeConcurrency: 1}
+
+	config, err := client.mapInstanceToConfig(context.Background(), types.Instance{
+		Architecture:       types.ArchitectureValuesArm64,
+		VirtualizationType: types.VirtualizationTypeHvm,
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "arm64", config["architecture"])
+	assert.Equal(t, "hvm", config["virtualization_type"])
+}
+
+func TestMapInstanceToConfig_SourceDestCheck(t *testing.T) {
+	client := &Client{logger: logrus.New(), volumeConcurrency: 1}
+
+	config, err := client.mapInstanceToConfig(context.Background(), types.Instance{SourceDestCheck: aws.Bool(false)}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, false, config["source_dest_check"])
+
+	// Left unset so it is fetched with DescribeInstanceAttribute when checked
+	config, err = client.mapInstanceToConfig(context.Background(), types.Instance{}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, config, "source_dest_check")
+}
+
+func TestMapInstanceToConfig_MetadataOptions(t *testing.T) {
+	client := &Client{logger: logrus.New(), volumeConcurrency: 1}
+
+	config, err := client.mapInstanceToConfig(context.Background(), types.Instance{
+		MetadataOptions: &types.InstanceMetadataOptionsResponse{
+			HttpEndpoint:            types.InstanceMetadataEndpointStateEnabled,
+			HttpTokens:              types.HttpTokensStateRequired,
+			HttpPutResponseHopLimit: aws.Int32(2),
+		},
+	}, nil)
+	assert.NoError(t, err)
+
+	options := config["metadata_options"].([]map[string]any)
+	assert.Equal(t, "required", options[0]["http_tokens"])
+	assert.Equal(t, "enabled", options[0]["http_endpoint"])
+	assert.Equal(t, int32(2), options[0]["http_put_response_hop_limit"])
+}
+
+func TestMapInstanceToConfig_CPUAndHibernation(t *testing.T) {
+	client := &Client{logger: logrus.New(), volumeConcurrency: 1}
+
+	config, err := client.mapInstanceToConfig(context.Background(), types.Instance{
+		HibernationOptions: &types.HibernationOptions{Configured: aws.Bool(true)},
+		CpuOptions:         &types.CpuOptions{CoreCount: aws.Int32(2), ThreadsPerCore: aws.Int32(1)},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, true, config["hibernation"])
+
+	options := config["cpu_options"].([]map[string]any)
+	assert.Equal(t, int32(2), options[0]["core_count"])
+	assert.Equal(t, int32(1), options[0]["threads_per_core"])
+	assert.Equal(t, "", options[0]["amd_sev_snp"])
+}
+
+func TestSpotOptions(t *testing.T) {
+	request := types.SpotInstanceRequest{
+		InstanceInterruptionBehavior: types.InstanceInterruptionBehaviorStop,
+		Type:                         types.SpotInstanceTypePersistent,
+		SpotPrice:                    aws.String("0.0500"),
+	}
+
+	assert.Equal(t, map[string]any{
+		"instance_interruption_behavior": "stop",
+		"spot_instance_type":             "persistent",
+		"max_price":                      "0.0500",
+	}, spotOptions(request))
+}
+
+func TestMapMarketOptions_DescribesSpotRequestOnlyWhenSet(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<DescribeSpotInstanceRequestsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>1</requestId>
+  <spotInstanceRequestSet>
+    <item><spotInstanceRequestId>sir-1</spotInstanceRequestId><spotPrice>0.0500</spotPrice><type>one-time</type><instanceInterruptionBehavior>terminate</instanceInterruptionBehavior></item>
+  </spotInstanceRequestSet>
+</DescribeSpotInstanceRequestsResponse>`))
+	}))
+	defer server.Close()
+
+	client := &Client{logger: logrus.New(), ec2Client: ec2.New(ec2.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})}
+
+	tests := []struct {
+		name        string
+		instance    types.Instance
+		requests    int
+		spotOptions bool
+	}{
+		{name: "On-demand", instance: types.Instance{}, requests: 0},
+		{name: "Spot without a request", instance: types.Instance{InstanceLifecycle: types.InstanceLifecycleTypeSpot}, requests: 0},
+		{
+			name: "Spot request",
+			instance: types.Instance{
+				InstanceLifecycle:     types.InstanceLifecycleTypeSpot,
+				SpotInstanceRequestId: aws.String("sir-1"),
+			},
+			requests:    1,
+			spotOptions: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			options := client.mapMarketOptions(context.Background(), nil, tt.instance)
+
+			assert.Equal(t, tt.requests, requests)
+			if tt.instance.InstanceLifecycle != types.InstanceLifecycleTypeSpot {
+				assert.Empty(t, options)
+				return
+			}
+			assert.Equal(t, "spot", options[0]["market_type"])
+			_, ok := options[0]["spot_options"]
+			assert.Equal(t, tt.spotOptions, ok)
+		})
+	}
+}
+
+func TestMapInstanceToConfig_HostAndCapacityReservation(t *testing.T) {
+	client := &Client{logger: logrus.New(), volumeConcurrency: 1}
+	instance := types.Instance{
+		Placement: &types.Placement{
+			Tenancy:              types.TenancyHost,
+			HostId:               aws.String("h-0123"),
+			Affinity:             aws.String("host"),
+			HostResourceGroupArn: aws.String("arn:aws:resource-groups:us-east-1:123456789012:group/hosts"),
+		},
+		CapacityReservationSpecification: &types.CapacityReservationSpecificationResponse{
+			CapacityReservationPreference: types.CapacityReservationPreferenceOpen,
+			CapacityReservationTarget: &types.CapacityReservationTargetResponse{
+				CapacityReservationId: aws.String("cr-0123"),
+			},
+		},
+	}
+
+	config, err := client.mapInstanceToConfig(context.Background(), instance, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "h-0123", config["host_id"])
+	// aws_instance has no affinity argument, so it is never compared
+	assert.NotContains(t, config, "affinity")
+	assert.Equal(t, "arn:aws:resource-groups:us-east-1:123456789012:group/hosts", config["host_resource_group_arn"])
+	assert.Equal(t, []map[string]any{{
+		"capacity_reservation_preference": "open",
+		"capacity_reservation_target": []map[string]any{{
+			"capacity_reservation_id":                 "cr-0123",
+			"capacity_reservation_resource_group_arn": "",
+		}},
+	}}, config["capacity_reservation_specification"])
+}
+
+func TestGetVolumeInfo_RetriesThrottling(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "text/xml")
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`<Response><Errors><Error><Code>RequestLimitExceeded</Code><Message>Request limit exceeded.</Message></Error></Errors><RequestID>1</RequestID></Response>`))
+			return
+		}
+		w.Write([]byte(`<DescribeVolumesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>2</requestId>
+  <volumeSet>
+    <item><volumeId>vol-1</volumeId><size>8</size><volumeType>gp3</volumeType><encrypted>true</encrypted></item>
+  </volumeSet>
+</DescribeVolumesResponse>`))
+	}))
+	defer server.Close()
+
+	client := &Client{ec2Client: ec2.New(ec2.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+		Retryer:      aws.NopRetryer{},
+	})}
+
+	volumeInfo, err := client.getVolumeInfo(context.Background(), "vol-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, int32(8), volumeInfo["volume_size"])
+	assert.Equal(t, "gp3", volumeInfo["volume_type"])
+}
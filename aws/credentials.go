@@ -0,0 +1,78 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+
+	"github.com/katungi/aws-terror/pkg/metrics"
+)
+
+// credentialErrorCodes are API error codes returned for missing, invalid or
+// expired credentials
+var credentialErrorCodes = map[string]bool{
+	"ExpiredToken":                true,
+	"ExpiredTokenException":       true,
+	"InvalidClientTokenId":        true,
+	"UnrecognizedClientException": true,
+	"SignatureDoesNotMatch":       true,
+	"AuthFailure":                 true,
+	"NoCredentialProviders":       true,
+}
+
+// permissionErrorCodes are API error codes returned when the credentials are
+// valid but not allowed to perform the call
+var permissionErrorCodes = map[string]bool{
+	"UnauthorizedOperation": true,
+	"AccessDenied":          true,
+	"AccessDeniedException": true,
+}
+
+// ErrInvalidCredentials is returned when the preflight check finds that no
+// usable AWS credentials are configured
+var ErrInvalidCredentials = errors.New("AWS credentials are missing, invalid or expired")
+
+func (c *Client) checkCredentials(ctx context.Context) error {
+	start := time.Now()
+	_, err := c.stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	latency := time.Since(start).Seconds()
+
+	if err != nil {
+		metrics.RecordAWSAPICall("GetCallerIdentity", "error", latency)
+		if isCredentialError(err) {
+			return fmt.Errorf("%w: %v\n"+
+				"Configure credentials via AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY, "+
+				"~/.aws/credentials, or AWS_PROFILE (run `aws sso login` for SSO profiles)",
+				ErrInvalidCredentials, err)
+		}
+		return fmt.Errorf("failed to verify AWS credentials: %w", err)
+	}
+	metrics.RecordAWSAPICall("GetCallerIdentity", "success", latency)
+
+	return nil
+}
+
+func isCredentialError(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && credentialErrorCodes[apiErr.ErrorCode()] {
+		return true
+	}
+
+	// Credential provider failures happen before a request is sent and
+	// carry no API error code
+	msg := err.Error()
+	return strings.Contains(msg, "failed to retrieve credentials") ||
+		strings.Contains(msg, "failed to refresh cached credentials") ||
+		strings.Contains(msg, "no valid credential sources")
+}
+
+// isPermissionError reports whether err is an authorization failure
+func isPermissionError(err error) bool {
+	var apiErr smithy.APIError
+	return err != nil && errors.As(err, &apiErr) && permissionErrorCodes[apiErr.ErrorCode()]
+}
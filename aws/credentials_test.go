@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsCredentialError(t *testing.T) {
+	expired := &smithy.GenericAPIError{Code: "ExpiredToken", Message: "The security token included in the request is expired"}
+	throttled := &smithy.GenericAPIError{Code: "Throttling", Message: "Rate exceeded"}
+
+	assert.True(t, isCredentialError(expired))
+	assert.True(t, isCredentialError(fmt.Errorf("operation error: %w", expired)))
+	assert.True(t, isCredentialError(errors.New("failed to refresh cached credentials, no EC2 IMDS role found")))
+	assert.False(t, isCredentialError(throttled))
+	assert.False(t, isCredentialError(errors.New("connection reset by peer")))
+}
+
+func TestIsPermissionError(t *testing.T) {
+	assert.True(t, isPermissionError(&smithy.GenericAPIError{Code: "UnauthorizedOperation"}))
+	assert.False(t, isPermissionError(&smithy.GenericAPIError{Code: "InvalidVolume.NotFound"}))
+	assert.False(t, isPermissionError(errors.New("connection reset")))
+	assert.False(t, isPermissionError(nil))
+}
+
+func TestLoadAWSConfig_SharedFiles(t *testing.T) {
+	// Keep credentials from the environment out of the chain
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_REGION", "")
+
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	credentialsFile := filepath.Join(dir, "credentials")
+	assert.NoError(t, os.WriteFile(configFile, []byte("[profile ci]\nregion = eu-west-2\n"), 0600))
+	assert.NoError(t, os.WriteFile(credentialsFile, []byte("[ci]\naws_access_key_id = AKIDCI\naws_secret_access_key = secret\n"), 0600))
+
+	client := &Client{}
+	WithProfile("ci")(client)
+	WithSharedConfigFile(configFile)(client)
+	WithSharedCredentialsFile(credentialsFile)(client)
+	cfg, err := loadAWSConfig("", client)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-west-2", cfg.Region)
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "AKIDCI", creds.AccessKeyID)
+
+	// A missing file is an error rather than a silent fallback
+	WithSharedCredentialsFile(filepath.Join(dir, "missing"))(client)
+	_, err = loadAWSConfig("", client)
+	assert.ErrorContains(t, err, "AWS credentials file")
+}
@@ -0,0 +1,268 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"gopkg.in/yaml.v3"
+)
+
+// Describer maps a Terraform resource type without built-in support to the
+// EC2 API call that describes it, so it can be compared without code
+// changes. The README has an example for aws_eip.
+//
+// Paths select fields of the response as the SDK names them: Name selects a
+// field, [N] a list element (negative from the end) and [] applies the rest
+// of the path to every element. Lists of Key/Value pairs, such as Tags,
+// become maps, as Terraform stores them.
+type Describer struct {
+	// Call is the EC2 Describe operation, e.g. DescribeAddresses
+	Call string `yaml:"call"`
+	// IDField is the input field that takes the resource ID in a list,
+	// e.g. AllocationIds
+	IDField string `yaml:"id_field"`
+	// Result is the path to the resource in the response
+	Result string `yaml:"result"`
+	// Attributes maps Terraform attribute names to paths in the result
+	Attributes map[string]string `yaml:"attributes"`
+}
+
+// ParseDescribers reads describers keyed by resource type from YAML and
+// checks that their calls, ID fields and paths exist
+func ParseDescribers(r io.Reader) (map[string]Describer, error) {
+	var describers map[string]Describer
+	if err := yaml.NewDecoder(r).Decode(&describers); err != nil {
+		return nil, fmt.Errorf("failed to parse describers: %w", err)
+	}
+
+	for resourceType, describer := range describers {
+		if err := describer.validate(); err != nil {
+			return nil, fmt.Errorf("describer for %s: %w", resourceType, err)
+		}
+	}
+	return describers, nil
+}
+
+// AttributeNames returns the Terraform attributes a describer maps, sorted
+func (d Describer) AttributeNames() []string {
+	names := make([]string, 0, len(d.Attributes))
+	for name := range d.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (d Describer) validate() error {
+	if _, err := d.input(""); err != nil {
+		return err
+	}
+	if _, err := parsePath(d.Result); err != nil {
+		return fmt.Errorf("result: %w", err)
+	}
+	if len(d.Attributes) == 0 {
+		return fmt.Errorf("no attributes to compare")
+	}
+	for name, path := range d.Attributes {
+		if _, err := parsePath(path); err != nil {
+			return fmt.Errorf("attribute %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// input returns the input of the call, asking for the resource with ID id.
+// Only Describe calls are allowed, so a describers file can't change
+// anything.
+func (d Describer) input(id string) (reflect.Value, error) {
+	if !strings.HasPrefix(d.Call, "Describe") {
+		return reflect.Value{}, fmt.Errorf("call %q is not a Describe call", d.Call)
+	}
+	method, ok := reflect.TypeOf(&ec2.Client{}).MethodByName(d.Call)
+	if !ok || method.Type.NumIn() < 3 || method.Type.In(2).Kind() != reflect.Pointer {
+		return reflect.Value{}, fmt.Errorf("unknown EC2 call %q", d.Call)
+	}
+
+	input := reflect.New(method.Type.In(2).Elem())
+	field := input.Elem().FieldByName(d.IDField)
+	if !field.IsValid() || field.Type() != reflect.TypeOf([]string{}) {
+		return reflect.Value{}, fmt.Errorf("%s has no list of IDs named %q", d.Call, d.IDField)
+	}
+	field.Set(reflect.ValueOf([]string{id}))
+	return input, nil
+}
+
+// DescribeResource makes a describer's call for the resource with ID id and
+// returns the attributes it maps. Attributes whose path finds nothing are
+// left out, like unset attributes of an instance.
+func (c *Client) DescribeResource(ctx context.Context, d Describer, id string) (map[string]any, error) {
+	input, err := d.input(id)
+	if err != nil {
+		return nil, err
+	}
+	method := reflect.ValueOf(c.ec2Client).MethodByName(d.Call)
+
+	var output any
+	err = c.retry(ctx, d.Call, func() error {
+		results := method.Call([]reflect.Value{reflect.ValueOf(ctx), input})
+		if err, _ := results[1].Interface().(error); err != nil {
+			return err
+		}
+		output = results[0].Interface()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error calling %s for %s: %w", d.Call, id, err)
+	}
+
+	// Paths are evaluated over the response as JSON, which names fields as
+	// the SDK does
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+	var response any
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	resultPath, _ := parsePath(d.Result)
+	result := evalPath(response, resultPath)
+	if result == nil {
+		return nil, fmt.Errorf("%s found no %s", d.Call, id)
+	}
+
+	config := make(map[string]any, len(d.Attributes))
+	for name, path := range d.Attributes {
+		steps, _ := parsePath(path)
+		if value := evalPath(result, steps); value != nil {
+			config[name] = keyValueMap(value)
+		}
+	}
+	return config, nil
+}
+
+// pathStep is one step of a path: a field, a list index or a projection
+type pathStep struct {
+	field   string
+	index   int
+	indexed bool
+	project bool
+}
+
+// parsePath splits a path such as Reservations[].Instances[0].InstanceId
+// into steps
+func parsePath(path string) ([]pathStep, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	var steps []pathStep
+	for i := 0; i < len(path); {
+		switch {
+		case path[i] == '.' && i > 0 && i < len(path)-1 && path[i+1] != '.' && path[i+1] != '[':
+			i++
+		case path[i] == '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %q", path)
+			}
+			inner := path[i+1 : i+end]
+			if inner == "" {
+				steps = append(steps, pathStep{project: true})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index [%s] in %q", inner, path)
+				}
+				steps = append(steps, pathStep{index: index, indexed: true})
+			}
+			i += end + 1
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+			steps = append(steps, pathStep{field: path[i : i+end]})
+			i += end
+		}
+	}
+	return steps, nil
+}
+
+// evalPath follows steps through a decoded JSON value, returning nil when
+// they lead nowhere
+func evalPath(value any, steps []pathStep) any {
+	for i, step := range steps {
+		switch {
+		case step.project:
+			list, ok := value.([]any)
+			if !ok {
+				return nil
+			}
+			projected := []any{}
+			for _, element := range list {
+				if v := evalPath(element, steps[i+1:]); v != nil {
+					projected = append(projected, v)
+				}
+			}
+			return projected
+		case step.indexed:
+			list, ok := value.([]any)
+			if !ok {
+				return nil
+			}
+			index := step.index
+			if index < 0 {
+				index += len(list)
+			}
+			if index < 0 || index >= len(list) {
+				return nil
+			}
+			value = list[index]
+		default:
+			object, ok := value.(map[string]any)
+			if !ok {
+				return nil
+			}
+			value = object[step.field]
+		}
+	}
+	return value
+}
+
+// keyValueMap turns a list of Key/Value pairs into a map, leaving other
+// values unchanged
+func keyValueMap(value any) any {
+	list, ok := value.([]any)
+	if !ok || len(list) == 0 {
+		return value
+	}
+
+	pairs := make(map[string]any, len(list))
+	for _, element := range list {
+		pair, ok := element.(map[string]any)
+		if !ok || len(pair) != 2 {
+			return value
+		}
+		key, ok := pair["Key"].(string)
+		if !ok {
+			return value
+		}
+		if _, ok := pair["Value"]; !ok {
+			return value
+		}
+		pairs[key] = pair["Value"]
+	}
+	return pairs
+}
@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+const eipDescribers = `
+aws_eip:
+  call: DescribeAddresses
+  id_field: AllocationIds
+  result: Addresses[0]
+  attributes:
+    public_ip: PublicIp
+    domain: Domain
+    instance: InstanceId
+    tags: Tags
+`
+
+func TestParseDescribers(t *testing.T) {
+	describers, err := ParseDescribers(strings.NewReader(eipDescribers))
+	assert.NoError(t, err)
+	assert.Equal(t, "DescribeAddresses", describers["aws_eip"].Call)
+	assert.Equal(t, []string{"domain", "instance", "public_ip", "tags"}, describers["aws_eip"].AttributeNames())
+
+	tests := []struct {
+		name      string
+		describer string
+		wantErr   string
+	}{
+		{"unknown call", "call: DescribeNothing\nid_field: Ids\nattributes: {a: A}", "unknown EC2 call"},
+		{"not a describe call", "call: TerminateInstances\nid_field: InstanceIds\nattributes: {a: A}", "not a Describe call"},
+		{"unknown id field", "call: DescribeAddresses\nid_field: InstanceIds\nattributes: {a: A}", "no list of IDs"},
+		{"bad path", "call: DescribeAddresses\nid_field: AllocationIds\nattributes: {a: 'Addresses[x]'}", "invalid index"},
+		{"no attributes", "call: DescribeAddresses\nid_field: AllocationIds", "no attributes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := "aws_thing:\n  " + strings.ReplaceAll(tt.describer, "\n", "\n  ")
+			_, err := ParseDescribers(strings.NewReader(yaml))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestEvalPath(t *testing.T) {
+	response := map[string]any{
+		"Reservations": []any{
+			map[string]any{"Instances": []any{map[string]any{"InstanceId": "i-1"}, map[string]any{"InstanceId": "i-2"}}},
+			map[string]any{"Instances": []any{map[string]any{"InstanceId": "i-3"}}},
+		},
+	}
+
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"Reservations[0].Instances[1].InstanceId", "i-2"},
+		{"Reservations[-1].Instances[0].InstanceId", "i-3"},
+		{"Reservations[].Instances[0].InstanceId", []any{"i-1", "i-3"}},
+		{"Reservations[5].Instances", nil},
+		{"Missing.Field", nil},
+	}
+	for _, tt := range tests {
+		steps, err := parsePath(tt.path)
+		assert.NoError(t, err, tt.path)
+		assert.Equal(t, tt.want, evalPath(response, steps), tt.path)
+	}
+
+	for _, path := range []string{".Reservations", "Reservations.", "Reservations..Instances", "Reservations[0"} {
+		_, err := parsePath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestDescribeResource(t *testing.T) {
+	var action, allocationID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		action, allocationID = r.Form.Get("Action"), r.Form.Get("AllocationId.1")
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<DescribeAddressesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>1</requestId>
+  <addressesSet>
+    <item>
+      <allocationId>eipalloc-123</allocationId>
+      <publicIp>203.0.113.10</publicIp>
+      <domain>vpc</domain>
+      <tagSet><item><key>Name</key><value>web</value></item></tagSet>
+    </item>
+  </addressesSet>
+</DescribeAddressesResponse>`))
+	}))
+	defer server.Close()
+
+	client := &Client{ec2Client: ec2.New(ec2.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})}
+	WithCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown)(client)
+
+	describers, err := ParseDescribers(strings.NewReader(eipDescribers))
+	assert.NoError(t, err)
+	config, err := client.DescribeResource(context.Background(), describers["aws_eip"], "eipalloc-123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "DescribeAddresses", action)
+	assert.Equal(t, "eipalloc-123", allocationID)
+	assert.Equal(t, map[string]any{
+		"public_ip": "203.0.113.10",
+		"domain":    "vpc",
+		"tags":      map[string]any{"Name": "web"},
+	}, config)
+}
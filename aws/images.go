@@ -0,0 +1,145 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/katungi/aws-terror/pkg/cache"
+	"github.com/katungi/aws-terror/pkg/metrics"
+)
+
+const (
+	// imageCacheTTL bounds how long resolved AMI names are reused. AMI names
+	// are immutable, so this only limits memory for long-running processes.
+	imageCacheTTL = time.Hour
+	// imageLookupTimeout bounds a shared DescribeImages lookup, which runs
+	// on behalf of every waiting caller rather than the one that started it
+	imageLookupTimeout = time.Minute
+)
+
+var (
+	// imageNames caches resolved AMI names for every client, since fleets
+	// are usually built from a handful of images. Entries are keyed by
+	// imageKey, as the same AMI ID may not be visible to every account.
+	imageNames = cache.NewCache(imageCacheTTL)
+	// imageLookups makes concurrent checks of instances sharing an AMI wait
+	// for a single DescribeImages call instead of each making one
+	imageLookups singleflight.Group
+)
+
+// errImageDeregistered is returned for an AMI that no longer exists, which
+// is common for instances launched long ago
+var errImageDeregistered = errors.New("image has been deregistered")
+
+// imageNotFoundCodes are the DescribeImages errors for an AMI that doesn't
+// exist; retrying them can't help
+var imageNotFoundCodes = map[string]bool{
+	"InvalidAMIID.NotFound":    true,
+	"InvalidAMIID.Unavailable": true,
+}
+
+// WithAMINameResolution resolves the instance's AMI ID to its name with
+// DescribeImages and stores it as ami_name, so instances can be compared
+// against Terraform configs that pin AMIs by name
+func WithAMINameResolution() Option {
+	return func(c *Client) {
+		c.resolveAMINames = true
+	}
+}
+
+// getImageName returns the name of an AMI used by an instance of the account
+// ownerID, using the cache when possible. Failed lookups aren't cached, so
+// the next instance using the AMI retries, but deregistered AMIs are, as an
+// empty name: they never come back.
+func (c *Client) getImageName(ctx context.Context, ownerID, imageID string) (string, error) {
+	key := imageKey(ownerID, c.region, imageID)
+	if name, ok := imageNames.Get(key); ok {
+		metrics.RecordAMINameLookup(true)
+		return imageName(name.(string))
+	}
+	metrics.RecordAMINameLookup(false)
+
+	name, err, _ := imageLookups.Do(key, func() (any, error) {
+		// A lookup that finished while this one waited already cached it
+		if name, ok := imageNames.Get(key); ok {
+			return name, nil
+		}
+		// Callers waiting on this lookup shouldn't fail because the one
+		// that started it was canceled
+		lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), imageLookupTimeout)
+		defer cancel()
+		return c.describeImageName(lookupCtx, key, imageID)
+	})
+	if err != nil {
+		return "", err
+	}
+	return imageName(name.(string))
+}
+
+// imageKey identifies an AMI in the image cache. AMI IDs are regional, and
+// private AMIs are only visible to the accounts they are shared with.
+func imageKey(ownerID, region, imageID string) string {
+	return ownerID + "/" + region + "/" + imageID
+}
+
+// imageName turns a cached name into the result of a lookup
+func imageName(name string) (string, error) {
+	if name == "" {
+		return "", errImageDeregistered
+	}
+	return name, nil
+}
+
+// describeImageName looks up the name of an AMI with DescribeImages and
+// caches it under key. An AMI that isn't found has been deregistered, which
+// is cached as an empty name.
+func (c *Client) describeImageName(ctx context.Context, key, imageID string) (string, error) {
+	paginator := ec2.NewDescribeImagesPaginator(c.ec2Client, &ec2.DescribeImagesInput{
+		ImageIds: []string{imageID},
+	})
+
+	var name string
+	for paginator.HasMorePages() && name == "" {
+		var page *ec2.DescribeImagesOutput
+		var notFound bool
+		err := c.retry(ctx, "DescribeImages", func() error {
+			var err error
+			page, err = paginator.NextPage(ctx)
+			if isImageNotFound(err) {
+				// The call worked; the image is gone
+				notFound = true
+				return nil
+			}
+			return err
+		})
+		if err != nil {
+			return "", fmt.Errorf("error describing image %s: %w", imageID, err)
+		}
+		if notFound {
+			break
+		}
+
+		for _, image := range page.Images {
+			if aws.ToString(image.ImageId) == imageID {
+				name = aws.ToString(image.Name)
+				break
+			}
+		}
+	}
+
+	imageNames.Set(key, name)
+	return imageName(name)
+}
+
+// isImageNotFound reports whether err says an AMI doesn't exist
+func isImageNotFound(err error) bool {
+	var apiErr smithy.APIError
+	return err != nil && errors.As(err, &apiErr) && imageNotFoundCodes[apiErr.ErrorCode()]
+}
@@ -0,0 +1,183 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetImageName_DescribesEachImageOnce(t *testing.T) {
+	imageNames.Clear()
+	defer imageNames.Clear()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		// Keep the call in flight so the concurrent lookups overlap
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<DescribeImagesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>1</requestId>
+  <imagesSet><item><imageId>ami-12345</imageId><name>golden-2025-03</name></item></imagesSet>
+</DescribeImagesResponse>`))
+	}))
+	defer server.Close()
+
+	client := &Client{ec2Client: ec2.New(ec2.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})}
+	WithCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown)(client)
+
+	var wg sync.WaitGroup
+	names := make([]string, 10)
+	for i := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name, err := client.getImageName(context.Background(), "123456789012", "ami-12345")
+			assert.NoError(t, err)
+			names[i] = name
+		}()
+	}
+	wg.Wait()
+
+	name, err := client.getImageName(context.Background(), "123456789012", "ami-12345")
+	assert.NoError(t, err)
+	assert.Equal(t, "golden-2025-03", name)
+	for _, name := range names {
+		assert.Equal(t, "golden-2025-03", name)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestGetImageName_Deregistered(t *testing.T) {
+	imageNames.Clear()
+	defer imageNames.Clear()
+
+	tests := []struct {
+		name     string
+		status   int
+		response string
+	}{
+		{
+			name:   "not found error",
+			status: http.StatusBadRequest,
+			response: `<Response><Errors><Error><Code>InvalidAMIID.NotFound</Code>
+<Message>The image id '[ami-gone]' does not exist</Message></Error></Errors><RequestID>1</RequestID></Response>`,
+		},
+		{
+			name:   "empty result",
+			status: http.StatusOK,
+			response: `<DescribeImagesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>1</requestId>
+  <imagesSet/>
+</DescribeImagesResponse>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imageNames.Clear()
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.Header().Set("Content-Type", "text/xml")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := &Client{ec2Client: ec2.New(ec2.Options{
+				Region:       "us-east-1",
+				BaseEndpoint: aws.String(server.URL),
+				Credentials:  aws.AnonymousCredentials{},
+			})}
+			WithCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown)(client)
+
+			_, err := client.getImageName(context.Background(), "123456789012", "ami-gone")
+			assert.ErrorIs(t, err, errImageDeregistered)
+
+			// Not retried, and remembered for the next instance
+			_, err = client.getImageName(context.Background(), "123456789012", "ami-gone")
+			assert.ErrorIs(t, err, errImageDeregistered)
+			assert.Equal(t, int32(1), calls.Load())
+		})
+	}
+}
+
+func TestGetImageName_KeyedByAccountAndRegion(t *testing.T) {
+	imageNames.Clear()
+	defer imageNames.Clear()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<DescribeImagesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>1</requestId>
+  <imagesSet><item><imageId>ami-12345</imageId><name>golden-2025-03</name></item></imagesSet>
+</DescribeImagesResponse>`))
+	}))
+	defer server.Close()
+
+	newClient := func(region string) *Client {
+		client := &Client{region: region, ec2Client: ec2.New(ec2.Options{
+			Region:       region,
+			BaseEndpoint: aws.String(server.URL),
+			Credentials:  aws.AnonymousCredentials{},
+		})}
+		WithCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown)(client)
+		return client
+	}
+	east, west := newClient("us-east-1"), newClient("us-west-2")
+
+	for _, lookup := range []struct {
+		client  *Client
+		ownerID string
+	}{
+		{east, "111111111111"},
+		{east, "111111111111"},
+		{east, "222222222222"},
+		{west, "111111111111"},
+	} {
+		_, err := lookup.client.getImageName(context.Background(), lookup.ownerID, "ami-12345")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestGetImageName_IgnoresCanceledCaller(t *testing.T) {
+	imageNames.Clear()
+	defer imageNames.Clear()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<DescribeImagesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>1</requestId>
+  <imagesSet><item><imageId>ami-12345</imageId><name>golden-2025-03</name></item></imagesSet>
+</DescribeImagesResponse>`))
+	}))
+	defer server.Close()
+
+	client := &Client{ec2Client: ec2.New(ec2.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})}
+	WithCircuitBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown)(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	name, err := client.getImageName(ctx, "123456789012", "ami-12345")
+	assert.NoError(t, err)
+	assert.Equal(t, "golden-2025-03", name)
+}
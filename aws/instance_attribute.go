@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// describedAttributes maps the attributes DescribeInstances doesn't always
+// return to the DescribeInstanceAttribute attribute holding them
+var describedAttributes = map[string]types.InstanceAttributeName{
+	"user_data":               types.InstanceAttributeNameUserData,
+	"disable_api_termination": types.InstanceAttributeNameDisableApiTermination,
+	"source_dest_check":       types.InstanceAttributeNameSourceDestCheck,
+}
+
+// WithInstanceAttributes fetches the attributes to check, which may be glob
+// patterns, that are only available from DescribeInstanceAttribute. Each
+// matched attribute costs one extra call per instance, so only the requested
+// ones are fetched.
+func WithInstanceAttributes(attributes ...string) Option {
+	return func(c *Client) {
+		c.describedAttributes = DescribedAttributes(attributes)
+	}
+}
+
+// DescribedAttributes returns the sorted names of the attributes matched by
+// the patterns that must be fetched with DescribeInstanceAttribute
+func DescribedAttributes(attributes []string) []string {
+	var names []string
+	for name := range describedAttributes {
+		for _, attr := range attributes {
+			root := strings.SplitN(attr, ".", 2)[0]
+			if matched, _ := path.Match(root, name); matched {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// getInstanceAttribute fetches an attribute of an instance that
+// DescribeInstances doesn't return, converted to the form Terraform keeps in
+// state. It reports false if the instance has no value for the attribute.
+func (c *Client) getInstanceAttribute(ctx context.Context, instanceID, name string) (any, bool, error) {
+	attribute, ok := describedAttributes[name]
+	if !ok {
+		return nil, false, fmt.Errorf("attribute %s is not fetched with DescribeInstanceAttribute", name)
+	}
+
+	var resp *ec2.DescribeInstanceAttributeOutput
+	err := c.retry(ctx, "DescribeInstanceAttribute", func() error {
+		var err error
+		resp, err = c.ec2Client.DescribeInstanceAttribute(ctx, &ec2.DescribeInstanceAttributeInput{
+			InstanceId: aws.String(instanceID),
+			Attribute:  attribute,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("error describing %s for instance %s: %w", name, instanceID, err)
+	}
+
+	return describedValue(name, resp)
+}
+
+// describedValue extracts the value of an attribute from a
+// DescribeInstanceAttribute response
+func describedValue(name string, resp *ec2.DescribeInstanceAttributeOutput) (any, bool, error) {
+	switch name {
+	case "user_data":
+		// State stores a hash of the user data, so compare against the same
+		if resp.UserData == nil || aws.ToString(resp.UserData.Value) == "" {
+			return nil, false, nil
+		}
+		return HashUserData(aws.ToString(resp.UserData.Value)), true, nil
+	case "disable_api_termination":
+		if resp.DisableApiTermination == nil {
+			return false, true, nil
+		}
+		return aws.ToBool(resp.DisableApiTermination.Value), true, nil
+	case "source_dest_check":
+		if resp.SourceDestCheck == nil {
+			return nil, false, nil
+		}
+		return aws.ToBool(resp.SourceDestCheck.Value), true, nil
+	default:
+		return nil, false, fmt.Errorf("no mapping for attribute %s", name)
+	}
+}
@@ -0,0 +1,57 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribedAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes []string
+		expected   []string
+	}{
+		{name: "Only DescribeInstances attributes", attributes: []string{"instance_type", "tags"}, expected: nil},
+		{name: "Requested attribute", attributes: []string{"instance_type", "user_data"}, expected: []string{"user_data"}},
+		{name: "Glob", attributes: []string{"*"}, expected: []string{"disable_api_termination", "source_dest_check", "user_data"}},
+		{name: "Duplicates", attributes: []string{"user_data", "user_*"}, expected: []string{"user_data"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DescribedAttributes(tt.attributes))
+		})
+	}
+}
+
+func TestDescribedValue(t *testing.T) {
+	value, ok, err := describedValue("disable_api_termination", &ec2.DescribeInstanceAttributeOutput{
+		DisableApiTermination: &types.AttributeBooleanValue{Value: aws.Bool(true)},
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, true, value)
+
+	value, ok, err = describedValue("source_dest_check", &ec2.DescribeInstanceAttributeOutput{
+		SourceDestCheck: &types.AttributeBooleanValue{Value: aws.Bool(false)},
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, false, value)
+
+	// Instances without user data have no user_data value, as in state
+	_, ok, err = describedValue("user_data", &ec2.DescribeInstanceAttributeOutput{})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	value, ok, err = describedValue("user_data", &ec2.DescribeInstanceAttributeOutput{
+		UserData: &types.AttributeValue{Value: aws.String("ZWNobyBoaQ==")},
+	})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, value, 40)
+}
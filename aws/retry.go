@@ -0,0 +1,89 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/cenkalti/backoff/v4"
+)
+
+const (
+	// backoffRandomizationFactor spreads retries of concurrent workers so
+	// they don't hit a throttled API in lockstep
+	backoffRandomizationFactor = 0.5
+	maxRetryElapsedTime        = 30 * time.Second
+)
+
+func newExponentialBackOff() *backoff.ExponentialBackOff {
+	b := backoff.NewExponentialBackOff()
+	b.RandomizationFactor = backoffRandomizationFactor
+	b.MaxElapsedTime = maxRetryElapsedTime
+	return b
+}
+
+// WithoutRetry makes every AWS call a single attempt, disabling both the
+// client's backoff and the SDK's own retries, so errors surface immediately
+func WithoutRetry() Option {
+	return func(c *Client) {
+		c.noRetry = true
+	}
+}
+
+// retryAfterBackOff waits at least as long as the server asked for via
+// Retry-After before the next attempt
+type retryAfterBackOff struct {
+	backoff.BackOff
+	retryAfter time.Duration
+}
+
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next == backoff.Stop {
+		return next
+	}
+	if b.retryAfter > next {
+		next = b.retryAfter
+	}
+	b.retryAfter = 0
+	return next
+}
+
+// retry runs operation with jittered exponential backoff, honoring any
+// Retry-After delay carried by the returned errors
+func retry(ctx context.Context, operation func() error) error {
+	b := &retryAfterBackOff{BackOff: newExponentialBackOff()}
+
+	return backoff.Retry(func() error {
+		err := operation()
+		if delay, ok := retryAfterDelay(err); ok {
+			b.retryAfter = delay
+		}
+		return err
+	}, backoff.WithContext(b, ctx))
+}
+
+// retryAfterDelay extracts the Retry-After header from an AWS error response
+func retryAfterDelay(err error) (time.Duration, bool) {
+	var respErr *smithyhttp.ResponseError
+	if err == nil || !errors.As(err, &respErr) || respErr.Response == nil || respErr.Response.Response == nil {
+		return 0, false
+	}
+
+	header := respErr.Response.Header.Get("Retry-After")
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return time.Until(at), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func throttlingError(retryAfter string) error {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	if retryAfter != "" {
+		resp.Header.Set("Retry-After", retryAfter)
+	}
+	return &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: resp},
+		Err:      errors.New("Throttling: Rate exceeded"),
+	}
+}
+
+func TestNewExponentialBackOff_HasJitter(t *testing.T) {
+	b := newExponentialBackOff()
+
+	assert.Greater(t, b.RandomizationFactor, 0.0)
+	assert.Equal(t, maxRetryElapsedTime, b.MaxElapsedTime)
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	delay, ok := retryAfterDelay(throttlingError("3"))
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, delay)
+
+	_, ok = retryAfterDelay(throttlingError(""))
+	assert.False(t, ok)
+
+	_, ok = retryAfterDelay(errors.New("connection reset by peer"))
+	assert.False(t, ok)
+}
+
+func TestRetryAfterBackOff_HonorsServerDelay(t *testing.T) {
+	b := &retryAfterBackOff{BackOff: backoff.NewConstantBackOff(10 * time.Millisecond)}
+
+	b.retryAfter = 2 * time.Second
+	assert.Equal(t, 2*time.Second, b.NextBackOff())
+	assert.Equal(t, 10*time.Millisecond, b.NextBackOff())
+}
@@ -0,0 +1,66 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// mapMarketOptions maps a spot instance to Terraform's instance_market_options
+// blocks, looking up its spot request for the spot_options. On-demand
+// instances have no market options.
+func (c *Client) mapMarketOptions(ctx context.Context, warn *warnings, instance types.Instance) []map[string]any {
+	if instance.InstanceLifecycle != types.InstanceLifecycleTypeSpot {
+		return []map[string]any{}
+	}
+
+	marketOptions := map[string]any{"market_type": "spot"}
+	if requestID := aws.ToString(instance.SpotInstanceRequestId); requestID != "" {
+		spotOptions, err := c.getSpotOptions(ctx, requestID)
+		if err != nil {
+			c.warnf(warn, "Failed to get spot request %s: %v", requestID, err)
+		} else {
+			marketOptions["spot_options"] = []map[string]any{spotOptions}
+		}
+	}
+
+	return []map[string]any{marketOptions}
+}
+
+// getSpotOptions returns the spot_options of a spot instance request
+func (c *Client) getSpotOptions(ctx context.Context, requestID string) (map[string]any, error) {
+	var resp *ec2.DescribeSpotInstanceRequestsOutput
+	err := c.retry(ctx, "DescribeSpotInstanceRequests", func() error {
+		var err error
+		resp, err = c.ec2Client.DescribeSpotInstanceRequests(ctx, &ec2.DescribeSpotInstanceRequestsInput{
+			SpotInstanceRequestIds: []string{requestID},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error describing spot request %s: %w", requestID, err)
+	}
+
+	if len(resp.SpotInstanceRequests) == 0 {
+		return nil, fmt.Errorf("spot request %s not found", requestID)
+	}
+
+	return spotOptions(resp.SpotInstanceRequests[0]), nil
+}
+
+// spotOptions maps a spot request to the fields of Terraform's spot_options
+func spotOptions(request types.SpotInstanceRequest) map[string]any {
+	options := map[string]any{
+		"instance_interruption_behavior": string(request.InstanceInterruptionBehavior),
+		"spot_instance_type":             string(request.Type),
+		"max_price":                      aws.ToString(request.SpotPrice),
+	}
+	if request.ValidUntil != nil {
+		options["valid_until"] = request.ValidUntil.UTC().Format(time.RFC3339)
+	}
+	return options
+}
@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// InstanceSummary identifies an instance found by ListInstances
+type InstanceSummary struct {
+	ID         string            `json:"instance_id"`
+	State      string            `json:"state"`
+	LaunchTime time.Time         `json:"launch_time"`
+	Tags       map[string]string `json:"tags,omitempty"`
+}
+
+// ListInstances returns every instance in the region that hasn't been
+// terminated, ordered by ID. With tags, only instances having all of the
+// given tag values are returned.
+func (c *Client) ListInstances(ctx context.Context, tags map[string]string) ([]InstanceSummary, error) {
+	paginator := ec2.NewDescribeInstancesPaginator(c.ec2Client, &ec2.DescribeInstancesInput{
+		Filters: instanceFilters(tags),
+	})
+
+	var instances []InstanceSummary
+	for paginator.HasMorePages() {
+		var page *ec2.DescribeInstancesOutput
+		err := c.retry(ctx, "DescribeInstances", func() error {
+			var err error
+			page, err = paginator.NextPage(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing instances: %w", err)
+		}
+
+		for _, reservation := range page.Reservations {
+			for _, instance := range reservation.Instances {
+				instances = append(instances, summarizeInstance(instance))
+			}
+		}
+	}
+
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+	return instances, nil
+}
+
+// IsTerminated reports whether an instance state is terminated or
+// shutting-down. Such instances linger in DescribeInstances for a while with
+// most of their attributes gone, so comparing them is mostly noise.
+func IsTerminated(state string) bool {
+	return state == string(types.InstanceStateNameTerminated) || state == string(types.InstanceStateNameShuttingDown)
+}
+
+// instanceFilters selects instances that aren't terminated and have the
+// given tag values
+func instanceFilters(tags map[string]string) []types.Filter {
+	filters := []types.Filter{{
+		Name:   aws.String("instance-state-name"),
+		Values: []string{"pending", "running", "shutting-down", "stopping", "stopped"},
+	}}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		filters = append(filters, types.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: []string{tags[key]},
+		})
+	}
+	return filters
+}
+
+func summarizeInstance(instance types.Instance) InstanceSummary {
+	summary := InstanceSummary{
+		ID:         aws.ToString(instance.InstanceId),
+		LaunchTime: aws.ToTime(instance.LaunchTime),
+		Tags:       make(map[string]string, len(instance.Tags)),
+	}
+	if instance.State != nil {
+		summary.State = string(instance.State.Name)
+	}
+	for _, tag := range instance.Tags {
+		summary.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return summary
+}
+
+// UnmanagedInstances returns the instances whose IDs aren't in managedIDs,
+// i.e. instances that no Terraform resource manages
+func UnmanagedInstances(instances []InstanceSummary, managedIDs []string) []InstanceSummary {
+	managed := make(map[string]bool, len(managedIDs))
+	for _, id := range managedIDs {
+		managed[id] = true
+	}
+
+	var unmanaged []InstanceSummary
+	for _, instance := range instances {
+		if !managed[instance.ID] {
+			unmanaged = append(unmanaged, instance)
+		}
+	}
+	return unmanaged
+}
@@ -0,0 +1,48 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceFilters(t *testing.T) {
+	filters := instanceFilters(map[string]string{"Team": "web", "Env": "prod"})
+
+	assert.Len(t, filters, 3)
+	assert.Equal(t, "instance-state-name", aws.ToString(filters[0].Name))
+	assert.NotContains(t, filters[0].Values, "terminated")
+	assert.Equal(t, "tag:Env", aws.ToString(filters[1].Name))
+	assert.Equal(t, []string{"prod"}, filters[1].Values)
+	assert.Equal(t, "tag:Team", aws.ToString(filters[2].Name))
+}
+
+func TestIsTerminated(t *testing.T) {
+	assert.True(t, IsTerminated("terminated"))
+	assert.True(t, IsTerminated("shutting-down"))
+	assert.False(t, IsTerminated("stopped"))
+	assert.False(t, IsTerminated(""))
+}
+
+func TestSummarizeInstance(t *testing.T) {
+	summary := summarizeInstance(types.Instance{
+		InstanceId: aws.String("i-1"),
+		State:      &types.InstanceState{Name: types.InstanceStateNameStopped},
+		Tags:       []types.Tag{{Key: aws.String("Name"), Value: aws.String("bastion")}},
+	})
+
+	assert.Equal(t, "i-1", summary.ID)
+	assert.Equal(t, "stopped", summary.State)
+	assert.Equal(t, map[string]string{"Name": "bastion"}, summary.Tags)
+}
+
+func TestUnmanagedInstances(t *testing.T) {
+	instances := []InstanceSummary{{ID: "i-1"}, {ID: "i-2"}, {ID: "i-3"}}
+
+	unmanaged := UnmanagedInstances(instances, []string{"i-1", "i-3", "i-9"})
+
+	assert.Equal(t, []InstanceSummary{{ID: "i-2"}}, unmanaged)
+	assert.Empty(t, UnmanagedInstances(instances, []string{"i-1", "i-2", "i-3"}))
+}
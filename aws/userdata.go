@@ -0,0 +1,19 @@
+package aws
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// HashUserData returns the hash the AWS provider stores for user_data: the
+// hex SHA1 of the content, base64-decoding it first if it is encoded
+func HashUserData(userData string) string {
+	content, err := base64.StdEncoding.DecodeString(userData)
+	if err != nil {
+		content = []byte(userData)
+	}
+
+	hash := sha1.Sum(content)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,26 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashUserData(t *testing.T) {
+	// sha1("#!/bin/bash\necho hello\n")
+	const expected = "7ab9e1ebee7aa7f6ab88b6001c017d19c3e27d14"
+
+	tests := []struct {
+		name     string
+		userData string
+	}{
+		{name: "plain text", userData: "#!/bin/bash\necho hello\n"},
+		{name: "base64 encoded", userData: "IyEvYmluL2Jhc2gKZWNobyBoZWxsbwo="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, expected, HashUserData(tt.userData))
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// WarningsKey holds the warnings, if any, met while reading an instance, so
+// partial data shows up in reports and not only in the log
+const WarningsKey = "warnings"
+
+// warnings collects the warnings for one instance; lookups running
+// concurrently may add to it
+type warnings struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (w *warnings) add(message string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, message)
+}
+
+// list returns the warnings sorted, since concurrent lookups add them in no
+// particular order
+func (w *warnings) list() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	messages := append([]string(nil), w.messages...)
+	sort.Strings(messages)
+	return messages
+}
+
+// warnf logs a warning and records it in warn, if not nil
+func (c *Client) warnf(warn *warnings, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	c.logger.Warn(message)
+	if warn != nil {
+		warn.add(message)
+	}
+}
@@ -0,0 +1,32 @@
+package aws
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarnf(t *testing.T) {
+	client := &Client{logger: logrus.New()}
+	warn := &warnings{}
+
+	var wg sync.WaitGroup
+	for _, volumeID := range []string{"vol-2", "vol-1"} {
+		wg.Add(1)
+		go func(volumeID string) {
+			defer wg.Done()
+			client.warnf(warn, "Failed to get volume information for %s", volumeID)
+		}(volumeID)
+	}
+	wg.Wait()
+
+	// Without a collector the warning is only logged
+	client.warnf(nil, "Failed to resolve AMI name")
+
+	assert.Equal(t, []string{
+		"Failed to get volume information for vol-1",
+		"Failed to get volume information for vol-2",
+	}, warn.list())
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/katungi/aws-terror/aws"
+	"github.com/spf13/cobra"
+)
+
+var attributesCmd = &cobra.Command{
+	Use:   "attributes",
+	Short: "List the attributes that can be checked for drift",
+	Long: `List the aws_instance attributes that AWS-Terror fetches from AWS and
+can compare against Terraform. Use these names with the --attributes flag
+of the drift command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if strings.ToLower(outputFormat) == "json" {
+			jsonData, err := json.MarshalIndent(aws.SupportedAttributes, "", "  ")
+			if err != nil {
+				logger.Fatalf("Failed to format attributes: %v", err)
+			}
+			fmt.Println(string(jsonData))
+			return
+		}
+
+		for _, attr := range aws.SupportedAttributes {
+			fmt.Printf("%-30s %s\n", attr.Name, attr.Description)
+		}
+
+		fmt.Println("\nPresets (--attributes-preset):")
+		for _, name := range presetNames() {
+			fmt.Printf("%-30s %s\n", name, strings.Join(attributePresets[name], ", "))
+		}
+	},
+}
+
+var validateAttributesCmd = &cobra.Command{
+	Use:   "validate ATTRIBUTE...",
+	Short: "Check that attribute names are recognized",
+	Long: `Check attribute names, as given to --attributes, against the attributes
+that can be checked for drift. Nested paths such as tags.Name are checked by
+their top-level attribute. Unrecognized names never drift, so a typo would
+otherwise go unnoticed:
+
+  aws-terror attributes validate instance_type,tags.Name,instanc_type`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var attributes []string
+		for _, arg := range args {
+			attributes = append(attributes, strings.Split(arg, ",")...)
+		}
+
+		unknown := unrecognizedAttributes(attributes)
+		if len(unknown) > 0 {
+			logger.Fatal(unrecognizedMessage(unknown))
+		}
+		fmt.Printf("All %d attributes are recognized\n", len(attributes))
+	},
+}
+
+// unrecognizedAttributes returns the attributes that aren't supported,
+// ignoring from-config
+func unrecognizedAttributes(attributes []string) []string {
+	return aws.UnrecognizedAttributes(slices.DeleteFunc(slices.Clone(attributes), func(attr string) bool {
+		return strings.TrimSpace(attr) == fromConfig
+	}))
+}
+
+// unrecognizedMessage describes unrecognized attributes, suggesting the
+// closest known attribute for likely typos
+func unrecognizedMessage(unknown []string) string {
+	described := make([]string, len(unknown))
+	for i, attr := range unknown {
+		described[i] = attr
+		root, _, _ := strings.Cut(attr, ".")
+		if suggestion := aws.ClosestAttribute(root); suggestion != "" {
+			described[i] += fmt.Sprintf(" (did you mean %s?)", suggestion)
+		}
+	}
+	return fmt.Sprintf("Unrecognized attributes, which never drift: %s (see aws-terror attributes)", strings.Join(described, ", "))
+}
+
+func init() {
+	rootCmd.AddCommand(attributesCmd)
+	attributesCmd.AddCommand(validateAttributesCmd)
+}
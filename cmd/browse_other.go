@@ -0,0 +1,19 @@
+//go:build !tui
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+// interactiveSupported reports whether --interactive is built in. The
+// terminal UI is only built with -tags tui, so other builds don't carry its
+// dependencies.
+const interactiveSupported = false
+
+// browseReports fails, since the terminal UI isn't built in
+func browseReports([]drift.Report) error {
+	return errors.New("--interactive requires a build with -tags tui")
+}
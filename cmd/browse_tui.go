@@ -0,0 +1,16 @@
+//go:build tui
+
+package cmd
+
+import (
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/katungi/aws-terror/pkg/tui"
+)
+
+// interactiveSupported reports whether --interactive is built in
+const interactiveSupported = true
+
+// browseReports shows the reports in the terminal UI until the user quits
+func browseReports(reports []drift.Report) error {
+	return tui.Run(reports)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+)
+
+// maxAutoConcurrency caps --concurrency auto. Each check makes a few EC2
+// Describe calls, and EC2 refills its Describe request tokens at roughly 20
+// per second, so more workers mostly add throttling.
+const maxAutoConcurrency = 20
+
+// workerCount resolves --concurrency for a run over instanceCount instances.
+// "auto" picks min(instanceCount, GOMAXPROCS*4, 20), at least 1.
+func workerCount(concurrency string, instanceCount int) (int, error) {
+	if concurrency != "auto" {
+		n, err := strconv.Atoi(concurrency)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid --concurrency %q: must be a positive number or auto", concurrency)
+		}
+		return n, nil
+	}
+
+	n := min(instanceCount, runtime.GOMAXPROCS(0)*4, maxAutoConcurrency)
+	return max(n, 1), nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile is looked up in the working directory, then the home
+// directory, when --config-file is not given
+const defaultConfigFile = ".aws-terror.yaml"
+
+var configFile string
+
+// configFilePath returns the config file to load, or "" if there is none
+func configFilePath() string {
+	if configFile != "" {
+		return configFile
+	}
+
+	candidates := []string{defaultConfigFile}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, defaultConfigFile))
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// envFallbacks are the environment variables flags fall back to when
+// neither the command line nor the config file sets them. They are applied
+// after parsing rather than as flag defaults, so secrets stay out of --help.
+var envFallbacks = map[string]string{
+	"region":         "AWS_REGION",
+	"state-username": "TF_HTTP_USERNAME",
+	"state-password": "TF_HTTP_PASSWORD",
+	"tfc-address":    "TFE_ADDRESS",
+	"tfc-token":      "TFE_TOKEN",
+}
+
+// envFallbackFlags are the flags applyEnvFallbacks set, with the variable
+// each was read from
+var envFallbackFlags = map[string]string{}
+
+// applyEnvFallbacks sets the flags of cmd left unset by the command line and
+// config file from their envFallbacks variables
+func applyEnvFallbacks(cmd *cobra.Command) error {
+	for name, key := range envFallbacks {
+		f := cmd.Flags().Lookup(name)
+		if f == nil || f.Changed || configFileFlags[name] {
+			continue
+		}
+		val, ok := os.LookupEnv(key)
+		if !ok || val == "" {
+			continue
+		}
+		if err := f.Value.Set(val); err != nil {
+			return fmt.Errorf("invalid %s in %s: %w", name, key, err)
+		}
+		envFallbackFlags[name] = key
+	}
+	return nil
+}
+
+// loadConfigFile sets the flags of cmd that were not given on the command
+// line from the config file. Keys are flag names; values override the
+// environment defaults but not explicit flags.
+func loadConfigFile(cmd *cobra.Command) error {
+	path := configFilePath()
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var settings map[string]any
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	var setErr error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		value, ok := settings[f.Name]
+		if !ok || f.Changed || setErr != nil {
+			return
+		}
+		if err := f.Value.Set(configValue(value)); err != nil {
+			setErr = fmt.Errorf("invalid %s in config file %s: %w", f.Name, path, err)
+			return
+		}
+		configFileFlags[f.Name] = true
+	})
+	loadedConfigFile = path
+	return setErr
+}
+
+// configValue renders a YAML value in the string form a flag accepts:
+// lists become comma-separated and maps key=value pairs
+func configValue(value any) string {
+	switch v := value.(type) {
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]any:
+		pairs := make([]string, 0, len(v))
+		for key, item := range v {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", key, item))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
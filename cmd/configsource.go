@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/katungi/aws-terror/pkg/terraform"
+	"github.com/sirupsen/logrus"
+)
+
+// checkoutConfig replaces a git source configuration path, e.g.
+// git::https://example.com/infra.git//prod?ref=main, with a shallow clone of
+// it. The returned func removes the clone, which is also removed if a fatal
+// error exits before it runs; other paths are left as they are.
+func checkoutConfig(configPath *string) func() {
+	if !terraform.IsGitSource(*configPath) {
+		return func() {}
+	}
+
+	globalSpinner.UpdateMessage("Cloning Terraform configuration")
+	dir, cleanup, err := terraform.CheckoutGitSource(*configPath)
+	if err != nil {
+		globalSpinner.Error(fmt.Sprintf("Failed to check out Terraform configuration: %v", err))
+		logger.Fatalf("Failed to check out Terraform configuration: %v", err)
+	}
+	logger.Debugf("Checked out %s to %s", *configPath, dir)
+	*configPath = dir
+	logrus.RegisterExitHandler(cleanup)
+	return cleanup
+}
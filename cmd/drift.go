@@ -4,14 +4,26 @@ Copyright © 2025 Daniel Denis <dankatdennis@gmail.com>
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/katungi/aws-terror/aws"
+	"github.com/katungi/aws-terror/pkg/cache"
 	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/katungi/aws-terror/pkg/metrics"
+	"github.com/katungi/aws-terror/pkg/notify"
 	"github.com/katungi/aws-terror/pkg/output"
 	"github.com/katungi/aws-terror/pkg/terraform"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 var driftCmd = &cobra.Command{
@@ -22,129 +34,481 @@ var driftCmd = &cobra.Command{
 You can also use simulation mode to compare two Terraform state files without AWS access:
   aws-terror drift -i INSTANCE_ID -s SOURCE_STATE -t TARGET_STATE --simulate
 
+or two HCL configurations, identifying the resource by ID or address:
+  aws-terror drift -i aws_instance.web -c SOURCE_DIR --target-config TARGET_DIR --simulate
+
 Cobra is a CLI library for Go that empowers applications.
 This application is a tool to generate the needed files
 to quickly create a Cobra application.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		runStartedAt := time.Now()
+		// Check if simulation mode is enabled
+		simulate, _ := cmd.Flags().GetBool("simulate")
+		targetState, _ := cmd.Flags().GetString("target-state")
+		targetConfig, _ := cmd.Flags().GetString("target-config")
+
 		instanceIDs, err := cmd.Flags().GetStringSlice("instances")
-		if err != nil || len(instanceIDs) == 0 {
+		if err != nil || (len(instanceIDs) == 0 && len(resourceFilter) == 0 && !simulate && !unmanaged) {
 			globalSpinner.Error("Instance ID is required")
 			logger.Fatal("Instance ID is required")
 		}
 
-		// Check if simulation mode is enabled
-		simulate, _ := cmd.Flags().GetBool("simulate")
-		targetState, _ := cmd.Flags().GetString("target-state")
+		if quiet {
+			logger.SetLevel(logrus.ErrorLevel)
+		}
+		if interactive && !interactiveSupported {
+			globalSpinner.Error("--interactive requires a build with -tags tui")
+			logger.Fatal("--interactive requires a build with -tags tui")
+		}
+		slackMinSeverity = strings.ToLower(slackMinSeverity)
+		if slackMinSeverity != "" && drift.Severity(slackMinSeverity).Rank() == 0 {
+			globalSpinner.Error("--slack-min-severity must be one of low, medium, high")
+			logger.Fatal("--slack-min-severity must be one of low, medium, high")
+		}
+		if groupBy != "" && !strings.HasPrefix(groupBy, "tag:") {
+			globalSpinner.Error("--group-by must be of the form tag:<key>")
+			logger.Fatal("--group-by must be of the form tag:<key>")
+		}
+		if sortBy != "" && !slices.Contains(sortOrders, sortBy) {
+			globalSpinner.Error(fmt.Sprintf("--sort-by must be one of %s", strings.Join(sortOrders, ", ")))
+			logger.Fatalf("--sort-by must be one of %s", strings.Join(sortOrders, ", "))
+		}
+
+		defer checkoutConfig(&tfConfigPath)()
+		defer checkoutConfig(&targetConfig)()
+
+		// Simulation reads state URLs the same way as a drift check
+		httpState := terraform.HTTPStateConfig{Username: stateUsername, Password: statePassword, Timeout: stateTimeout}
 
 		if simulate {
-			if tfStatePath == "" || targetState == "" {
-				globalSpinner.Error("Both source and target state files are required for simulation mode")
-				logger.Fatal("Both source and target state files are required for simulation mode")
-			}
-			globalSpinner.UpdateMessage("Starting drift simulation")
+			// The AWS defaults don't apply between two configurations
+			opts := detectOptions()
+			opts.Defaults = nil
 
-			drifts, err := terraform.SimulateDrift(tfStatePath, targetState, instanceIDs[0])
+			// Without instances, every aws_instance of the two states is compared
+			if len(instanceIDs) == 0 {
+				if tfStatePath == "" || targetState == "" {
+					globalSpinner.Error("Comparing whole states requires --state and --target-state")
+					logger.Fatal("Comparing whole states requires --state and --target-state")
+				}
+				globalSpinner.UpdateMessage("Comparing state files")
+				diffs, err := terraform.SimulateStateDiff(tfStatePath, targetState, opts, httpState)
+				if err == nil && len(resourceFilter) > 0 {
+					diffs, err = filterStateDiff(diffs, resourceFilter)
+				}
+				if err != nil {
+					logger.Fatalf("Simulation failed: %v", err)
+				}
+				for _, diff := range diffs {
+					drift.Redact(diff.Drifts, redactAttributes)
+				}
+				fmt.Println(formatStateDiff(diffs))
+				return
+			}
+			var drifts map[string]drift.DriftDetail
+			switch {
+			case tfStatePath != "" && targetState != "":
+				globalSpinner.UpdateMessage("Starting drift simulation")
+				drifts, err = terraform.SimulateDrift(tfStatePath, targetState, instanceIDs[0], opts, httpState)
+			case tfConfigPath != "" && targetConfig != "":
+				globalSpinner.UpdateMessage("Starting drift simulation")
+				drifts, err = terraform.SimulateHCLDrift(tfConfigPath, targetConfig, instanceIDs[0], opts)
+			default:
+				globalSpinner.Error("Both source and target state files (or HCL configs) are required for simulation mode")
+				logger.Fatal("Both source and target state files (or HCL configs) are required for simulation mode")
+			}
 			if err != nil {
 				logger.Fatalf("Simulation failed: %v", err)
 			}
+			drift.Redact(drifts, redactAttributes)
 
 			// Format and output results
-			formattedOutput := output.FormatDriftResults(drifts, instanceIDs[0], outputFormat)
-			fmt.Println(formattedOutput)
+			fmt.Println(formatResults(instanceResult{instanceID: instanceIDs[0], drifts: drifts}))
 			return
 		}
 
+		if tfcWorkspace != "" {
+			if tfStatePath != "" {
+				globalSpinner.Error("--state and --tfc-workspace cannot be used together")
+				logger.Fatal("--state and --tfc-workspace cannot be used together")
+			}
+			globalSpinner.UpdateMessage("Fetching state from Terraform Cloud")
+			tfStatePath, httpState, err = terraform.UseTFCState(terraform.TFCWorkspace{
+				Address:      tfcAddress,
+				Token:        tfcToken,
+				Organization: tfcOrganization,
+				Name:         tfcWorkspace,
+			}, httpState)
+			if err != nil {
+				globalSpinner.Error(fmt.Sprintf("Failed to fetch Terraform Cloud state: %v", err))
+				logger.Fatalf("Failed to fetch Terraform Cloud state: %v", err)
+			}
+		}
+
+		ignoreRules, err = loadIgnoreRules()
+		if err != nil {
+			globalSpinner.Error(err.Error())
+			logger.Fatal(err)
+		}
+
+		if unmanaged {
+			detectUnmanaged(cmd, tfStatePath, httpState)
+			return
+		}
+		if resultCachePath != "" {
+			resultCache, err = cache.OpenStore(resultCachePath)
+			if err != nil {
+				globalSpinner.Error(err.Error())
+				logger.Fatal(err)
+			}
+		}
+
+		// Attributes that aren't recognized would silently never drift
+		if unknown := unrecognizedAttributes(attributesToCheck); len(unknown) > 0 {
+			if strictAttrs {
+				globalSpinner.Error(unrecognizedMessage(unknown))
+				logger.Fatal(unrecognizedMessage(unknown))
+			}
+			logger.Warn(unrecognizedMessage(unknown))
+		}
+
 		if tfStatePath == "" && tfConfigPath == "" {
 			globalSpinner.Error("Either Terraform state file or HCL configuration path is required")
 			logger.Fatal("Either Terraform state file or HCL configuration path is required")
 		}
+
+		// Read the plan before checking, so a bad plan fails fast
+		var planDrifts []terraform.PlanDrift
+		if planPath != "" {
+			planDrifts, err = terraform.ParsePlanDrift(planPath)
+			if err != nil {
+				globalSpinner.Error(fmt.Sprintf("Failed to read Terraform plan: %v", err))
+				logger.Fatalf("Failed to read Terraform plan: %v", err)
+			}
+		}
+		if managedPlanPath != "" {
+			configuredAttrs, err = terraform.ParsePlanConfiguredAttributes(managedPlanPath)
+			if err != nil {
+				globalSpinner.Error(fmt.Sprintf("Failed to read --managed-only plan: %v", err))
+				logger.Fatalf("Failed to read --managed-only plan: %v", err)
+			}
+		}
 		globalSpinner.UpdateMessage("Initializing drift detection")
 
+		// Without instance IDs every instance in state is checked, including
+		// ones terminated since the state was written
+		scanning := len(instanceIDs) == 0
+		if len(resourceFilter) > 0 {
+			if tfStatePath == "" {
+				globalSpinner.Error("--resource-filter requires --state")
+				logger.Fatal("--resource-filter requires --state")
+			}
+			instanceIDs, err = filterInstances(tfStatePath, instanceIDs, resourceFilter, httpState)
+			if err != nil {
+				globalSpinner.Error(err.Error())
+				logger.Fatal(err)
+			}
+			if len(instanceIDs) == 0 {
+				globalSpinner.Error("No instances in state match --resource-filter")
+				logger.Fatal("No instances in state match --resource-filter")
+			}
+		}
+
+		if tfStatePath != "" && (minStateSerial > 0 || expectTFVersion != "") {
+			statePaths, err := terraform.ExpandStatePaths(tfStatePath)
+			if err != nil {
+				logger.Warnf("Failed to read state metadata: %v", err)
+			}
+			for _, path := range statePaths {
+				metadata, err := terraform.ReadStateMetadata(path, httpState)
+				if err != nil {
+					logger.Warnf("Failed to read state metadata of %s: %v", path, err)
+					continue
+				}
+				for _, warning := range metadata.StalenessWarnings(minStateSerial, expectTFVersion) {
+					if len(statePaths) > 1 {
+						warning = path + ": " + warning
+					}
+					logger.Warn(warning)
+				}
+			}
+		}
+
 		// Initialize AWS client
 		globalSpinner.UpdateMessage("Initializing AWS client")
-		awsClient, err := aws.NewClient(awsRegion, logger)
+		awsClient, err := aws.NewClient(awsRegion, logger, driftClientOptions()...)
 		if err != nil {
 			globalSpinner.Error(fmt.Sprintf("Failed to initialize AWS client: %v", err))
 			logger.Fatalf("Failed to initialize AWS client: %v", err)
 		}
 
+		if reportDir != "" {
+			if err := os.MkdirAll(reportDir, 0755); err != nil {
+				globalSpinner.Error(fmt.Sprintf("Failed to create report directory: %v", err))
+				logger.Fatalf("Failed to create report directory: %v", err)
+			}
+		}
+
+		var webhook *notify.Webhook
+		if webhookURL != "" {
+			webhook = notify.NewWebhook(webhookURL, webhookTimeout)
+		}
+		var slack *notify.Slack
+		if slackWebhookURL != "" {
+			slack = notify.NewSlack(slackWebhookURL, webhookTimeout)
+		}
+
 		// Create channels for results and errors
-		resultsChan := make(chan struct {
-			instanceID string
-			drifts     map[string]drift.DriftDetail
-			err        error
-		}, len(instanceIDs))
+		resultsChan := make(chan instanceResult, len(instanceIDs))
 
 		// Process instances concurrently with worker pool
-		workerPool := make(chan struct{}, maxConcurrency)
+		workers, err := workerCount(concurrency, len(instanceIDs))
+		if err != nil {
+			globalSpinner.Error(err.Error())
+			logger.Fatal(err)
+		}
+		logger.Debugf("Checking %d instances with %d workers", len(instanceIDs), workers)
+
+		// State files are read once up front, with as many in parallel as
+		// there are workers, rather than by every instance check
+		var stateIndex *terraform.StateIndex
+		if tfStatePath != "" {
+			globalSpinner.UpdateMessage("Parsing Terraform state")
+			stateIndex, err = terraform.BuildStateIndex(tfStatePath, workers, httpState)
+			if err != nil {
+				globalSpinner.Error(fmt.Sprintf("Failed to read Terraform state: %v", err))
+				logger.Fatalf("Failed to read Terraform state: %v", err)
+			}
+		}
+		instanceIDs = withoutIgnoredInstances(instanceIDs, stateIndex)
+		workerPool := make(chan struct{}, workers)
 		for _, id := range instanceIDs {
 			workerPool <- struct{}{} // Acquire worker
 			go func(instanceID string) {
 				defer func() { <-workerPool }() // Release worker
 
-				// Fetch EC2 instance configuration from AWS
-				logger.Infof("Fetching EC2 instance %s configuration from AWS...", instanceID)
-				awsConfig, err := awsClient.GetEC2InstanceConfig(cmd.Context(), instanceID)
-				if err != nil {
-					resultsChan <- struct {
-						instanceID string
-						drifts     map[string]drift.DriftDetail
-						err        error
-					}{instanceID: instanceID, err: fmt.Errorf("failed to get EC2 instance config: %v", err)}
-					return
+				resultsChan <- checkInstance(cmd.Context(), awsClient, instanceID, stateIndex, tfConfigPath, scanning && !includeTerminated)
+			}(id)
+		}
+
+		// Collect and process results. Full detail goes to stdout and
+		// --output-file; with --summary-only stdout only gets the summary line.
+		var hasErrors bool
+		var report strings.Builder
+		driftedInstances, skippedInstances := 0, 0
+		reasonCounts := make(map[drift.Reason]int)
+		// emitSplit writes different text to stdout and --output-file, for
+		// output that --top-severity-only shortens on stdout only
+		emitSplit := func(shown, full string) {
+			if !summaryOnly {
+				fmt.Print(shown)
+			}
+			if outputFile != "" {
+				report.WriteString(full)
+			}
+		}
+		emit := func(format string, args ...any) {
+			text := fmt.Sprintf(format, args...)
+			emitSplit(text, text)
+		}
+		// The wide table is rendered once across all instances at the end
+		tableOutput := output.IsTableFormat(outputFormat) && !onlyDrifted
+		var tableReports []drift.Report
+		emitTable := func() {
+			shownReports := tableReports
+			if topSeverityOnly {
+				shownReports = make([]drift.Report, len(tableReports))
+				for i, tableReport := range tableReports {
+					shownReports[i] = tableReport.TopSeverity()
 				}
+			}
+			// Only stdout is fitted to the terminal; the file keeps full values
+			emitSplit(output.FormatTable(shownReports, terminalWidth())+"\n", output.FormatTable(tableReports, 0)+"\n")
+			tableReports = nil
+		}
+		handleResult := func(result instanceResult) {
+			// Output results for each instance
+			formattedOutput := formatResults(result)
+			if tableOutput {
+				tableReports = append(tableReports, result.report())
+			} else {
+				shownOutput := formattedOutput
+				if topSeverityOnly {
+					shownOutput = formatResults(result.topSeverity())
+				}
+				// Other formats hold nothing but reports, so they can be
+				// parsed back, e.g. by report-diff
+				header := ""
+				if textOutput() {
+					header = fmt.Sprintf("\nResults for instance %s:\n", result.instanceID)
+				}
+				emitSplit(header+shownOutput+"\n", header+formattedOutput+"\n")
+			}
+			if suggestReplace {
+				if suggestion := replaceSuggestion(result); suggestion != "" && textOutput() {
+					emit("%s\n", suggestion)
+				} else if suggestion != "" {
+					fmt.Fprintln(os.Stderr, suggestion)
+				}
+			}
+
+			if reportDir != "" {
+				reportPath := filepath.Join(reportDir, result.instanceID+"."+output.FileExtension(outputFormat))
+				if err := os.WriteFile(reportPath, []byte(formattedOutput+"\n"), 0644); err != nil {
+					logger.Errorf("Failed to write report for instance %s: %v", result.instanceID, err)
+					hasErrors = true
+				}
+			}
 
-				// Parse Terraform configuration
-				var tfConfig map[string]interface{}
-				if tfStatePath != "" {
-					tfConfig, err = terraform.ParseStateFile(tfStatePath, instanceID)
-				} else {
-					tfConfig, err = terraform.ParseHCLConfig(tfConfigPath, instanceID)
+			if webhook != nil {
+				payload := output.FormatReport(result.report(), "json", output.Options{})
+				if err := webhook.Send(cmd.Context(), []byte(payload)); err != nil {
+					logger.Errorf("Failed to send webhook for instance %s: %v", result.instanceID, err)
+				}
+			}
+			if slack != nil {
+				notable := atLeastSeverity(result.drifts, drift.Severity(slackMinSeverity))
+				if err := slack.Notify(cmd.Context(), result.instanceID, notable); err != nil {
+					logger.Errorf("Failed to send Slack notification for instance %s: %v", result.instanceID, err)
 				}
+			}
 
-				if err != nil {
-					resultsChan <- struct {
-						instanceID string
-						drifts     map[string]drift.DriftDetail
-						err        error
-					}{instanceID: instanceID, err: fmt.Errorf("failed to parse Terraform configuration: %v", err)}
-					return
-				}
-
-				// Detect drift
-				drifts, err := drift.DetectDrift(awsConfig, tfConfig, attributesToCheck)
-				resultsChan <- struct {
-					instanceID string
-					drifts     map[string]drift.DriftDetail
-					err        error
-				}{instanceID: instanceID, drifts: drifts, err: err}
-			}(id)
+			if len(result.drifts) > 0 {
+				driftedInstances++
+				for _, detail := range result.drifts {
+					reasonCounts[detail.Reason]++
+				}
+				attributes := make([]string, 0, len(result.drifts))
+				for attr := range result.drifts {
+					attributes = append(attributes, attr)
+				}
+				sort.Strings(attributes)
+				logger.Warnf("Instance %s: Drift detected in %d attributes: %s",
+					result.instanceID, len(result.drifts), strings.Join(attributes, ", "))
+			} else {
+				logger.Infof("Instance %s: No drift detected", result.instanceID)
+			}
 		}
 
-		// Collect and process results
-		var hasErrors bool
+		var results []instanceResult
+		var interactiveReports, timedReports, historyReports []drift.Report
+		var planResults []instanceResult
 		for range instanceIDs {
 			result := <-resultsChan
+			if result.skipped != "" {
+				logger.Infof("Instance %s: Skipped, instance is %s", result.instanceID, result.skipped)
+				skippedInstances++
+				continue
+			}
+			if planPath != "" && result.err == nil {
+				planResults = append(planResults, result)
+			}
+			if concurrencyStats && result.err == nil {
+				timedReports = append(timedReports, result.report())
+			}
+			if sqlitePath != "" && result.err == nil {
+				historyReports = append(historyReports, result.report())
+			}
+			if interactive {
+				// Failed instances are browsable too, showing their error
+				interactiveReports = append(interactiveReports, result.report())
+			}
 			if result.err != nil {
 				logger.Errorf("Error processing instance %s: %v", result.instanceID, result.err)
 				hasErrors = true
 				continue
 			}
+			if interactive {
+				continue
+			}
 
-			// Output results for each instance
-			fmt.Printf("\nResults for instance %s:\n", result.instanceID)
-			output := output.FormatDriftResults(result.drifts, result.instanceID, outputFormat)
-			fmt.Println(output)
+			// Without grouping or sorting, output each instance as soon as
+			// it completes
+			if groupBy == "" && sortBy == "" {
+				handleResult(result)
+				continue
+			}
+			results = append(results, result)
+		}
 
-			if len(result.drifts) > 0 {
-				attributes := make([]string, 0, len(result.drifts))
-				for attr := range result.drifts {
-					attributes = append(attributes, attr)
+		if interactive {
+			globalSpinner.Stop()
+			sort.Slice(interactiveReports, func(i, j int) bool {
+				return reportLess(interactiveReports[i], interactiveReports[j], sortBy)
+			})
+			if err := browseReports(interactiveReports); err != nil {
+				logger.Errorf("Interactive browser failed: %v", err)
+				hasErrors = true
+			}
+		} else if groupBy != "" {
+			tagKey := strings.TrimPrefix(groupBy, "tag:")
+			for _, group := range groupResults(results, tagKey) {
+				if textOutput() {
+					emit("\n=== %s=%s: %d instances, %d with drift, %d drifted attributes ===\n",
+						tagKey, group.value, len(group.results), group.driftedInstances(), group.driftCount())
 				}
-				logger.Warnf("Instance %s: Drift detected in %d attributes: %s",
-					result.instanceID, len(result.drifts), strings.Join(attributes, ", "))
+				sortResults(group.results, sortBy)
+				for _, result := range group.results {
+					handleResult(result)
+				}
+				if tableOutput {
+					emitTable()
+				}
+			}
+		} else {
+			sortResults(results, sortBy)
+			for _, result := range results {
+				handleResult(result)
+			}
+			if tableOutput {
+				emitTable()
+			}
+		}
+
+		if planPath != "" {
+			reconciliation := formatReconciliation(reconcilePlan(planResults, planDrifts))
+			if textOutput() {
+				emit("\n%s\n", reconciliation)
 			} else {
-				logger.Infof("Instance %s: No drift detected", result.instanceID)
+				fmt.Fprintf(os.Stderr, "\n%s\n", reconciliation)
+			}
+		}
+
+		if sqlitePath != "" {
+			if err := writeHistory(cmd.Context(), sqlitePath, runStartedAt, historyReports); err != nil {
+				logger.Errorf("Failed to write drift history to %s: %v", sqlitePath, err)
+				hasErrors = true
+			}
+		}
+		if resultCache != nil {
+			if err := resultCache.Save(); err != nil {
+				logger.Errorf("Failed to save result cache: %v", err)
+			}
+		}
+		if outputFile != "" {
+			if err := os.WriteFile(outputFile, []byte(report.String()), 0644); err != nil {
+				logger.Errorf("Failed to write output file: %v", err)
+				hasErrors = true
+			}
+		}
+		if summaryOnly {
+			summary := fmt.Sprintf("aws-terror: %d/%d instances drifted", driftedInstances, len(instanceIDs)-skippedInstances)
+			if reasons := output.FormatReasonCounts(reasonCounts); reasons != "" {
+				summary += " (" + reasons + ")"
+			}
+			fmt.Println(summary)
+		}
+		// Timings and API call counts go to stderr so they don't mix with
+		// the report on stdout
+		if concurrencyStats {
+			fmt.Fprint(os.Stderr, output.FormatTimingSummary(timedReports, slowestInstances))
+		}
+		if !quiet {
+			fmt.Fprintln(os.Stderr, output.FormatAPICalls(metrics.AWSAPICallCounts()))
+		}
+		if pushgatewayURL != "" {
+			if err := metrics.Push(cmd.Context(), pushgatewayURL, pushgatewayJob); err != nil {
+				logger.Errorf("Failed to push metrics to %s: %v", pushgatewayURL, err)
 			}
 		}
 
@@ -156,9 +520,500 @@ to quickly create a Cobra application.`,
 	},
 }
 
+// parseStateAndConfig reads an instance from state and fills the attributes
+// missing or null in state (e.g. write-only or not yet applied attributes)
+// from the HCL config. State takes precedence; if the resource can't be found
+// in the HCL config, state is used alone and a warning is returned. It also
+// returns the attributes the HCL config sets, or nil without it.
+func parseStateAndConfig(state *terraform.StateIndex, configPath, instanceID string) (map[string]any, string, []string, []string, error) {
+	tfConfig, address, err := state.Lookup(instanceID)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	hclConfig, err := terraform.ParseModuleConfig(configPath, address)
+	if err != nil {
+		warning := fmt.Sprintf("Using state only, resource %s not found in HCL configuration: %v", address, err)
+		logger.Warnf("Instance %s: %s", instanceID, warning)
+		return tfConfig, address, nil, []string{warning}, nil
+	}
+	configured := configKeys(hclConfig)
+	// State already has the values of attributes unknown in HCL
+	terraform.TakeUnknownAttributes(hclConfig)
+
+	if filled := terraform.MergeConfig(tfConfig, hclConfig); len(filled) > 0 {
+		logger.Debugf("Instance %s: took %s from HCL configuration", instanceID, strings.Join(filled, ", "))
+	}
+	return tfConfig, address, configured, nil, nil
+}
+
+// instanceResult is the outcome of checking a single instance
+type instanceResult struct {
+	instanceID  string
+	drifts      map[string]drift.DriftDetail
+	tags        map[string]string
+	address     string
+	unchecked   []string
+	warnings    []string
+	accountID   string
+	arn         string
+	startedAt   time.Time
+	completedAt time.Time
+	timings     drift.Timings
+	// checked are the attributes compared for the instance
+	checked  []string
+	metadata *drift.Metadata
+	// skipped is the state of a terminated instance left out of a scan
+	skipped string
+	err     error
+}
+
+// topSeverity returns the result with only its highest-severity drift
+func (r instanceResult) topSeverity() instanceResult {
+	r.drifts = r.report().TopSeverity().DriftMap()
+	return r
+}
+
+// atLeastSeverity returns the drifts at or above a severity; an empty
+// severity keeps every drift
+func atLeastSeverity(drifts map[string]drift.DriftDetail, severity drift.Severity) map[string]drift.DriftDetail {
+	if severity == "" {
+		return drifts
+	}
+	notable := make(map[string]drift.DriftDetail, len(drifts))
+	for attr, detail := range drifts {
+		if detail.Severity.Rank() >= severity.Rank() {
+			notable[attr] = detail
+		}
+	}
+	return notable
+}
+
+// report converts the result into a drift report for the output formatters
+func (r instanceResult) report() drift.Report {
+	report := drift.NewReport(r.instanceID, r.drifts)
+	report.Unchecked = r.unchecked
+	report.Warnings = r.warnings
+	report.AccountID = r.accountID
+	report.ARN = r.arn
+	report.StartedAt = r.startedAt
+	report.CompletedAt = r.completedAt
+	if concurrencyStats {
+		timings := r.timings
+		report.Timings = &timings
+	}
+	report.Metadata = r.metadata
+	report.Err = r.err
+	return report
+}
+
+// checkInstance fetches one instance from AWS, finds it in the Terraform
+// state or HCL config and detects drift between the two. state is nil when
+// only HCL config is used. With skipTerminated, a terminated or
+// shutting-down instance is skipped rather than compared.
+func checkInstance(ctx context.Context, awsClient *aws.Client, instanceID string, state *terraform.StateIndex, configPath string, skipTerminated bool) instanceResult {
+	startedAt := time.Now()
+
+	// The AWS and Terraform sides are independent, so the Terraform parse
+	// overlaps the AWS calls. Whichever fails first cancels the other.
+	var (
+		awsConfig      map[string]any
+		tfConfig       map[string]any
+		address        string
+		configWarnings []string
+		configured     []string
+		timings        drift.Timings
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		logger.Infof("Fetching EC2 instance %s configuration from AWS...", instanceID)
+		var err error
+		awsConfig, err = awsClient.GetEC2InstanceConfig(gctx, instanceID)
+		timings.AWSFetch = time.Since(startedAt)
+		if err != nil {
+			return fmt.Errorf("failed to get EC2 instance config: %v", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		parseStart := time.Now()
+		var err error
+		switch {
+		case state != nil && configPath != "":
+			tfConfig, address, configured, configWarnings, err = parseStateAndConfig(state, configPath, instanceID)
+		case state != nil:
+			tfConfig, address, err = state.Lookup(instanceID)
+		default:
+			tfConfig, address, err = terraform.ParseHCLConfigWithAddress(configPath, instanceID)
+			configured = configKeys(tfConfig)
+		}
+		timings.StateParse = time.Since(parseStart)
+		if err != nil {
+			return fmt.Errorf("failed to parse Terraform configuration: %v", err)
+		}
+		return gctx.Err()
+	})
+	if err := g.Wait(); err != nil {
+		return instanceResult{instanceID: instanceID, startedAt: startedAt, err: err}
+	}
+	if instanceState := stringValue(awsConfig, "instance_state"); skipTerminated && aws.IsTerminated(instanceState) {
+		return instanceResult{instanceID: instanceID, startedAt: startedAt, skipped: instanceState}
+	}
+	// Metadata is reported, never compared
+	awsMetadata, _ := awsConfig[aws.MetadataKey].(aws.InstanceMetadata)
+	delete(awsConfig, aws.MetadataKey)
+	compareStart := time.Now()
+
+	// Neither side changed since the cached result was computed
+	fingerprint := resultFingerprint(state, configPath, awsConfig)
+	if cached, ok := cachedInstanceResult(instanceID, fingerprint); ok {
+		logger.Infof("Instance %s: Using cached result", instanceID)
+		timings.Compare = time.Since(compareStart)
+		recordDriftMetrics(startedAt, cached.Drifts)
+		return instanceResult{
+			instanceID:  instanceID,
+			drifts:      cached.Drifts,
+			tags:        instanceTags(awsConfig),
+			address:     cached.Address,
+			unchecked:   cached.Unchecked,
+			warnings:    cached.Warnings,
+			accountID:   awsMetadata.AccountID,
+			arn:         awsMetadata.ARN,
+			startedAt:   startedAt,
+			completedAt: time.Now(),
+			timings:     timings,
+			checked:     cached.Checked,
+			metadata:    instanceMetadata(awsConfig, awsMetadata),
+		}
+	}
+
+	// Warnings about partly read data are reported, not just logged, and
+	// neither bookkeeping key is compared
+	warnings, _ := awsConfig[aws.WarningsKey].([]string)
+	unchecked, _ := awsConfig[aws.UncheckedAttributesKey].([]string)
+	delete(awsConfig, aws.WarningsKey)
+	delete(awsConfig, aws.UncheckedAttributesKey)
+	warnings = append(warnings, configWarnings...)
+	// HCL attributes set from references can't be compared
+	unknown := terraform.TakeUnknownAttributes(tfConfig)
+	// Without state, blocks are compared only on the fields HCL sets
+	if state == nil {
+		terraform.TrimBlockFields(awsConfig, tfConfig)
+	}
+
+	// Resolved before instance_state is added below, which Terraform
+	// doesn't manage
+	if configuredAttrs != nil {
+		if names, ok := configuredAttrs.Lookup(address); ok {
+			configured = names
+		}
+	}
+	attributes := resolveAttributes(attributesToCheck, tfConfig, configured)
+	if configuredAttrs != nil {
+		var warning string
+		attributes, warning = managedAttributes(attributes, address)
+		if warning != "" {
+			logger.Warnf("Instance %s: %s", instanceID, warning)
+			warnings = append(warnings, warning)
+		}
+	}
+
+	if resolveAMINames {
+		drift.UseAMINames(awsConfig, tfConfig)
+	}
+
+	// Terraform doesn't manage power state, so instance_state is
+	// checked against the expected state instead
+	if expectState != "" {
+		tfConfig["instance_state"] = expectState
+	}
+
+	// Attributes AWS wouldn't let us read are reported as unchecked
+	if len(unchecked) > 0 && requireVolumes {
+		return instanceResult{instanceID: instanceID, startedAt: startedAt, err: fmt.Errorf("not permitted to read volume details (ec2:DescribeVolumes) and --require-volumes is set")}
+	}
+	if len(unknown) > 0 {
+		warning := fmt.Sprintf("Not comparing %s, whose values in the HCL configuration are only known to Terraform", strings.Join(unknown, ", "))
+		logger.Warnf("Instance %s: %s", instanceID, warning)
+		warnings = append(warnings, warning)
+	}
+
+	// Detect drift
+	drifts, err := drift.DetectDrift(ignoreRules.StripAttributes(awsConfig), ignoreRules.StripAttributes(tfConfig), attributes, detectOptions())
+	drift.RemoveUnchecked(drifts, unchecked)
+	drift.RemoveUnchecked(drifts, unknown)
+	drift.Redact(drifts, redactAttributes)
+	timings.Compare = time.Since(compareStart)
+	result := instanceResult{
+		instanceID:  instanceID,
+		drifts:      drifts,
+		tags:        instanceTags(awsConfig),
+		address:     address,
+		unchecked:   unchecked,
+		warnings:    warnings,
+		accountID:   awsMetadata.AccountID,
+		arn:         awsMetadata.ARN,
+		startedAt:   startedAt,
+		completedAt: time.Now(),
+		timings:     timings,
+		checked:     attributes,
+		metadata:    instanceMetadata(awsConfig, awsMetadata),
+		err:         err,
+	}
+	if err == nil {
+		recordDriftMetrics(startedAt, drifts)
+		cacheInstanceResult(fingerprint, result)
+	}
+	return result
+}
+
+// recordDriftMetrics records a completed instance check and its drift
+func recordDriftMetrics(startedAt time.Time, drifts map[string]drift.DriftDetail) {
+	metrics.RecordDriftCheck(time.Since(startedAt).Seconds())
+	for attr, detail := range drifts {
+		metrics.RecordDriftDetected(attr, string(detail.Reason))
+	}
+}
+
+// slowestInstances is how many instances the --concurrency-stats summary lists
+const slowestInstances = 5
+
+// sortOrders are the accepted --sort-by values
+var sortOrders = []string{"drift-count", "instance-id", "severity"}
+
+// sortResults orders results by a --sort-by value. Without one, results stay
+// in the order they were collected.
+func sortResults(results []instanceResult, sortBy string) {
+	if sortBy == "" {
+		return
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return reportLess(results[i].report(), results[j].report(), sortBy)
+	})
+}
+
+// reportLess orders reports for --sort-by: drift-count puts the most drifted
+// instances first, severity the instances with the highest severity drift
+// (then the most drifted). Ties, and instance-id, order by instance ID in
+// natural order.
+func reportLess(a, b drift.Report, sortBy string) bool {
+	switch sortBy {
+	case "severity":
+		if a.MaxSeverity() != b.MaxSeverity() {
+			return a.MaxSeverity().Rank() > b.MaxSeverity().Rank()
+		}
+		fallthrough
+	case "drift-count":
+		if len(a.Drifts) != len(b.Drifts) {
+			return len(a.Drifts) > len(b.Drifts)
+		}
+	}
+	return drift.NaturalLess(a.InstanceID, b.InstanceID)
+}
+
+// resultGroup is a set of results sharing the same value of the --group-by tag
+type resultGroup struct {
+	value   string
+	results []instanceResult
+}
+
+func (g resultGroup) driftedInstances() int {
+	count := 0
+	for _, result := range g.results {
+		if len(result.drifts) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func (g resultGroup) driftCount() int {
+	count := 0
+	for _, result := range g.results {
+		count += len(result.drifts)
+	}
+	return count
+}
+
+// groupResults groups results by the value of a tag, in natural order of tag
+// value. Instances without the tag are grouped under "(none)".
+func groupResults(results []instanceResult, tagKey string) []resultGroup {
+	byValue := make(map[string][]instanceResult)
+	for _, result := range results {
+		value, ok := result.tags[tagKey]
+		if !ok {
+			value = "(none)"
+		}
+		byValue[value] = append(byValue[value], result)
+	}
+
+	groups := make([]resultGroup, 0, len(byValue))
+	for value, members := range byValue {
+		sort.Slice(members, func(i, j int) bool {
+			return drift.NaturalLess(members[i].instanceID, members[j].instanceID)
+		})
+		groups = append(groups, resultGroup{value: value, results: members})
+	}
+	sort.Slice(groups, func(i, j int) bool { return drift.NaturalLess(groups[i].value, groups[j].value) })
+
+	return groups
+}
+
+// instanceMetadata describes the instance for --include-metadata, or
+// returns nil without it
+func instanceMetadata(awsConfig map[string]any, awsMetadata aws.InstanceMetadata) *drift.Metadata {
+	if !includeMetadata {
+		return nil
+	}
+	return &drift.Metadata{
+		AMIName:          stringValue(awsConfig, "ami_name"),
+		LaunchTime:       awsMetadata.LaunchTime,
+		State:            stringValue(awsConfig, "instance_state"),
+		AvailabilityZone: stringValue(awsConfig, "availability_zone"),
+	}
+}
+
+// instanceTags extracts the tags from an AWS instance config
+func instanceTags(awsConfig map[string]any) map[string]string {
+	tags, _ := awsConfig["tags"].(map[string]string)
+	return tags
+}
+
+// replaceSuggestion returns a terraform apply -replace command when an
+// attribute in --replace-attributes drifted
+func replaceSuggestion(result instanceResult) string {
+	attributes := drift.ReplacementAttributes(result.drifts, replaceAttributes)
+	if len(attributes) == 0 {
+		return ""
+	}
+	if result.address == "" {
+		logger.Warnf("Instance %s: cannot suggest a replacement without a resource address", result.instanceID)
+		return ""
+	}
+
+	// Quote addresses with instance keys for the shell
+	address := result.address
+	if strings.ContainsAny(address, `["`) {
+		address = "'" + address + "'"
+	}
+	return fmt.Sprintf("Suggested fix (%s drifted): terraform apply -replace=%s", strings.Join(attributes, ", "), address)
+}
+
+// textOutput reports whether --output is meant for reading rather than
+// parsing. Other formats (json, yaml, diff, wide) must hold nothing but
+// reports, so hints and summaries alongside them go to stderr.
+func textOutput() bool {
+	switch strings.ToLower(outputFormat) {
+	case "json", "yaml", "diff", "wide", "table":
+		return false
+	default:
+		return true
+	}
+}
+
+// stringValue returns a string attribute of an AWS instance config
+func stringValue(awsConfig map[string]any, key string) string {
+	value, _ := awsConfig[key].(string)
+	return value
+}
+
+// detectOptions configures drift detection from the drift flags
+func detectOptions() drift.Options {
+	opts := drift.Options{TreatEmptyAsAbsent: emptyAsAbsent, CompareTagsSubset: tagsSubset}
+	if ignoreDefaults {
+		opts.Defaults = maps.Clone(drift.AWSDefaults)
+		for attr, value := range awsDefaults {
+			opts.Defaults[attr] = value
+		}
+	}
+	return opts
+}
+
+// formatResults renders the drift for one instance according to the output flags
+func formatResults(result instanceResult) string {
+	if onlyDrifted {
+		return output.FormatDriftedAttributes(result.drifts, outputFormat)
+	}
+	return output.FormatReport(result.report(), outputFormat, output.Options{ContextLines: contextLines})
+}
+
+// filterInstances returns the instances in state whose resource address
+// matches one of the patterns. With ids, only those instances are
+// considered; without, every aws_instance in state is.
+func filterInstances(statePath string, ids, patterns []string, httpState terraform.HTTPStateConfig) ([]string, error) {
+	resources, err := terraform.StateResources(statePath, httpState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances in state: %w", err)
+	}
+	resources, err = terraform.FilterResources(resources, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		requested[id] = true
+	}
+
+	var filtered []string
+	for _, resource := range resources {
+		if len(ids) > 0 && !requested[resource.ID] {
+			continue
+		}
+		logger.Debugf("Resource filter matched %s (%s)", resource.Address, resource.ID)
+		filtered = append(filtered, resource.ID)
+	}
+	return filtered, nil
+}
+
 var (
 	instanceIDs       []string
-	maxConcurrency    int
+	resourceFilter    []string
+	redactAttributes  []string
+	concurrency       string
+	webhookURL        string
+	webhookTimeout    time.Duration
+	slackWebhookURL   string
+	slackMinSeverity  string
+	pushgatewayURL    string
+	pushgatewayJob    string
+	planPath          string
+	topSeverityOnly   bool
+	strictAttrs       bool
+	includeMetadata   bool
+	stateUsername     string
+	statePassword     string
+	stateTimeout      time.Duration
+	minStateSerial    int64
+	expectTFVersion   string
+	onlyDrifted       bool
+	contextLines      int
+	reportDir         string
+	expectState       string
+	groupBy           string
+	unmanaged         bool
+	unmanagedTags     map[string]string
+	sortBy            string
+	summaryOnly       bool
+	quiet             bool
+	interactive       bool
+	concurrencyStats  bool
+	outputFile        string
+	ignoreDefaults    bool
+	emptyAsAbsent     bool
+	tagsSubset        bool
+	includeTerminated bool
+	resultCachePath   string
+	sqlitePath        string
+	managedPlanPath   string
+	suggestReplace    bool
+	requireVolumes    bool
+	tfcAddress        string
+	tfcToken          string
+	tfcOrganization   string
+	tfcWorkspace      string
+	replaceAttributes []string
+	awsDefaults       map[string]string
 	defaultAttributes = []string{
 		"instance_type",
 		"ami",
@@ -173,13 +1028,59 @@ var (
 
 func init() {
 	rootCmd.AddCommand(driftCmd)
-	driftCmd.Flags().StringSliceVarP(&instanceIDs, "instances", "i", nil, "EC2 instance IDs to check (required, comma-separated)")
-	driftCmd.Flags().StringVarP(&tfStatePath, "state", "s", "", "Path to Terraform state file")
-	driftCmd.Flags().StringVarP(&tfConfigPath, "config", "c", "", "Path to Terraform HCL configuration directory")
-	driftCmd.Flags().StringSliceVarP(&attributesToCheck, "attributes", "a", defaultAttributes, "Attributes to check for drift (comma-separated)")
-	driftCmd.Flags().IntVarP(&maxConcurrency, "concurrency", "n", 5, "Maximum number of concurrent instance checks")
-	driftCmd.Flags().BoolP("simulate", "", false, "Enable simulation mode to compare two state files")
+	driftCmd.Flags().StringSliceVarP(&instanceIDs, "instances", "i", nil, "EC2 instance IDs to check (comma-separated; required unless --resource-filter is set; omit with --simulate to compare whole states)")
+	driftCmd.Flags().StringSliceVar(&resourceFilter, "resource-filter", nil, "Only check aws_instance resources in --state whose address matches these globs (e.g. 'aws_instance.web*')")
+	driftCmd.Flags().StringVarP(&tfStatePath, "state", "s", "", "Path or http(s) URL of Terraform state file, or - to read it from stdin; several comma-separated paths or globs are searched together")
+	driftCmd.Flags().StringVarP(&tfConfigPath, "config", "c", "", "Path to Terraform HCL configuration directory, or a git source (git::https://example.com/infra.git//dir?ref=main); with --state, fills attributes missing from state")
+	driftCmd.Flags().StringSliceVarP(&attributesToCheck, "attributes", "a", envStringSlice("AWS_TERROR_ATTRIBUTES", defaultAttributes), "Attributes to check for drift (comma-separated; from-config checks those set for each resource in Terraform) [env AWS_TERROR_ATTRIBUTES]")
+	driftCmd.Flags().BoolVar(&strictAttrs, "strict-attributes", false, "Fail instead of warning when --attributes names an unrecognized attribute")
+	driftCmd.Flags().StringSliceVar(&selectedPresets, "attributes-preset", nil, "Named attribute sets to check: basic, security or cost (comma-separated; combined with --attributes)")
+	driftCmd.Flags().StringVarP(&concurrency, "concurrency", "n", envString("AWS_TERROR_CONCURRENCY", "5"), "Maximum number of concurrent instance checks, or auto [env AWS_TERROR_CONCURRENCY]")
+	driftCmd.Flags().BoolVar(&concurrencyStats, "concurrency-stats", false, "Time each instance check (AWS fetch, state parse, compare) and print a summary with the slowest instances to stderr")
+	driftCmd.Flags().BoolP("simulate", "", false, "Enable simulation mode to compare two state files (every instance when --instances is omitted)")
 	driftCmd.Flags().StringP("target-state", "t", "", "Path to target Terraform state file for simulation mode")
-
-	driftCmd.MarkFlagRequired("instances")
+	driftCmd.Flags().String("target-config", "", "Path to target Terraform HCL configuration for simulation mode")
+	driftCmd.Flags().StringSliceVar(&redactAttributes, "redact", nil, "Attributes whose values are shown as *** in all output (e.g. user_data,tags.DbPassword)")
+	driftCmd.Flags().BoolVar(&onlyDrifted, "only-drifted", false, "Only output the names of drifted attributes (one per line, or a JSON array)")
+	driftCmd.Flags().IntVar(&contextLines, "context-lines", 0, "Unchanged map keys to show around each change in --output diff")
+	driftCmd.Flags().StringVar(&expectState, "expect-state", "running", "Expected instance state when instance_state is in --attributes")
+	driftCmd.Flags().BoolVar(&ignoreDefaults, "ignore-defaults", false, "Ignore attributes unset in Terraform whose AWS value is the AWS default (e.g. monitoring=false)")
+	driftCmd.Flags().BoolVar(&tagsSubset, "compare-tags-subset", false, "Only report tags Terraform sets that are missing or different in AWS, ignoring tags only AWS has")
+	driftCmd.Flags().BoolVar(&emptyAsAbsent, "treat-empty-as-absent", false, "Treat attributes that are empty (\"\", [] or {}) the same as unset when comparing")
+	driftCmd.Flags().StringToStringVar(&awsDefaults, "aws-default", nil, "Override or add AWS default values for --ignore-defaults (e.g. tenancy=default)")
+	driftCmd.Flags().BoolVar(&suggestReplace, "suggest-replace", false, "Print a terraform apply -replace command when an attribute that usually requires replacement drifted")
+	driftCmd.Flags().StringSliceVar(&replaceAttributes, "replace-attributes", drift.ReplaceAttributes, "Attributes that trigger --suggest-replace")
+	driftCmd.Flags().BoolVar(&requireVolumes, "require-volumes", false, "Fail instead of reporting block devices as unchecked when volumes can't be read")
+	driftCmd.Flags().BoolVar(&unmanaged, "detect-unmanaged", false, "List instances in the region that no aws_instance in --state manages, instead of checking drift")
+	driftCmd.Flags().StringToStringVar(&unmanagedTags, "unmanaged-tag", nil, "Only list unmanaged instances with these tag values (e.g. Environment=prod)")
+	driftCmd.Flags().StringVar(&sqlitePath, "sqlite", "", "SQLite database to record each run and its drifted attributes in, for querying drift history (created if missing)")
+	driftCmd.Flags().StringVar(&resultCachePath, "result-cache", "", "File to keep each instance's result in between runs; an instance whose state serial and AWS config are unchanged isn't compared again")
+	driftCmd.Flags().BoolVar(&includeTerminated, "include-terminated", false, "Check terminated and shutting-down instances when scanning state (--resource-filter without --instances) instead of skipping them; --detect-unmanaged always lists shutting-down instances")
+	driftCmd.Flags().StringVar(&groupBy, "group-by", "", "Group multi-instance output by a tag value (e.g. tag:Environment)")
+	driftCmd.Flags().StringVar(&sortBy, "sort-by", "", "Order multi-instance output by drift-count (most first), instance-id or severity (highest first)")
+	driftCmd.Flags().BoolVar(&topSeverityOnly, "top-severity-only", false, "Only print each instance's highest-severity drift; --output-file and --report-dir still get full results")
+	driftCmd.Flags().BoolVar(&includeMetadata, "include-metadata", false, "Add the instance's AMI name, launch time, state and availability zone to every report")
+	driftCmd.Flags().BoolVar(&summaryOnly, "summary-only", false, "Print a single summary line to stdout instead of per-instance results (for cron jobs)")
+	driftCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Only log errors")
+	driftCmd.Flags().BoolVar(&interactive, "interactive", false, "Browse the results in a terminal UI instead of printing them (requires a build with -tags tui)")
+	driftCmd.Flags().StringVar(&managedPlanPath, "managed-only", "", "Only compare the attributes each resource sets in its configuration, read from a plan from terraform show -json, skipping attributes the provider computes (e.g. arn, private_ip)")
+	driftCmd.Flags().StringVar(&planPath, "terraform-plan", "", "Compare the drift found with the resource_drift of a plan from terraform show -json, listing attributes only one of them found drifted")
+	driftCmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "File of attribute paths and instance IDs or addresses to exclude from drift, one glob per line (default .terror-ignore if present)")
+	driftCmd.Flags().StringVar(&outputFile, "output-file", "", "File to write the full per-instance results to")
+	driftCmd.Flags().StringVar(&reportDir, "report-dir", "", "Directory to write one report file per instance (<instance-id>.<ext>)")
+	driftCmd.Flags().StringVar(&webhookURL, "webhook", "", "URL to POST the JSON drift report to after each instance")
+	driftCmd.Flags().DurationVar(&webhookTimeout, "webhook-timeout", 10*time.Second, "Timeout for each webhook request")
+	driftCmd.Flags().StringVar(&stateUsername, "state-username", "", "Basic auth username for http(s) state (defaults to TF_HTTP_USERNAME)")
+	driftCmd.Flags().StringVar(&statePassword, "state-password", "", "Basic auth password for http(s) state (defaults to TF_HTTP_PASSWORD)")
+	driftCmd.Flags().DurationVar(&stateTimeout, "state-timeout", 30*time.Second, "Timeout for fetching http(s) and Terraform Cloud state")
+	driftCmd.Flags().StringVar(&tfcOrganization, "tfc-organization", envString("TFE_ORGANIZATION", ""), "Terraform Cloud organization of --tfc-workspace [env TFE_ORGANIZATION]")
+	driftCmd.Flags().StringVar(&tfcWorkspace, "tfc-workspace", "", "Read the current state of this Terraform Cloud workspace (token from --tfc-token)")
+	driftCmd.Flags().StringVar(&tfcAddress, "tfc-address", "", "Terraform Enterprise address (defaults to TFE_ADDRESS or https://app.terraform.io)")
+	driftCmd.Flags().StringVar(&tfcToken, "tfc-token", "", "Terraform Cloud API token for --tfc-workspace (defaults to TFE_TOKEN)")
+	driftCmd.Flags().Int64Var(&minStateSerial, "min-state-serial", 0, "Warn if the state file serial is lower than this value")
+	driftCmd.Flags().StringVar(&expectTFVersion, "expect-terraform-version", "", "Warn if the state file was written by a different Terraform version")
+	driftCmd.Flags().StringVar(&slackWebhookURL, "slack-webhook", "", "Slack incoming webhook URL to notify when drift is found")
+	driftCmd.Flags().StringVar(&slackMinSeverity, "slack-min-severity", "", "Only notify --slack-webhook of drift at or above this severity: low, medium or high")
+	driftCmd.Flags().StringVar(&pushgatewayURL, "pushgateway", "", "Prometheus Pushgateway URL to push drift and AWS API metrics to on completion")
+	driftCmd.Flags().StringVar(&pushgatewayJob, "pushgateway-job", "aws-terror", "Job label for metrics pushed to --pushgateway")
 }
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	explainConfig bool
+	// configFileFlags are the flags loadConfigFile set from loadedConfigFile
+	configFileFlags  = map[string]bool{}
+	loadedConfigFile string
+)
+
+// secretFlags have their values masked when the configuration is explained.
+// Webhook URLs carry their credentials in the path.
+var secretFlags = map[string]bool{
+	"state-password": true,
+	"tfc-token":      true,
+	"slack-webhook":  true,
+	"webhook":        true,
+	"pushgateway":    true,
+}
+
+// envFlagPattern finds the environment variable a flag's usage documents
+var envFlagPattern = regexp.MustCompile(`\[env ([A-Z0-9_]+)\]`)
+
+// configSetting is a flag's effective value and where it came from: flag,
+// file, env, preset or default
+type configSetting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// effectiveConfig returns the resolved value and source of each visible flag
+// of cmd, once the config file and presets have been applied
+func effectiveConfig(cmd *cobra.Command) []configSetting {
+	var settings []configSetting
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Name == "help" || f.Name == "explain-config" {
+			return
+		}
+
+		setting := configSetting{Name: f.Name, Value: maskSetting(f.Name, f.Value.String()), Source: settingSource(f)}
+		settings = append(settings, setting)
+	})
+	return settings
+}
+
+// maskSetting hides the value of a secret flag, and the credentials of any
+// URL in other values, such as state URLs: their userinfo and query string
+func maskSetting(name, value string) string {
+	if value == "" {
+		return value
+	}
+	if secretFlags[name] {
+		return "***"
+	}
+
+	items := strings.Split(value, ",")
+	for i, item := range items {
+		u, err := url.Parse(item)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			continue
+		}
+		hasUser := u.User != nil
+		u.User = nil
+		if u.RawQuery != "" {
+			u.RawQuery = "***"
+		}
+		items[i] = u.String()
+		if hasUser {
+			items[i] = strings.Replace(items[i], "://", "://***@", 1)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// settingSource reports where a flag's value came from. The config file
+// only sets flags not given on the command line, and environment variables
+// only provide defaults.
+func settingSource(f *pflag.Flag) string {
+	switch {
+	case configFileFlags[f.Name]:
+		return "file"
+	case f.Changed:
+		if f.Name == "attributes" && len(selectedPresets) > 0 {
+			return "flag+preset"
+		}
+		return "flag"
+	case f.Name == "attributes" && len(selectedPresets) > 0:
+		return "preset"
+	}
+	if key, ok := envFallbackFlags[f.Name]; ok {
+		return "env " + key
+	}
+	if match := envFlagPattern.FindStringSubmatch(f.Usage); match != nil {
+		if val, ok := os.LookupEnv(match[1]); ok && val != "" {
+			return "env " + match[1]
+		}
+	}
+	return "default"
+}
+
+// formatEffectiveConfig renders the settings as JSON, or as aligned
+// name, value and source columns after the config file in use
+func formatEffectiveConfig(settings []configSetting) string {
+	if strings.ToLower(outputFormat) == "json" {
+		jsonData, err := json.MarshalIndent(struct {
+			ConfigFile string          `json:"config_file,omitempty"`
+			Settings   []configSetting `json:"settings"`
+		}{loadedConfigFile, settings}, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("Error formatting JSON: %v", err)
+		}
+		return string(jsonData)
+	}
+
+	var sb strings.Builder
+	configPath := loadedConfigFile
+	if configPath == "" {
+		configPath = "none"
+	}
+	sb.WriteString(fmt.Sprintf("Config file: %s\n\n", configPath))
+
+	nameWidth, valueWidth := 0, 0
+	for _, setting := range settings {
+		nameWidth = max(nameWidth, len(setting.Name))
+		valueWidth = max(valueWidth, len(setting.Value))
+	}
+	for _, setting := range settings {
+		sb.WriteString(fmt.Sprintf("%-*s  %-*s  (%s)\n", nameWidth, setting.Name, valueWidth, setting.Value, setting.Source))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// explainAndExit prints the effective configuration of cmd and exits
+// without running it
+func explainAndExit(cmd *cobra.Command) {
+	if globalSpinner != nil {
+		globalSpinner.Stop()
+	}
+	fmt.Println(formatEffectiveConfig(effectiveConfig(cmd)))
+	os.Exit(0)
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/katungi/aws-terror/pkg/history"
+)
+
+// writeHistory records a run in the --sqlite database, creating it on first
+// use
+func writeHistory(ctx context.Context, path string, startedAt time.Time, reports []drift.Report) error {
+	db, err := history.Open(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	runID, err := db.WriteRun(ctx, startedAt, reports)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Recorded run %d with %d instances in %s", runID, len(reports), path)
+	return nil
+}
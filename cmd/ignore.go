@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/katungi/aws-terror/pkg/terraform"
+)
+
+// defaultIgnoreFile is loaded from the working directory when --ignore-file
+// is not given
+const defaultIgnoreFile = ".terror-ignore"
+
+var (
+	ignoreFile  string
+	ignoreRules drift.IgnoreRules
+)
+
+// loadIgnoreRules reads --ignore-file, or .terror-ignore if it exists
+func loadIgnoreRules() (drift.IgnoreRules, error) {
+	path := ignoreFile
+	if path == "" {
+		if _, err := os.Stat(defaultIgnoreFile); err != nil {
+			return drift.IgnoreRules{}, nil
+		}
+		path = defaultIgnoreFile
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return drift.IgnoreRules{}, fmt.Errorf("failed to open ignore file: %w", err)
+	}
+	defer file.Close()
+
+	rules, err := drift.ParseIgnoreRules(file)
+	if err != nil {
+		return drift.IgnoreRules{}, fmt.Errorf("invalid ignore file %s: %w", path, err)
+	}
+	logger.Debugf("Loaded %d attribute and %d instance patterns from %s", len(rules.Attributes), len(rules.Instances), path)
+	return rules, nil
+}
+
+// withoutIgnoredInstances drops the instances the ignore rules name by ID or,
+// when state is given, by resource address
+func withoutIgnoredInstances(instanceIDs []string, state *terraform.StateIndex) []string {
+	if len(ignoreRules.Instances) == 0 {
+		return instanceIDs
+	}
+
+	kept := make([]string, 0, len(instanceIDs))
+	for _, id := range instanceIDs {
+		var address string
+		if state != nil {
+			_, address, _ = state.Lookup(id)
+		}
+		if ignoreRules.IgnoresInstance(id, address) {
+			logger.Infof("Instance %s: ignored", id)
+			continue
+		}
+		kept = append(kept, id)
+	}
+	return kept
+}
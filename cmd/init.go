@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var initForce bool
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a commented .aws-terror.yaml template",
+	Long: `Write a .aws-terror.yaml template to the current directory listing every
+setting of the drift command with its default, commented out. Uncomment and
+edit the settings you need; flags given on the command line still take
+precedence over the file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(defaultConfigFile); err == nil && !initForce {
+			logger.Fatalf("%s already exists; use --force to overwrite it", defaultConfigFile)
+		}
+
+		if err := os.WriteFile(defaultConfigFile, []byte(configTemplate()), 0644); err != nil {
+			logger.Fatalf("Failed to write %s: %v", defaultConfigFile, err)
+		}
+		fmt.Printf("Wrote %s\n", defaultConfigFile)
+	},
+}
+
+// configTemplate lists the drift command's flags, including the global
+// ones, as commented YAML settings with their defaults
+func configTemplate() string {
+	var sb strings.Builder
+	sb.WriteString("# AWS-Terror configuration. Keys are flag names; uncomment to set.\n")
+	sb.WriteString("# Command line flags take precedence over this file.\n")
+
+	driftCmd.InitDefaultHelpFlag()
+	driftCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Name == "help" || f.Name == "config-file" {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("\n# %s\n", f.Usage))
+		sb.WriteString(fmt.Sprintf("# %s: %s\n", f.Name, templateValue(f)))
+	})
+	return sb.String()
+}
+
+// templateValue renders a flag default as YAML
+func templateValue(f *pflag.Flag) string {
+	switch f.Value.Type() {
+	case "stringSlice":
+		items := strings.Trim(f.DefValue, "[]")
+		if items == "" {
+			return "[]"
+		}
+		return "[" + strings.ReplaceAll(items, ",", ", ") + "]"
+	case "stringToString":
+		return "{}"
+	case "string":
+		return fmt.Sprintf("%q", f.DefValue)
+	default:
+		return f.DefValue
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing .aws-terror.yaml")
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/katungi/aws-terror/aws"
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/katungi/aws-terror/pkg/metrics"
+	"github.com/katungi/aws-terror/pkg/output"
+	"github.com/katungi/aws-terror/pkg/terraform"
+	"github.com/spf13/cobra"
+)
+
+// inventoryEntry is one account in an inventory file. Without instances,
+// every aws_instance in the state file is checked.
+type inventoryEntry struct {
+	Account   string   `json:"account"`
+	Profile   string   `json:"profile"`
+	Region    string   `json:"region"`
+	State     string   `json:"state"`
+	Instances []string `json:"instances,omitempty"`
+}
+
+// inventoryResult is the combined JSON report row for one instance
+type inventoryResult struct {
+	Account    string          `json:"account"`
+	Profile    string          `json:"profile"`
+	Region     string          `json:"region"`
+	InstanceID string          `json:"instance_id,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Drift      json.RawMessage `json:"drift,omitempty"`
+}
+
+var inventoryPath string
+
+var inventoryCmd = &cobra.Command{
+	Use:   "profile-inventory",
+	Short: "Detect drift across the accounts listed in an inventory file",
+	Long: `Detect drift across many AWS accounts in one run. The inventory file is a
+JSON array mapping each account to an AWS profile, region and state file:
+
+  [
+    {"account": "prod", "profile": "prod-admin", "region": "us-east-1", "state": "prod.tfstate"},
+    {"account": "staging", "profile": "staging", "region": "eu-west-1", "state": "https://example.com/staging.tfstate"}
+  ]
+
+Every aws_instance in each state file is checked, unless the entry lists
+"instances". --attributes, --expect-state and the ignore file apply to
+every entry.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := loadInventory(inventoryPath)
+		if err != nil {
+			globalSpinner.Error(err.Error())
+			logger.Fatal(err)
+		}
+		ignoreRules, err = loadIgnoreRules()
+		if err != nil {
+			globalSpinner.Error(err.Error())
+			logger.Fatal(err)
+		}
+
+		var hasErrors bool
+		var report []inventoryResult
+		totalInstances, driftedInstances := 0, 0
+		reasonCounts := make(map[drift.Reason]int)
+		jsonOutput := strings.ToLower(outputFormat) == "json"
+
+		for _, entry := range entries {
+			globalSpinner.UpdateMessage(fmt.Sprintf("Checking account %s", entry.Account))
+			results, err := runInventoryEntry(cmd, entry)
+			if err != nil {
+				logger.Errorf("Account %s: %v", entry.Account, err)
+				hasErrors = true
+				report = append(report, inventoryResult{Account: entry.Account, Profile: entry.Profile, Region: entry.Region, Error: err.Error()})
+				continue
+			}
+
+			drifted := 0
+			for _, result := range results {
+				if len(result.drifts) > 0 {
+					drifted++
+				}
+				for _, detail := range result.drifts {
+					reasonCounts[detail.Reason]++
+				}
+			}
+			totalInstances += len(results)
+			driftedInstances += drifted
+
+			if !jsonOutput {
+				fmt.Printf("\n=== %s (%s, %s): %d instances, %d with drift ===\n",
+					entry.Account, entry.Profile, entry.Region, len(results), drifted)
+			}
+			for _, result := range results {
+				row := inventoryResult{Account: entry.Account, Profile: entry.Profile, Region: entry.Region, InstanceID: result.instanceID}
+				if result.err != nil {
+					logger.Errorf("Account %s: error processing instance %s: %v", entry.Account, result.instanceID, result.err)
+					hasErrors = true
+					row.Error = result.err.Error()
+				} else if jsonOutput {
+					row.Drift = json.RawMessage(formatResults(result))
+				} else {
+					fmt.Printf("\nResults for instance %s:\n%s\n", result.instanceID, formatResults(result))
+				}
+				report = append(report, row)
+			}
+		}
+
+		if jsonOutput {
+			jsonData, err := json.MarshalIndent(struct {
+				SchemaVersion int               `json:"schema_version"`
+				Results       []inventoryResult `json:"results"`
+			}{output.SchemaVersion, report}, "", "  ")
+			if err != nil {
+				logger.Fatalf("Failed to format inventory report: %v", err)
+			}
+			fmt.Println(string(jsonData))
+		} else {
+			summary := fmt.Sprintf("aws-terror: %d/%d instances drifted across %d accounts", driftedInstances, totalInstances, len(entries))
+			if reasons := output.FormatReasonCounts(reasonCounts); reasons != "" {
+				summary += " (" + reasons + ")"
+			}
+			fmt.Printf("\n%s\n", summary)
+		}
+		fmt.Fprintln(os.Stderr, output.FormatAPICalls(metrics.AWSAPICallCounts()))
+
+		if hasErrors {
+			globalSpinner.Error("One or more accounts or instances failed to process")
+			logger.Fatal("One or more accounts or instances failed to process")
+		}
+		globalSpinner.Success("Inventory drift detection completed successfully")
+	},
+}
+
+// loadInventory reads and validates an inventory file
+func loadInventory(path string) ([]inventoryEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory file: %w", err)
+	}
+
+	var entries []inventoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory file: %w", err)
+	}
+
+	for i, entry := range entries {
+		if entry.Account == "" || entry.State == "" {
+			return nil, fmt.Errorf("inventory entry %d: account and state are required", i)
+		}
+	}
+	return entries, nil
+}
+
+// runInventoryEntry checks the instances of one inventory entry concurrently,
+// returning the results ordered by instance ID
+func runInventoryEntry(cmd *cobra.Command, entry inventoryEntry) ([]instanceResult, error) {
+	instanceIDs := entry.Instances
+	// Instances listed from state may have been terminated since
+	skipTerminated := len(instanceIDs) == 0 && !includeTerminated
+	if len(instanceIDs) == 0 {
+		var err error
+		instanceIDs, err = terraform.StateInstanceIDs(entry.State, terraform.DefaultHTTPState())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list instances in state: %w", err)
+		}
+	}
+
+	opts := driftClientOptions()
+	if entry.Profile != "" {
+		opts = append(opts, aws.WithProfile(entry.Profile))
+	}
+	awsClient, err := aws.NewClient(entry.Region, logger, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize AWS client: %w", err)
+	}
+
+	workers, err := workerCount(concurrency, len(instanceIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := terraform.BuildStateIndex(entry.State, workers, terraform.DefaultHTTPState())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	instanceIDs = withoutIgnoredInstances(instanceIDs, state)
+
+	resultsChan := make(chan instanceResult, len(instanceIDs))
+	workerPool := make(chan struct{}, workers)
+	for _, id := range instanceIDs {
+		workerPool <- struct{}{} // Acquire worker
+		go func(instanceID string) {
+			defer func() { <-workerPool }() // Release worker
+			resultsChan <- checkInstance(cmd.Context(), awsClient, instanceID, state, "", skipTerminated)
+		}(id)
+	}
+
+	results := make([]instanceResult, 0, len(instanceIDs))
+	for range instanceIDs {
+		result := <-resultsChan
+		if result.skipped != "" {
+			logger.Infof("Instance %s: Skipped, instance is %s", result.instanceID, result.skipped)
+			continue
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return drift.NaturalLess(results[i].instanceID, results[j].instanceID) })
+
+	return results, nil
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+	inventoryCmd.Flags().StringVarP(&inventoryPath, "file", "f", "", "Path to the inventory file (required)")
+	inventoryCmd.Flags().StringSliceVarP(&attributesToCheck, "attributes", "a", envStringSlice("AWS_TERROR_ATTRIBUTES", defaultAttributes), "Attributes to check for drift (comma-separated) [env AWS_TERROR_ATTRIBUTES]")
+	inventoryCmd.Flags().StringSliceVar(&selectedPresets, "attributes-preset", nil, "Named attribute sets to check: basic, security or cost (comma-separated; combined with --attributes)")
+	inventoryCmd.Flags().StringVarP(&concurrency, "concurrency", "n", envString("AWS_TERROR_CONCURRENCY", "5"), "Maximum number of concurrent instance checks per account, or auto [env AWS_TERROR_CONCURRENCY]")
+	inventoryCmd.Flags().StringSliceVar(&redactAttributes, "redact", nil, "Attributes whose values are shown as *** in all output (e.g. user_data,tags.DbPassword)")
+	inventoryCmd.Flags().StringVar(&expectState, "expect-state", "running", "Expected instance state when instance_state is in --attributes")
+	inventoryCmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "File of attribute paths and instance IDs or addresses to exclude from drift, one glob per line (default .terror-ignore if present)")
+	inventoryCmd.Flags().BoolVar(&includeTerminated, "include-terminated", false, "Check terminated and shutting-down instances listed from state instead of skipping them")
+
+	inventoryCmd.MarkFlagRequired("file")
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/katungi/aws-terror/aws"
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/katungi/aws-terror/pkg/terraform"
+)
+
+// configuredAttrs are the attributes each resource sets in configuration,
+// read from the --managed-only plan
+var configuredAttrs terraform.ConfiguredAttributes
+
+// managedAttributes keeps the attributes, or nested paths of attributes, that
+// the instance at address sets in configuration. instance_state and Elastic
+// IPs don't come from the aws_instance configuration, so they are kept. An
+// instance missing from the configuration keeps every attribute, with a
+// warning.
+func managedAttributes(attributes []string, address string) ([]string, string) {
+	configured, ok := configuredAttrs.Lookup(address)
+	if !ok {
+		return attributes, fmt.Sprintf("Comparing all attributes, resource %s not found in the --managed-only plan configuration", address)
+	}
+
+	var managed []string
+	for _, attr := range attributes {
+		root, _, _ := strings.Cut(attr, ".")
+		if slices.Contains(configured, root) || root == "instance_state" || root == aws.ElasticIPsAttribute {
+			managed = append(managed, attr)
+		}
+	}
+	return managed, ""
+}
+
+// planReconciliation is the outcome of comparing a run against a plan's
+// resource drift. NotChecked lists the instances Terraform found drifted
+// that weren't checked successfully.
+type planReconciliation struct {
+	Instances  []drift.PlanReconciliation `json:"instances"`
+	NotChecked []string                   `json:"not_checked,omitempty"`
+}
+
+// reconcilePlan compares each successfully checked instance with the
+// drift the plan has for it, matched by instance ID or resource address
+func reconcilePlan(results []instanceResult, planDrifts []terraform.PlanDrift) planReconciliation {
+	reconciliation := planReconciliation{Instances: []drift.PlanReconciliation{}}
+	matched := make(map[int]bool)
+	for _, result := range results {
+		var planAttributes []string
+		for i, planDrift := range planDrifts {
+			if planDrift.InstanceID == result.instanceID || (result.address != "" && planDrift.Address == result.address) {
+				planAttributes = planDrift.Attributes
+				matched[i] = true
+				break
+			}
+		}
+
+		instance := drift.ReconcilePlan(result.report(), result.checked, planAttributes)
+		instance.Address = result.address
+		reconciliation.Instances = append(reconciliation.Instances, instance)
+	}
+
+	for i, planDrift := range planDrifts {
+		if matched[i] {
+			continue
+		}
+		name := planDrift.InstanceID
+		if name == "" {
+			name = planDrift.Address
+		}
+		reconciliation.NotChecked = append(reconciliation.NotChecked, name)
+	}
+
+	sort.Slice(reconciliation.Instances, func(i, j int) bool {
+		return reconciliation.Instances[i].InstanceID < reconciliation.Instances[j].InstanceID
+	})
+	sort.Strings(reconciliation.NotChecked)
+	return reconciliation
+}
+
+// formatReconciliation renders a plan reconciliation as JSON, or as the
+// instances where aws-terror and Terraform disagree followed by a summary
+func formatReconciliation(reconciliation planReconciliation) string {
+	if strings.ToLower(outputFormat) == "json" {
+		jsonData, err := json.MarshalIndent(reconciliation, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("Error formatting JSON: %v", err)
+		}
+		return string(jsonData)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Terraform plan reconciliation:\n")
+	consistent := 0
+	for _, instance := range reconciliation.Instances {
+		if instance.Consistent() {
+			consistent++
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  %s", instance.InstanceID))
+		if instance.Address != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", instance.Address))
+		}
+		sb.WriteString("\n")
+		if len(instance.OnlyTerraform) > 0 {
+			sb.WriteString(fmt.Sprintf("    Only Terraform found drift in: %s\n", strings.Join(instance.OnlyTerraform, ", ")))
+		}
+		if len(instance.OnlyDetected) > 0 {
+			sb.WriteString(fmt.Sprintf("    Only aws-terror found drift in: %s\n", strings.Join(instance.OnlyDetected, ", ")))
+		}
+	}
+	if len(reconciliation.NotChecked) > 0 {
+		sb.WriteString(fmt.Sprintf("  Drifted in the plan but not checked: %s\n", strings.Join(reconciliation.NotChecked, ", ")))
+	}
+	sb.WriteString(fmt.Sprintf("aws-terror: %d/%d instances agree with the Terraform plan",
+		consistent, len(reconciliation.Instances)))
+	return sb.String()
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/katungi/aws-terror/aws"
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/spf13/cobra"
+)
+
+// attributePresets are named attribute sets for common audit goals
+var attributePresets = map[string][]string{
+	"basic": defaultAttributes,
+	"security": {
+		"metadata_options",
+		"vpc_security_group_ids",
+		"associate_public_ip_address",
+		"ebs_block_device.*.encrypted",
+		"root_block_device.*.encrypted",
+		"disable_api_termination",
+	},
+	"cost": {
+		"instance_type",
+		"ebs_block_device.*.volume_type",
+		"root_block_device.*.volume_type",
+		"tenancy",
+		"instance_lifecycle",
+		"instance_market_options",
+	},
+}
+
+var selectedPresets []string
+
+// presetNames returns the sorted names of the attribute presets
+func presetNames() []string {
+	names := make([]string, 0, len(attributePresets))
+	for name := range attributePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyAttributePresets expands --attributes-preset into attributesToCheck.
+// The presets replace the default attributes, or are added to the attributes
+// when they were set by --attributes, the config file or the environment.
+func applyAttributePresets(cmd *cobra.Command) error {
+	if len(selectedPresets) == 0 {
+		return nil
+	}
+
+	var attributes []string
+	if cmd.Flags().Changed("attributes") || !slices.Equal(attributesToCheck, defaultAttributes) {
+		attributes = append(attributes, attributesToCheck...)
+	}
+	for _, name := range selectedPresets {
+		preset, ok := attributePresets[name]
+		if !ok {
+			return fmt.Errorf("unknown attributes preset %q (available: %s)", name, strings.Join(presetNames(), ", "))
+		}
+		attributes = append(attributes, preset...)
+	}
+
+	seen := make(map[string]bool, len(attributes))
+	attributesToCheck = attributesToCheck[:0:0]
+	for _, attr := range attributes {
+		if !seen[attr] {
+			seen[attr] = true
+			attributesToCheck = append(attributesToCheck, attr)
+		}
+	}
+	return nil
+}
+
+// fromConfig in --attributes checks the attributes set in each resource's
+// Terraform configuration
+const fromConfig = "from-config"
+
+// configKeys returns the attributes a parsed HCL configuration sets
+func configKeys(config map[string]any) []string {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// clientAttributes returns the attributes the AWS client must fetch. With
+// from-config the checked attributes are only known per resource, so every
+// attribute is fetched.
+func clientAttributes(attributes []string) []string {
+	if slices.Contains(attributes, fromConfig) {
+		return []string{"*"}
+	}
+	return attributes
+}
+
+// resolveAttributes replaces from-config in attributes with the supported
+// attributes the resource's configuration sets: configured, when the HCL
+// configuration or a --managed-only plan gives them, or else those with a
+// value in tfConfig. State records every attribute of the schema whether or
+// not the configuration sets it, so with state alone from-config means every
+// attribute that isn't null or empty.
+func resolveAttributes(attributes []string, tfConfig map[string]any, configured []string) []string {
+	if !slices.Contains(attributes, fromConfig) {
+		return attributes
+	}
+
+	if configured == nil {
+		for name, value := range tfConfig {
+			if !drift.IsEmptyValue(value) {
+				configured = append(configured, name)
+			}
+		}
+	}
+	configured = slices.DeleteFunc(slices.Clone(configured), func(name string) bool {
+		return !aws.IsSupportedAttribute(name)
+	})
+	sort.Strings(configured)
+
+	var resolved []string
+	seen := make(map[string]bool)
+	for _, attr := range attributes {
+		expanded := []string{attr}
+		if attr == fromConfig {
+			expanded = configured
+		}
+		for _, name := range expanded {
+			if !seen[name] {
+				seen[name] = true
+				resolved = append(resolved, name)
+			}
+		}
+	}
+	return resolved
+}
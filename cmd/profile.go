@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+)
+
+var (
+	cpuProfile string
+	memProfile string
+
+	cpuProfileFile *os.File
+	stopOnce       sync.Once
+)
+
+// startProfiling starts the CPU profile requested with --cpuprofile. Profiles
+// are also written when a fatal log entry exits the process.
+func startProfiling() error {
+	if cpuProfile == "" && memProfile == "" {
+		return nil
+	}
+
+	if cpuProfile != "" {
+		file, err := os.Create(cpuProfile)
+		if err != nil {
+			return fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		cpuProfileFile = file
+	}
+
+	logger.ExitFunc = func(code int) {
+		stopProfiling()
+		os.Exit(code)
+	}
+	return nil
+}
+
+// stopProfiling finishes the CPU profile and writes the heap profile
+// requested with --memprofile. Only the first call has an effect.
+func stopProfiling() {
+	stopOnce.Do(func() {
+		if cpuProfileFile != nil {
+			pprof.StopCPUProfile()
+			cpuProfileFile.Close()
+		}
+
+		if memProfile == "" {
+			return
+		}
+		file, err := os.Create(memProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create memory profile: %v\n", err)
+			return
+		}
+		defer file.Close()
+		// Collect garbage first so the profile shows live memory
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(file); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write memory profile: %v\n", err)
+		}
+	})
+}
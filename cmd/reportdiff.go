@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/katungi/aws-terror/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var baselineReports []string
+
+var reportDiffCmd = &cobra.Command{
+	Use:   "report-diff --baseline-drift-report BASELINE.json CURRENT.json...",
+	Short: "Show new, fixed and unchanged drift between two saved JSON reports",
+	Long: `Compare drift reports saved with --output json from two runs, and show which
+drifts are new since the baseline, which were fixed and which are unchanged:
+
+  aws-terror report-diff --baseline-drift-report reports/2025-03-01 reports/2025-03-02
+
+Each argument is a report file, such as an --output-file, or a directory
+of them, such as a --report-dir. A file may hold one report, an array of
+reports or several reports one after another. Instances with drift in the
+baseline that are missing from the current reports are listed as not
+checked.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(baselineReports) == 0 {
+			globalSpinner.Error("--baseline-drift-report is required")
+			logger.Fatal("--baseline-drift-report is required")
+		}
+
+		baseline, err := readJSONReports(baselineReports)
+		if err != nil {
+			globalSpinner.Error(err.Error())
+			logger.Fatal(err)
+		}
+		current, err := readJSONReports(args)
+		if err != nil {
+			globalSpinner.Error(err.Error())
+			logger.Fatal(err)
+		}
+
+		fmt.Println(formatDelta(drift.CompareReports(baseline, current)))
+		globalSpinner.Success("Report comparison completed successfully")
+	},
+}
+
+// readJSONReports reads the reports in each of paths. A directory, such as
+// a --report-dir, stands for the .json files in it.
+func readJSONReports(paths []string) ([]drift.Report, error) {
+	var files []string
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			matches, err := filepath.Glob(filepath.Join(path, "*.json"))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+			continue
+		}
+		files = append(files, path)
+	}
+
+	var reports []drift.Report
+	for _, path := range files {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open report: %w", err)
+		}
+		parsed, err := output.ParseJSONReports(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		reports = append(reports, parsed...)
+	}
+	return reports, nil
+}
+
+// formatDelta renders a delta as JSON, or as one line per drift grouped by
+// new, fixed and unchanged, followed by a summary
+func formatDelta(delta drift.Delta) string {
+	if strings.ToLower(outputFormat) == "json" {
+		jsonData, err := json.MarshalIndent(struct {
+			SchemaVersion int `json:"schema_version"`
+			drift.Delta
+		}{output.SchemaVersion, delta}, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("Error formatting JSON: %v", err)
+		}
+		return string(jsonData)
+	}
+
+	var sb strings.Builder
+	sections := []struct {
+		title  string
+		marker string
+		drifts []drift.InstanceDrift
+	}{
+		{"New drift", "+", delta.New},
+		{"Fixed", "-", delta.Fixed},
+		{"Unchanged", "=", delta.Unchanged},
+	}
+	for _, section := range sections {
+		if len(section.drifts) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s (%d):\n", section.title, len(section.drifts)))
+		for _, entry := range section.drifts {
+			sb.WriteString(fmt.Sprintf("  %s %-20s %-40s %s, %s\n", section.marker,
+				entry.InstanceID, entry.Attribute, entry.Severity, entry.Reason))
+		}
+		sb.WriteString("\n")
+	}
+	if len(delta.NotChecked) > 0 {
+		sb.WriteString(fmt.Sprintf("Not in current reports: %s\n\n", strings.Join(delta.NotChecked, ", ")))
+	}
+	sb.WriteString(fmt.Sprintf("aws-terror: %d new, %d fixed, %d unchanged",
+		len(delta.New), len(delta.Fixed), len(delta.Unchanged)))
+	return sb.String()
+}
+
+func init() {
+	rootCmd.AddCommand(reportDiffCmd)
+	reportDiffCmd.Flags().StringSliceVar(&baselineReports, "baseline-drift-report", nil, "JSON drift reports of the earlier run to compare against (comma-separated)")
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/katungi/aws-terror/aws"
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/katungi/aws-terror/pkg/output"
+	"github.com/katungi/aws-terror/pkg/terraform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	describersPath string
+	resourceType   string
+	resourceIDs    []string
+)
+
+var resourceDriftCmd = &cobra.Command{
+	Use:   "resource-drift",
+	Short: "Detect drift for a resource type described in a describers file",
+	Long: `Detect drift for resources AWS-Terror doesn't support natively, using a
+describers file that maps each resource type to the EC2 Describe call that
+reads it and the paths of its attributes in the response. See "Other
+Resource Types" in the README for the format and an aws_eip example.
+
+Every resource of --type in state is compared, or only those given with
+--ids.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if tfStatePath == "" {
+			globalSpinner.Error("Terraform state file is required")
+			logger.Fatal("Terraform state file is required")
+		}
+		describer, err := loadDescriber(describersPath, resourceType)
+		if err != nil {
+			globalSpinner.Error(err.Error())
+			logger.Fatal(err)
+		}
+
+		resources, err := terraform.StateResourcesOfType(tfStatePath, resourceType, terraform.DefaultHTTPState())
+		if err != nil {
+			globalSpinner.Error(fmt.Sprintf("Failed to read Terraform state: %v", err))
+			logger.Fatalf("Failed to read Terraform state: %v", err)
+		}
+		if len(resourceIDs) > 0 {
+			resources = slices.DeleteFunc(resources, func(resource terraform.StateResource) bool {
+				return !slices.Contains(resourceIDs, resource.ID)
+			})
+		}
+		if len(resources) == 0 {
+			globalSpinner.Error(fmt.Sprintf("No %s resources found in state", resourceType))
+			logger.Fatalf("No %s resources found in state", resourceType)
+		}
+
+		awsClient, err := aws.NewClient(awsRegion, logger, awsClientOptions()...)
+		if err != nil {
+			globalSpinner.Error(fmt.Sprintf("Failed to initialize AWS client: %v", err))
+			logger.Fatalf("Failed to initialize AWS client: %v", err)
+		}
+
+		// AWS leaves out unset fields that Terraform stores as empty values
+		opts := drift.Options{TreatEmptyAsAbsent: true}
+		attributes := describer.AttributeNames()
+		var hasErrors bool
+		drifted := 0
+		for _, resource := range resources {
+			globalSpinner.UpdateMessage(fmt.Sprintf("Checking %s", resource.Address))
+			awsConfig, err := awsClient.DescribeResource(cmd.Context(), describer, resource.ID)
+			if err != nil {
+				logger.Errorf("Error processing %s: %v", resource.Address, err)
+				hasErrors = true
+				continue
+			}
+
+			drifts, err := drift.DetectDrift(awsConfig, resource.Attributes, attributes, opts)
+			if err != nil {
+				logger.Errorf("Error processing %s: %v", resource.Address, err)
+				hasErrors = true
+				continue
+			}
+			if len(drifts) > 0 {
+				drifted++
+			}
+			fmt.Printf("\nResults for %s (%s):\n%s\n", resource.Address, resource.ID,
+				output.FormatReport(drift.NewReport(resource.ID, drifts), outputFormat, output.Options{}))
+		}
+		fmt.Printf("\naws-terror: %d/%d %s resources drifted\n", drifted, len(resources), resourceType)
+
+		if hasErrors {
+			globalSpinner.Error("One or more resources failed to process")
+			logger.Fatal("One or more resources failed to process")
+		}
+		globalSpinner.Success("Resource drift detection completed successfully")
+	},
+}
+
+// loadDescriber reads the describer for a resource type from a describers
+// file
+func loadDescriber(path, resourceType string) (aws.Describer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return aws.Describer{}, fmt.Errorf("failed to open describers file: %w", err)
+	}
+	defer file.Close()
+
+	describers, err := aws.ParseDescribers(file)
+	if err != nil {
+		return aws.Describer{}, fmt.Errorf("invalid describers file %s: %w", path, err)
+	}
+	describer, ok := describers[resourceType]
+	if !ok {
+		types := make([]string, 0, len(describers))
+		for name := range describers {
+			types = append(types, name)
+		}
+		slices.Sort(types)
+		return aws.Describer{}, fmt.Errorf("%s has no describer for %s (it describes %s)", path, resourceType, strings.Join(types, ", "))
+	}
+	return describer, nil
+}
+
+func init() {
+	rootCmd.AddCommand(resourceDriftCmd)
+	resourceDriftCmd.Flags().StringVar(&describersPath, "describers", "", "YAML file mapping resource types to the EC2 Describe call and attribute paths that read them (required)")
+	resourceDriftCmd.Flags().StringVar(&resourceType, "type", "", "Terraform resource type to check, e.g. aws_eip (required)")
+	resourceDriftCmd.Flags().StringSliceVar(&resourceIDs, "ids", nil, "Only check the resources with these IDs (comma-separated)")
+	resourceDriftCmd.Flags().StringVarP(&tfStatePath, "state", "s", "", "Path of the Terraform state file; several comma-separated paths or globs are searched together")
+
+	resourceDriftCmd.MarkFlagRequired("describers")
+	resourceDriftCmd.MarkFlagRequired("type")
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/katungi/aws-terror/pkg/cache"
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/katungi/aws-terror/pkg/terraform"
+)
+
+// resultCacheVersion is bumped when the comparison changes in a way that
+// makes results cached by an earlier version wrong
+const resultCacheVersion = 1
+
+// resultCache holds the results of earlier runs when --result-cache is set
+var resultCache *cache.Store
+
+// cachedResult is the part of an instance's result kept in --result-cache.
+// The rest comes from the AWS config fetched on every run.
+type cachedResult struct {
+	Fingerprint string                       `json:"fingerprint"`
+	Address     string                       `json:"address,omitempty"`
+	Drifts      map[string]drift.DriftDetail `json:"drifts"`
+	Unchecked   []string                     `json:"unchecked,omitempty"`
+	Warnings    []string                     `json:"warnings,omitempty"`
+	Checked     []string                     `json:"checked,omitempty"`
+}
+
+// resultFingerprint hashes what an instance's result is computed from: the
+// version of the state, the instance as AWS reports it and the settings that
+// change the comparison. It is empty when the result can't be cached, i.e.
+// without --result-cache or when the Terraform side has no version, as with
+// HCL config.
+func resultFingerprint(state *terraform.StateIndex, configPath string, awsConfig map[string]any) string {
+	if resultCache == nil || state == nil || configPath != "" || state.Version() == "" {
+		return ""
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"version":      resultCacheVersion,
+		"state":        state.Version(),
+		"aws":          awsConfig,
+		"attributes":   attributesToCheck,
+		"expect_state": expectState,
+		"ignore":       ignoreRules,
+		"defaults":     ignoreDefaults,
+		"aws_defaults": awsDefaults,
+		"empty":        emptyAsAbsent,
+		"tags_subset":  tagsSubset,
+		"redact":       redactAttributes,
+		"ami_names":    resolveAMINames,
+		"require_vols": requireVolumes,
+		"configured":   configuredAttrs,
+	})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedInstanceResult returns the cached result of an instance if it was
+// computed from the same inputs. A result computed from other inputs, i.e.
+// after the state or the instance changed, is not used.
+func cachedInstanceResult(instanceID, fingerprint string) (cachedResult, bool) {
+	if fingerprint == "" {
+		return cachedResult{}, false
+	}
+	var cached cachedResult
+	if !resultCache.Get(instanceID, &cached) || cached.Fingerprint != fingerprint {
+		return cachedResult{}, false
+	}
+	return cached, true
+}
+
+// cacheInstanceResult keeps an instance's result for the next run, replacing
+// the result computed from earlier inputs
+func cacheInstanceResult(fingerprint string, result instanceResult) {
+	if fingerprint == "" {
+		return
+	}
+	err := resultCache.Set(result.instanceID, cachedResult{
+		Fingerprint: fingerprint,
+		Address:     result.address,
+		Drifts:      result.drifts,
+		Unchecked:   result.unchecked,
+		Warnings:    result.warnings,
+		Checked:     result.checked,
+	})
+	if err != nil {
+		logger.Warnf("Instance %s: Not caching result: %v", result.instanceID, err)
+	}
+}
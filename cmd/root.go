@@ -2,10 +2,15 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/katungi/aws-terror/aws"
 	"github.com/katungi/aws-terror/pkg/progress"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -18,6 +23,15 @@ var (
 	tfStatePath       string
 	tfConfigPath      string
 	outputFormat      string
+	skipCredCheck     bool
+	resolveAMINames   bool
+	volumeConcurrency int
+	noVolumeLookup    bool
+	noRetry           bool
+	breakerThreshold  int
+	breakerCooldown   time.Duration
+	awsConfigFile     string
+	awsCredsFile      string
 	attributesToCheck []string
 	logger            *logrus.Logger
 	globalSpinner     *progress.Spinner
@@ -29,6 +43,24 @@ var rootCmd = &cobra.Command{
 	Long: `AWS-Terror is a CLI tool that helps you detect drift between your
 AWS resources and your Terraform state files. This helps ensure your
 infrastructure is in the expected state defined in your IaC.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadConfigFile(cmd); err != nil {
+			return err
+		}
+		if err := applyEnvFallbacks(cmd); err != nil {
+			return err
+		}
+		if err := applyAttributePresets(cmd); err != nil {
+			return err
+		}
+		if explainConfig {
+			explainAndExit(cmd)
+		}
+		return startProfiling()
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		stopProfiling()
+	},
 }
 
 func Execute() error {
@@ -80,10 +112,26 @@ func ExecuteContext(ctx context.Context) error {
 func init() {
 	// Initialize logger
 	logger = logrus.New()
+	logger.AddHook(spinnerHook{})
 
+	rootCmd.PersistentFlags().StringVar(&configFile, "config-file", "", "Config file (defaults to .aws-terror.yaml in the working or home directory)")
+	rootCmd.PersistentFlags().BoolVar(&explainConfig, "explain-config", false, "Print every setting with its effective value and where it came from (flag, file, env, preset or default), then exit")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
 	rootCmd.PersistentFlags().StringVar(&awsRegion, "region", "", "AWS region (defaults to AWS_REGION env var)")
-	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", "text", "Output format (text, json, yaml)")
+	rootCmd.PersistentFlags().BoolVar(&skipCredCheck, "skip-credential-check", false, "Skip verifying AWS credentials with sts:GetCallerIdentity before running")
+	rootCmd.PersistentFlags().BoolVar(&resolveAMINames, "resolve-ami-names", false, "Resolve AMI IDs to names and compare by name when Terraform pins an AMI by name")
+	rootCmd.PersistentFlags().IntVar(&volumeConcurrency, "volume-concurrency", 4, "Maximum concurrent volume lookups per instance")
+	rootCmd.PersistentFlags().BoolVar(&noVolumeLookup, "no-volume-lookup", false, "Skip DescribeVolumes; block devices only include what DescribeInstances returns")
+	rootCmd.PersistentFlags().IntVar(&breakerThreshold, "breaker-threshold", aws.DefaultBreakerThreshold, "Consecutive AWS throttling errors that make calls fail fast until --breaker-cooldown has passed (0 disables)")
+	rootCmd.PersistentFlags().DurationVar(&breakerCooldown, "breaker-cooldown", aws.DefaultBreakerCooldown, "How long calls fail fast once --breaker-threshold is reached")
+	rootCmd.PersistentFlags().StringVar(&awsConfigFile, "aws-config-file", "", "AWS shared config file to use instead of ~/.aws/config")
+	rootCmd.PersistentFlags().StringVar(&awsCredsFile, "aws-credentials-file", "", "AWS shared credentials file to use instead of ~/.aws/credentials")
+	rootCmd.PersistentFlags().BoolVar(&noRetry, "no-retry", false, "Make a single attempt at each AWS call instead of retrying with backoff, so failures surface immediately")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", envString("AWS_TERROR_OUTPUT", "text"), "Output format (text, json, yaml, diff, wide) [env AWS_TERROR_OUTPUT]")
+	rootCmd.PersistentFlags().StringVar(&cpuProfile, "cpuprofile", "", "Write a pprof CPU profile of the run to this file")
+	rootCmd.PersistentFlags().StringVar(&memProfile, "memprofile", "", "Write a pprof heap profile at the end of the run to this file")
+	rootCmd.PersistentFlags().MarkHidden("cpuprofile")
+	rootCmd.PersistentFlags().MarkHidden("memprofile")
 
 	// Set log level from flag
 	level, err := logrus.ParseLevel(logLevel)
@@ -104,3 +152,107 @@ func init() {
 		}
 	}
 }
+
+// spinnerHook keeps log entries off the spinner's line, and stops the
+// spinner before a fatal entry exits the process so the terminal is left
+// with its cursor restored
+type spinnerHook struct{}
+
+func (spinnerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (spinnerHook) Fire(entry *logrus.Entry) error {
+	if globalSpinner == nil {
+		return nil
+	}
+	if entry.Level <= logrus.FatalLevel {
+		globalSpinner.Stop()
+	} else {
+		globalSpinner.Clear()
+	}
+	return nil
+}
+
+// envString returns the value of the environment variable key, or def if unset
+func envString(key, def string) string {
+	if val, ok := os.LookupEnv(key); ok && val != "" {
+		return val
+	}
+	return def
+}
+
+// envStringSlice reads a comma-separated list from the environment variable key
+func envStringSlice(key string, def []string) []string {
+	val, ok := os.LookupEnv(key)
+	if !ok || val == "" {
+		return def
+	}
+
+	var result []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// envInt reads an integer from the environment variable key, or def if unset
+// or invalid. It runs during flag registration, before the logger exists.
+func envInt(key string, def int) int {
+	val, ok := os.LookupEnv(key)
+	if !ok || val == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ignoring invalid %s=%q: %v\n", key, val, err)
+		return def
+	}
+	return n
+}
+
+// awsClientOptions returns the AWS client options selected by global flags
+func awsClientOptions() []aws.Option {
+	var opts []aws.Option
+	if !skipCredCheck {
+		opts = append(opts, aws.WithCredentialPreflight())
+	}
+	// --include-metadata reports the AMI name, without comparing by it
+	if resolveAMINames || includeMetadata {
+		opts = append(opts, aws.WithAMINameResolution())
+	}
+	if noVolumeLookup {
+		opts = append(opts, aws.WithoutVolumeLookup())
+	}
+	if noRetry {
+		opts = append(opts, aws.WithoutRetry())
+	}
+	opts = append(opts, aws.WithCircuitBreaker(breakerThreshold, breakerCooldown))
+	if awsConfigFile != "" {
+		opts = append(opts, aws.WithSharedConfigFile(awsConfigFile))
+	}
+	if awsCredsFile != "" {
+		opts = append(opts, aws.WithSharedCredentialsFile(awsCredsFile))
+	}
+	opts = append(opts, aws.WithVolumeConcurrency(volumeConcurrency))
+	return opts
+}
+
+// driftClientOptions extends awsClientOptions with the options implied by
+// --attributes: attributes that need DescribeInstanceAttribute or
+// DescribeAddresses are only fetched when checked, and volume lookups are
+// skipped when no block device attribute is checked
+func driftClientOptions() []aws.Option {
+	attributes := clientAttributes(attributesToCheck)
+	opts := append(awsClientOptions(), aws.WithInstanceAttributes(attributes...))
+	if !noVolumeLookup && !aws.NeedsVolumeLookup(attributes) {
+		opts = append(opts, aws.WithoutVolumeLookup())
+	}
+	if aws.NeedsElasticIPLookup(attributes) {
+		opts = append(opts, aws.WithElasticIPLookup())
+	}
+	return opts
+}
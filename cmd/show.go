@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/katungi/aws-terror/aws"
+	"github.com/katungi/aws-terror/pkg/output"
+	"github.com/katungi/aws-terror/pkg/terraform"
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the configuration fetched from AWS for an instance",
+	Long: `Fetch an EC2 instance from AWS and print the attributes AWS-Terror maps
+from it, without comparing against Terraform. Useful for understanding why
+an attribute does or does not report drift.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ids, err := cmd.Flags().GetStringSlice("instances")
+		if err != nil || len(ids) == 0 {
+			globalSpinner.Error("Instance ID is required")
+			logger.Fatal("Instance ID is required")
+		}
+
+		awsClient, err := aws.NewClient(awsRegion, logger, awsClientOptions()...)
+		if err != nil {
+			globalSpinner.Error(fmt.Sprintf("Failed to initialize AWS client: %v", err))
+			logger.Fatalf("Failed to initialize AWS client: %v", err)
+		}
+
+		for _, id := range ids {
+			awsConfig, err := awsClient.GetEC2InstanceConfig(cmd.Context(), id)
+			if err != nil {
+				globalSpinner.Error(fmt.Sprintf("Failed to get EC2 instance config: %v", err))
+				logger.Fatalf("Failed to get EC2 instance config: %v", err)
+			}
+
+			fmt.Println(output.FormatConfig(awsConfig, id, outputFormat))
+		}
+	},
+}
+
+var showStateCmd = &cobra.Command{
+	Use:   "show-state",
+	Short: "Show the attributes parsed from Terraform for an instance",
+	Long: `Parse a Terraform state file or HCL configuration and print the
+attributes extracted for an instance, without contacting AWS. Useful for
+diagnosing parsing issues on the Terraform side of a comparison.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ids, err := cmd.Flags().GetStringSlice("instances")
+		if err != nil || len(ids) == 0 {
+			globalSpinner.Error("Instance ID is required")
+			logger.Fatal("Instance ID is required")
+		}
+
+		statePath, _ := cmd.Flags().GetString("state")
+		configPath, _ := cmd.Flags().GetString("config")
+		if statePath == "" && configPath == "" {
+			globalSpinner.Error("Either Terraform state file or HCL configuration path is required")
+			logger.Fatal("Either Terraform state file or HCL configuration path is required")
+		}
+		defer checkoutConfig(&configPath)()
+
+		for _, id := range ids {
+			var tfConfig map[string]any
+			if statePath != "" {
+				tfConfig, err = terraform.ParseStateFile(statePath, id, terraform.DefaultHTTPState())
+			} else {
+				tfConfig, err = terraform.ParseHCLConfig(configPath, id)
+			}
+			if err != nil {
+				globalSpinner.Error(fmt.Sprintf("Failed to parse Terraform configuration: %v", err))
+				logger.Fatalf("Failed to parse Terraform configuration: %v", err)
+			}
+
+			fmt.Println(output.FormatConfig(tfConfig, id, outputFormat))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+	showCmd.Flags().StringSliceP("instances", "i", nil, "EC2 instance IDs to show (required, comma-separated)")
+
+	showCmd.MarkFlagRequired("instances")
+
+	rootCmd.AddCommand(showStateCmd)
+	showStateCmd.Flags().StringSliceP("instances", "i", nil, "EC2 instance IDs to show (required, comma-separated)")
+	showStateCmd.Flags().StringP("state", "s", "", "Path or http(s) URL of Terraform state file")
+	showStateCmd.Flags().StringP("config", "c", "", "Path to Terraform HCL configuration directory, or a git source (git::https://example.com/infra.git//dir?ref=main)")
+
+	showStateCmd.MarkFlagRequired("instances")
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/katungi/aws-terror/pkg/output"
+	"github.com/katungi/aws-terror/pkg/terraform"
+)
+
+// stateDiffResult is the JSON report row for one resource of a whole-state
+// comparison
+type stateDiffResult struct {
+	Address    string          `json:"address"`
+	InstanceID string          `json:"instance_id,omitempty"`
+	Status     string          `json:"status"`
+	Drift      json.RawMessage `json:"drift,omitempty"`
+}
+
+// filterStateDiff keeps the resources whose address matches --resource-filter
+func filterStateDiff(diffs []terraform.ResourceDiff, patterns []string) ([]terraform.ResourceDiff, error) {
+	var filtered []terraform.ResourceDiff
+	for _, diff := range diffs {
+		matched, err := terraform.MatchAddress(diff.Address, patterns)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			filtered = append(filtered, diff)
+		}
+	}
+	return filtered, nil
+}
+
+// formatStateDiff renders a whole-state comparison as one combined report
+func formatStateDiff(diffs []terraform.ResourceDiff) string {
+	if strings.ToLower(outputFormat) == "json" {
+		rows := make([]stateDiffResult, 0, len(diffs))
+		for _, diff := range diffs {
+			row := stateDiffResult{Address: diff.Address, InstanceID: diff.ID, Status: string(diff.Status)}
+			if diff.Status == terraform.ResourceChanged {
+				row.Drift = json.RawMessage(output.FormatReport(drift.NewReport(diff.ID, diff.Drifts), "json", output.Options{}))
+			}
+			rows = append(rows, row)
+		}
+		jsonData, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("Error formatting JSON: %v", err)
+		}
+		return string(jsonData)
+	}
+
+	// Other formats hold nothing but reports, so headers are left out and
+	// notes about added or removed resources go to stderr
+	var sb strings.Builder
+	var notes io.Writer = &sb
+	if !textOutput() {
+		notes = os.Stderr
+	}
+	counts := make(map[terraform.ResourceStatus]int)
+	for _, diff := range diffs {
+		counts[diff.Status]++
+		switch diff.Status {
+		case terraform.ResourceChanged:
+			report := output.FormatReport(drift.NewReport(diff.ID, diff.Drifts), outputFormat, output.Options{ContextLines: contextLines})
+			if textOutput() {
+				sb.WriteString(fmt.Sprintf("\nResults for %s (%s):\n", diff.Address, diff.ID))
+			}
+			sb.WriteString(report + "\n")
+		case terraform.ResourceAdded:
+			fmt.Fprintf(notes, "\n%s (%s): only in target state\n", diff.Address, diff.ID)
+		case terraform.ResourceRemoved:
+			fmt.Fprintf(notes, "\n%s (%s): only in source state\n", diff.Address, diff.ID)
+		}
+	}
+	fmt.Fprintf(notes, "\naws-terror: %d changed, %d added, %d removed\n",
+		counts[terraform.ResourceChanged], counts[terraform.ResourceAdded], counts[terraform.ResourceRemoved])
+	return sb.String()
+}
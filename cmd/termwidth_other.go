@@ -0,0 +1,8 @@
+//go:build !unix
+
+package cmd
+
+// terminalWidth returns the COLUMNS width, or 0 when unset
+func terminalWidth() int {
+	return envInt("COLUMNS", 0)
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalWidth returns the width of the terminal on stdout, honoring
+// COLUMNS, or 0 when stdout is not a terminal
+func terminalWidth() int {
+	if width := envInt("COLUMNS", 0); width > 0 {
+		return width
+	}
+
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+	return int(ws.Col)
+}
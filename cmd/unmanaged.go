@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/katungi/aws-terror/aws"
+	"github.com/katungi/aws-terror/pkg/output"
+	"github.com/katungi/aws-terror/pkg/terraform"
+	"github.com/spf13/cobra"
+)
+
+// detectUnmanaged lists the instances in the region that no aws_instance in
+// the state manages, e.g. instances created by hand in the console
+func detectUnmanaged(cmd *cobra.Command, statePath string, httpState terraform.HTTPStateConfig) {
+	if statePath == "" {
+		globalSpinner.Error("--detect-unmanaged requires --state or --tfc-workspace")
+		logger.Fatal("--detect-unmanaged requires --state or --tfc-workspace")
+	}
+
+	globalSpinner.UpdateMessage("Reading managed instances from state")
+	resources, err := terraform.StateResources(statePath, httpState)
+	if err != nil {
+		globalSpinner.Error(fmt.Sprintf("Failed to read state: %v", err))
+		logger.Fatalf("Failed to read state: %v", err)
+	}
+	managedIDs := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		managedIDs = append(managedIDs, resource.ID)
+	}
+
+	globalSpinner.UpdateMessage("Initializing AWS client")
+	awsClient, err := aws.NewClient(awsRegion, logger, driftClientOptions()...)
+	if err != nil {
+		globalSpinner.Error(fmt.Sprintf("Failed to initialize AWS client: %v", err))
+		logger.Fatalf("Failed to initialize AWS client: %v", err)
+	}
+
+	globalSpinner.UpdateMessage("Listing instances in AWS")
+	instances, err := awsClient.ListInstances(cmd.Context(), unmanagedTags)
+	if err != nil {
+		globalSpinner.Error(fmt.Sprintf("Failed to list instances: %v", err))
+		logger.Fatalf("Failed to list instances: %v", err)
+	}
+
+	// Unmanaged instances have no address, so only instance ID patterns apply
+	unmanaged := slices.DeleteFunc(aws.UnmanagedInstances(instances, managedIDs), func(instance aws.InstanceSummary) bool {
+		return ignoreRules.IgnoresInstance(instance.ID, "")
+	})
+	fmt.Println(formatUnmanaged(unmanaged, len(instances)))
+	globalSpinner.Success("Unmanaged instance detection completed successfully")
+}
+
+// formatUnmanaged renders the unmanaged instances as a JSON object listing
+// them under instances, or as one line per instance followed by a summary
+func formatUnmanaged(unmanaged []aws.InstanceSummary, total int) string {
+	if strings.ToLower(outputFormat) == "json" {
+		if unmanaged == nil {
+			unmanaged = []aws.InstanceSummary{}
+		}
+		jsonData, err := json.MarshalIndent(struct {
+			SchemaVersion int                   `json:"schema_version"`
+			Instances     []aws.InstanceSummary `json:"instances"`
+		}{output.SchemaVersion, unmanaged}, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("Error formatting JSON: %v", err)
+		}
+		return string(jsonData)
+	}
+
+	var sb strings.Builder
+	for _, instance := range unmanaged {
+		sb.WriteString(fmt.Sprintf("%-20s %-14s %s  %s\n", instance.ID, instance.State,
+			instance.LaunchTime.UTC().Format("2006-01-02T15:04:05Z"), formatTags(instance.Tags)))
+	}
+	sb.WriteString(fmt.Sprintf("aws-terror: %d/%d instances not managed by Terraform", len(unmanaged), total))
+	return sb.String()
+}
+
+// formatTags renders tags as sorted key=value pairs
+func formatTags(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
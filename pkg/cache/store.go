@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store is a cache of JSON-encoded values kept in a file, so values survive
+// between runs. Entries don't expire; callers replace an entry when the
+// value it was computed from changes.
+type Store struct {
+	path    string
+	entries map[string]json.RawMessage
+	mutex   sync.Mutex
+	dirty   bool
+}
+
+// OpenStore reads the store at path. A missing file is an empty store.
+func OpenStore(path string) (*Store, error) {
+	store := &Store{path: path, entries: make(map[string]json.RawMessage)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse cache %s: %w", path, err)
+	}
+	return store, nil
+}
+
+// Get decodes the value stored under key into value, reporting whether there
+// was one. An entry that no longer decodes into value counts as missing.
+func (s *Store) Get(key string, value any) bool {
+	s.mutex.Lock()
+	data, exists := s.entries[key]
+	s.mutex.Unlock()
+
+	if !exists {
+		return false
+	}
+	return json.Unmarshal(data, value) == nil
+}
+
+// Set stores value under key, replacing any previous value
+func (s *Store) Set(key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries[key] = data
+	s.dirty = true
+	return nil
+}
+
+// Save writes the store back to its file if anything was set. The file is
+// replaced in one step, so a failed save leaves the previous file intact.
+func (s *Store) Save() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
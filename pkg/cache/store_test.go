@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore() on a missing file error = %v", err)
+	}
+	if err := store.Set("i-123", map[string]string{"fingerprint": "abc"}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Values survive reopening the store
+	reopened, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	var value map[string]string
+	if !reopened.Get("i-123", &value) || value["fingerprint"] != "abc" {
+		t.Errorf("Get() = %v, want the saved value", value)
+	}
+	if reopened.Get("i-456", &value) {
+		t.Error("expected i-456 to be missing")
+	}
+
+	// A value of another type counts as missing
+	var wrongType []string
+	if reopened.Get("i-123", &wrongType) {
+		t.Error("expected a value that doesn't decode to be missing")
+	}
+}
+
+func TestStore_SaveWithoutChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Save() without changes wrote %s", path)
+	}
+}
+
+func TestOpenStore_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatalf("Failed to write cache: %v", err)
+	}
+
+	if _, err := OpenStore(path); err == nil {
+		t.Error("expected an error for a corrupt cache file")
+	}
+}
@@ -0,0 +1,17 @@
+package drift
+
+import "strings"
+
+// UseAMINames makes the ami attribute compare by name when the Terraform
+// side pins the AMI by name instead of by ID. The AWS config must carry the
+// resolved name as ami_name; otherwise the configs are left unchanged.
+func UseAMINames(awsConfig, tfConfig map[string]any) {
+	tfAMI, ok := tfConfig["ami"].(string)
+	if !ok || tfAMI == "" || strings.HasPrefix(tfAMI, "ami-") {
+		return
+	}
+
+	if name, ok := awsConfig["ami_name"].(string); ok {
+		awsConfig["ami"] = name
+	}
+}
@@ -0,0 +1,26 @@
+package drift
+
+// EqualFunc reports whether an attribute's AWS and Terraform values are equal
+type EqualFunc func(awsValue, tfValue any) bool
+
+// compareAttribute compares the values of attr with its comparator in
+// Options.Comparators, falling back to compareTags for tags and
+// compareValuesAt for everything else
+func (opts Options) compareAttribute(attr string, awsValue, tfValue any) bool {
+	if equal, ok := opts.lookupComparator(attr); ok {
+		return equal(awsValue, tfValue)
+	}
+	if topLevelAttribute(attr) == "tags" {
+		return opts.compareTags(awsValue, tfValue)
+	}
+	return opts.compareValuesAt(attr, awsValue, tfValue)
+}
+
+// lookupComparator returns the comparator for an attribute path
+func (opts Options) lookupComparator(attr string) (EqualFunc, bool) {
+	if attr == "" {
+		return nil, false
+	}
+	equal, ok := opts.Comparators[wildcardIndices(attr)]
+	return equal, ok
+}
@@ -0,0 +1,73 @@
+package drift
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// equalFold compares string values ignoring case
+func equalFold(awsValue, tfValue any) bool {
+	return strings.EqualFold(awsValue.(string), tfValue.(string))
+}
+
+func TestComparators(t *testing.T) {
+	// Compare instance profiles by name, whether given as an ARN or a name
+	profileName := func(v any) string {
+		s, _ := v.(string)
+		return s[strings.LastIndex(s, "/")+1:]
+	}
+	opts := Options{Comparators: map[string]EqualFunc{
+		"iam_instance_profile": func(awsValue, tfValue any) bool {
+			return profileName(awsValue) == profileName(tfValue)
+		},
+	}}
+
+	awsConfig := map[string]any{"iam_instance_profile": "arn:aws:iam::123456789012:instance-profile/web", "ami": "ami-1"}
+	tfConfig := map[string]any{"iam_instance_profile": "web", "ami": "ami-2"}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"iam_instance_profile", "ami"}, opts)
+	assert.NoError(t, err)
+	assert.NotContains(t, drifts, "iam_instance_profile")
+	assert.Contains(t, drifts, "ami", "other attributes use the default comparison")
+
+	drifts, err = DetectDrift(awsConfig, tfConfig, []string{"iam_instance_profile"}, Options{})
+	assert.NoError(t, err)
+	assert.Contains(t, drifts, "iam_instance_profile")
+}
+
+func TestComparators_ListIndex(t *testing.T) {
+	opts := Options{Comparators: map[string]EqualFunc{"ebs_block_device.*.volume_type": equalFold}}
+
+	awsConfig := map[string]any{"ebs_block_device": []any{map[string]any{"volume_type": "GP3"}}}
+	tfConfig := map[string]any{"ebs_block_device": []any{map[string]any{"volume_type": "gp3"}}}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"ebs_block_device.*.volume_type"}, opts)
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+}
+
+func TestComparators_NestedPath(t *testing.T) {
+	opts := Options{Comparators: map[string]EqualFunc{"ebs_block_device.*.volume_type": equalFold}}
+
+	awsConfig := map[string]any{"ebs_block_device": []any{
+		map[string]any{"device_name": "/dev/sdb", "volume_type": "GP3"},
+		map[string]any{"device_name": "/dev/sdc", "volume_type": "IO2"},
+	}}
+	tfConfig := map[string]any{"ebs_block_device": []any{
+		map[string]any{"device_name": "/dev/sdc", "volume_type": "io2"},
+		map[string]any{"device_name": "/dev/sdb", "volume_type": "gp3"},
+	}}
+
+	// The whole list is compared, and its blocks' volume_type fields still
+	// use the comparator for their path
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"ebs_block_device"}, opts)
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	tfConfig["ebs_block_device"].([]any)[0].(map[string]any)["volume_type"] = "gp2"
+	drifts, err = DetectDrift(awsConfig, tfConfig, []string{"ebs_block_device"}, opts)
+	assert.NoError(t, err)
+	assert.Contains(t, drifts, "ebs_block_device")
+}
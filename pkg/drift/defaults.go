@@ -0,0 +1,37 @@
+package drift
+
+import "fmt"
+
+// AWSDefaults holds the values AWS assigns to aws_instance attributes that
+// Terraform configs usually leave unset. Pass it, or a copy with overrides,
+// as Options.Defaults; it is not modified.
+var AWSDefaults = map[string]any{
+	"monitoring":                           false,
+	"ebs_optimized":                        false,
+	"source_dest_check":                    true,
+	"disable_api_termination":              false,
+	"instance_initiated_shutdown_behavior": "stop",
+	"tenancy":                              "default",
+	"hibernation":                          false,
+}
+
+// removeDefaults removes drifts for attributes that are set in AWS but not in
+// Terraform when the AWS value equals the default in defaults
+func removeDefaults(drifts map[string]DriftDetail, defaults map[string]any) {
+	for attr, detail := range drifts {
+		if detail.InTerraform || !detail.InAWS {
+			continue
+		}
+
+		def, ok := defaults[attr]
+		if !ok {
+			continue
+		}
+
+		// Compare as strings so overrides given on the command line match
+		// typed values
+		if fmt.Sprintf("%v", normalizeValue(detail.AWSValue)) == fmt.Sprintf("%v", normalizeValue(def)) {
+			delete(drifts, attr)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package drift
+
+import "sort"
+
+// InstanceDrift is a drifted attribute of one instance
+type InstanceDrift struct {
+	InstanceID string `json:"instance_id"`
+	DriftDetail
+}
+
+// Delta is the change in drift between two runs. Instances in the baseline
+// that weren't checked successfully in the current run are listed in
+// NotChecked, since whether their drift was fixed is unknown.
+type Delta struct {
+	New        []InstanceDrift `json:"new"`
+	Fixed      []InstanceDrift `json:"fixed"`
+	Unchanged  []InstanceDrift `json:"unchanged"`
+	NotChecked []string        `json:"not_checked,omitempty"`
+}
+
+// CompareReports compares the reports of a baseline run against those of
+// the current run. A drift is unchanged when the same attribute of the same
+// instance drifted in both runs, even if the values differ; unchanged drifts
+// carry the current values.
+func CompareReports(baseline, current []Report) Delta {
+	delta := Delta{
+		New:       []InstanceDrift{},
+		Fixed:     []InstanceDrift{},
+		Unchanged: []InstanceDrift{},
+	}
+
+	checked := make(map[string]map[string]DriftDetail)
+	for _, report := range current {
+		if report.Err == nil {
+			checked[report.InstanceID] = report.DriftMap()
+		}
+	}
+
+	baselineDrifts := make(map[string]map[string]DriftDetail)
+	for _, report := range baseline {
+		if report.Err != nil {
+			continue
+		}
+		baselineDrifts[report.InstanceID] = report.DriftMap()
+
+		currentDrifts, ok := checked[report.InstanceID]
+		if !ok {
+			if report.HasDrift() {
+				delta.NotChecked = append(delta.NotChecked, report.InstanceID)
+			}
+			continue
+		}
+		for _, detail := range report.Drifts {
+			if _, still := currentDrifts[detail.Attribute]; !still {
+				delta.Fixed = append(delta.Fixed, InstanceDrift{InstanceID: report.InstanceID, DriftDetail: detail})
+			}
+		}
+	}
+
+	for _, report := range current {
+		if report.Err != nil {
+			continue
+		}
+		previous := baselineDrifts[report.InstanceID]
+		for _, detail := range report.Drifts {
+			entry := InstanceDrift{InstanceID: report.InstanceID, DriftDetail: detail}
+			if _, before := previous[detail.Attribute]; before {
+				delta.Unchanged = append(delta.Unchanged, entry)
+			} else {
+				delta.New = append(delta.New, entry)
+			}
+		}
+	}
+
+	for _, drifts := range [][]InstanceDrift{delta.New, delta.Fixed, delta.Unchanged} {
+		sort.Slice(drifts, func(i, j int) bool {
+			if drifts[i].InstanceID != drifts[j].InstanceID {
+				return drifts[i].InstanceID < drifts[j].InstanceID
+			}
+			return drifts[i].Attribute < drifts[j].Attribute
+		})
+	}
+	sort.Strings(delta.NotChecked)
+	return delta
+}
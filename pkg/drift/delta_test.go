@@ -0,0 +1,54 @@
+package drift
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareReports(t *testing.T) {
+	baseline := []Report{
+		NewReport("i-1", map[string]DriftDetail{
+			"ami":  {AWSValue: "ami-1", TerraformValue: "ami-2"},
+			"tags": {},
+		}),
+		NewReport("i-2", map[string]DriftDetail{"ami": {}}),
+		NewReport("i-3", nil),
+	}
+	failed := NewReport("i-2", nil)
+	failed.Err = errors.New("instance not found")
+	current := []Report{
+		NewReport("i-1", map[string]DriftDetail{
+			"ami":     {AWSValue: "ami-1", TerraformValue: "ami-3"},
+			"tenancy": {},
+		}),
+		failed,
+		NewReport("i-4", map[string]DriftDetail{"tags": {}}),
+	}
+
+	delta := CompareReports(baseline, current)
+
+	attrs := func(drifts []InstanceDrift) []string {
+		var names []string
+		for _, d := range drifts {
+			names = append(names, d.InstanceID+":"+d.Attribute)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"i-1:tenancy", "i-4:tags"}, attrs(delta.New))
+	assert.Equal(t, []string{"i-1:tags"}, attrs(delta.Fixed))
+	assert.Equal(t, []string{"i-1:ami"}, attrs(delta.Unchanged))
+	assert.Equal(t, "ami-3", delta.Unchanged[0].TerraformValue, "unchanged drifts carry the current values")
+	assert.Equal(t, []string{"i-2"}, delta.NotChecked)
+}
+
+func TestCompareReports_Empty(t *testing.T) {
+	delta := CompareReports(nil, nil)
+
+	assert.NotNil(t, delta.New)
+	assert.Empty(t, delta.New)
+	assert.Empty(t, delta.Fixed)
+	assert.Empty(t, delta.Unchanged)
+	assert.Empty(t, delta.NotChecked)
+}
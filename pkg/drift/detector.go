@@ -2,21 +2,30 @@ package drift
 
 import (
 	"fmt"
+	"math/big"
+	"path"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-// DetectDrift compares AWS and Terraform configurations and returns differences
-func DetectDrift(awsConfig, tfConfig map[string]any, attributesToCheck []string) (map[string]DriftDetail, error) {
+// DetectDrift compares AWS and Terraform configurations and returns differences.
+// Attributes may be glob patterns (e.g. "tags.*") which are expanded against
+// the keys present in either configuration.
+func DetectDrift(awsConfig, tfConfig map[string]any, attributesToCheck []string, opts Options) (map[string]DriftDetail, error) {
 	drifts := make(map[string]DriftDetail)
 
-	for _, attr := range attributesToCheck {
+	for _, attr := range expandAttributes(attributesToCheck, awsConfig, tfConfig) {
 		awsValue, awsExists := getNestedValue(awsConfig, attr)
 		tfValue, tfExists := getNestedValue(tfConfig, attr)
 
 		if !awsExists && !tfExists {
 			continue
 		}
+		if opts.TreatEmptyAsAbsent && emptyOrAbsent(awsValue, awsExists) && emptyOrAbsent(tfValue, tfExists) {
+			continue
+		}
 
 		if !awsExists {
 			drifts[attr] = DriftDetail{
@@ -24,40 +33,142 @@ func DetectDrift(awsConfig, tfConfig map[string]any, attributesToCheck []string)
 				InAWS:          false,
 				InTerraform:    true,
 				TerraformValue: tfValue,
+				Severity:       lookupSeverity(attr),
+				Reason:         ReasonMissingInAWS,
 			}
 			continue
 		}
 
 		if !tfExists {
+			if opts.CompareTagsSubset && topLevelAttribute(attr) == "tags" {
+				continue
+			}
 			drifts[attr] = DriftDetail{
 				Attribute:   attr,
 				InAWS:       true,
 				InTerraform: false,
 				AWSValue:    awsValue,
+				Severity:    lookupSeverity(attr),
+				Reason:      ReasonMissingInTerraform,
 			}
 			continue
 		}
 
-		if !compareValues(awsValue, tfValue) {
-			drifts[attr] = DriftDetail{
+		if !opts.compareAttribute(attr, awsValue, tfValue) {
+			detail := DriftDetail{
 				Attribute:      attr,
 				InAWS:          true,
 				InTerraform:    true,
 				AWSValue:       awsValue,
 				TerraformValue: tfValue,
+				Severity:       opts.classifySeverity(attr, awsValue, tfValue),
+				Reason:         mismatchReason(awsValue, tfValue),
 			}
+			if attr == "tags" {
+				detail.TagDiff = opts.diffTags(awsValue, tfValue)
+			}
+			drifts[attr] = detail
 		}
 	}
 
+	if opts.Defaults != nil {
+		removeDefaults(drifts, opts.Defaults)
+	}
 	return drifts, nil
 }
 
+// Options configures how DetectDrift compares configurations
+type Options struct {
+	// Defaults are the AWS default values of attributes, such as
+	// AWSDefaults. An attribute set in AWS but not in Terraform is not drift
+	// while its AWS value is the default. Nil reports every such attribute.
+	Defaults map[string]any
+	// TreatEmptyAsAbsent considers an attribute that is null, an empty
+	// string or an empty list or map the same as one that isn't set, so e.g.
+	// tags = {} in Terraform doesn't drift from an instance without tags
+	TreatEmptyAsAbsent bool
+	// CompareTagsSubset makes tags drift only when a tag Terraform sets is
+	// missing or has a different value in AWS. Tags only AWS has, such as
+	// those added by operators or other tools, are ignored.
+	CompareTagsSubset bool
+	// Comparators compare the attributes at their paths instead of the
+	// default comparison, e.g. to normalize ARNs or resolve security group
+	// names. In a path, "*" matches a list index (e.g.
+	// "ebs_block_device.*.volume_type").
+	Comparators map[string]EqualFunc
+}
+
 type DriftDetail struct {
 	Attribute      string
 	InAWS          bool
 	InTerraform    bool
 	AWSValue       any
 	TerraformValue any
+	Severity       Severity
+	Reason         Reason
+	// TagDiff breaks down drift in the tags map
+	TagDiff *TagDiff `json:",omitempty"`
+}
+
+// Reason is a machine-readable code for why an attribute drifted
+type Reason string
+
+const (
+	ReasonValueMismatch      Reason = "value_mismatch"
+	ReasonMissingInAWS       Reason = "missing_in_aws"
+	ReasonMissingInTerraform Reason = "missing_in_terraform"
+	ReasonTypeMismatch       Reason = "type_mismatch"
+)
+
+// mismatchReason distinguishes values of different kinds (e.g. a string
+// compared against a list) from values that merely differ
+func mismatchReason(awsValue, tfValue any) Reason {
+	if valueKind(awsValue) != valueKind(tfValue) {
+		return ReasonTypeMismatch
+	}
+	return ReasonValueMismatch
+}
+
+func valueKind(v any) string {
+	v = normalizeValue(derefPointer(v))
+	if v == nil {
+		return "null"
+	}
+
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Map:
+		return "map"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64, reflect.Uint8, reflect.Uint16:
+		return "number"
+	default:
+		return reflect.TypeOf(v).String()
+	}
+}
+
+// derefPointer follows pointers such as the *int32 fields of AWS SDK types,
+// returning nil for a nil pointer. *big.Float is kept for normalizeValue.
+func derefPointer(v any) any {
+	if _, ok := v.(*big.Float); ok {
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	return rv.Interface()
 }
 
 func getNestedValue(data map[string]any, path string) (any, bool) {
@@ -65,37 +176,114 @@ func getNestedValue(data map[string]any, path string) (any, bool) {
 		return nil, false
 	}
 
-	parts := strings.Split(path, ".")
-	current := data
-
-	for i := 0; i < len(parts)-1; i++ {
-		part := parts[i]
-		val, ok := current[part]
+	var current any = data
+	for _, part := range strings.Split(path, ".") {
+		next, ok := childValues(current)[part]
 		if !ok {
 			return nil, false
 		}
+		current = next
+	}
 
-		next, ok := val.(map[string]any)
-		if !ok {
-			if strMap, isStrMap := val.(map[string]string); isStrMap {
-				next = make(map[string]any)
-				for k, v := range strMap {
-					next[k] = v
-				}
-			} else {
-				return nil, false
-			}
+	return current, true
+}
+
+// childValues returns the direct children of a nested value keyed by path
+// segment. Slice elements are keyed by their index.
+func childValues(val any) map[string]any {
+	switch v := val.(type) {
+	case map[string]any:
+		return v
+	case map[string]string:
+		result := make(map[string]any, len(v))
+		for k, item := range v {
+			result[k] = item
+		}
+		return result
+	case []any:
+		result := make(map[string]any, len(v))
+		for i, item := range v {
+			result[strconv.Itoa(i)] = item
+		}
+		return result
+	case []map[string]any:
+		result := make(map[string]any, len(v))
+		for i, item := range v {
+			result[strconv.Itoa(i)] = item
 		}
+		return result
+	case []string:
+		result := make(map[string]any, len(v))
+		for i, item := range v {
+			result[strconv.Itoa(i)] = item
+		}
+		return result
+	default:
+		return nil
+	}
+}
 
-		current = next
+// expandAttributes replaces glob patterns such as "tags.*" with the concrete
+// attribute paths they match in either configuration
+func expandAttributes(attributes []string, awsConfig, tfConfig map[string]any) []string {
+	var expanded []string
+	seen := make(map[string]bool)
+
+	add := func(attr string) {
+		if !seen[attr] {
+			seen[attr] = true
+			expanded = append(expanded, attr)
+		}
+	}
+
+	for _, attr := range attributes {
+		if !strings.ContainsAny(attr, "*?[") {
+			add(attr)
+			continue
+		}
+
+		parts := strings.Split(attr, ".")
+		var matches []string
+		matches = append(matches, matchPattern(awsConfig, parts, "")...)
+		matches = append(matches, matchPattern(tfConfig, parts, "")...)
+		sort.Strings(matches)
+		for _, match := range matches {
+			add(match)
+		}
 	}
 
-	lastPart := parts[len(parts)-1]
-	val, ok := current[lastPart]
-	return val, ok
+	return expanded
+}
+
+func matchPattern(val any, parts []string, prefix string) []string {
+	if len(parts) == 0 {
+		return []string{prefix}
+	}
+
+	var matches []string
+	for key, child := range childValues(val) {
+		if ok, _ := path.Match(parts[0], key); !ok {
+			continue
+		}
+
+		childPath := key
+		if prefix != "" {
+			childPath = prefix + "." + key
+		}
+		matches = append(matches, matchPattern(child, parts[1:], childPath)...)
+	}
+
+	return matches
 }
 
 func compareValues(v1, v2 any) bool {
+	return Options{}.compareValuesAt("", v1, v2)
+}
+
+// compareValuesAt compares the values at an attribute path. The values
+// nested in maps and lists are compared with the comparator for their own
+// path, if any; with an empty path, none are used.
+func (opts Options) compareValuesAt(path string, v1, v2 any) bool {
 	if v1 == nil && v2 == nil {
 		return true
 	}
@@ -106,18 +294,35 @@ func compareValues(v1, v2 any) bool {
 	v1 = normalizeValue(v1)
 	v2 = normalizeValue(v2)
 
+	// Most attributes are scalars; compare them directly rather than
+	// through reflect.DeepEqual
+	switch a := v1.(type) {
+	case string:
+		if b, ok := v2.(string); ok {
+			return a == b
+		}
+	case bool:
+		if b, ok := v2.(bool); ok {
+			return a == b
+		}
+	case float64:
+		if b, ok := v2.(float64); ok {
+			return a == b
+		}
+	}
+
 	m1, isMap1 := v1.(map[string]any)
 	m2, isMap2 := v2.(map[string]any)
 
 	if isMap1 && isMap2 {
-		return compareMaps(m1, m2)
+		return opts.compareMaps(path, m1, m2)
 	}
 
 	s1, isSlice1 := v1.([]any)
 	s2, isSlice2 := v2.([]any)
 
 	if isSlice1 && isSlice2 {
-		return compareSlices(s1, s2)
+		return opts.compareSlices(path, s1, s2)
 	}
 
 	return reflect.DeepEqual(v1, v2)
@@ -137,6 +342,13 @@ func normalizeValue(v any) any {
 		return float64(val)
 	case uint64:
 		return float64(val)
+	case *big.Float:
+		// HCL numbers are parsed as arbitrary-precision floats
+		if val == nil {
+			return nil
+		}
+		f, _ := val.Float64()
+		return f
 	case map[string]string:
 
 		result := make(map[string]any)
@@ -150,12 +362,18 @@ func normalizeValue(v any) any {
 			result[i] = v
 		}
 		return result
+	case []map[string]any:
+		result := make([]any, len(val))
+		for i, v := range val {
+			result[i] = v
+		}
+		return result
 	default:
 		return val
 	}
 }
 
-func compareMaps(m1, m2 map[string]any) bool {
+func (opts Options) compareMaps(path string, m1, m2 map[string]any) bool {
 	if len(m1) != len(m2) {
 		return false
 	}
@@ -166,7 +384,7 @@ func compareMaps(m1, m2 map[string]any) bool {
 			return false
 		}
 
-		if !compareValues(v1, v2) {
+		if !opts.compareChild(childPath(path, k), v1, v2) {
 			return false
 		}
 	}
@@ -174,7 +392,7 @@ func compareMaps(m1, m2 map[string]any) bool {
 	return true
 }
 
-func compareSlices(s1, s2 []any) bool {
+func (opts Options) compareSlices(path string, s1, s2 []any) bool {
 	if len(s1) != len(s2) {
 		return false
 	}
@@ -185,7 +403,7 @@ func compareSlices(s1, s2 []any) bool {
 	for _, v1 := range s1 {
 		found := false
 		for i, v2 := range s2Copy {
-			if compareValues(v1, v2) {
+			if opts.compareElements(childPath(path, "*"), v1, v2) {
 				s2Copy[i] = nil
 				found = true
 				break
@@ -200,10 +418,62 @@ func compareSlices(s1, s2 []any) bool {
 	return true
 }
 
+// compareElements compares two list elements. Blocks such as
+// ebs_block_device or network_interface entries are compared over the fields
+// of both sides, so a field set on only one side is a difference. Under
+// Options.TreatEmptyAsAbsent an empty field counts as unset.
+func (opts Options) compareElements(path string, v1, v2 any) bool {
+	m1, isMap1 := normalizeValue(v1).(map[string]any)
+	m2, isMap2 := normalizeValue(v2).(map[string]any)
+	if !isMap1 || !isMap2 {
+		return opts.compareChild(path, v1, v2)
+	}
+
+	for k, field1 := range m1 {
+		field2, ok := m2[k]
+		if opts.TreatEmptyAsAbsent && emptyOrAbsent(field1, true) && emptyOrAbsent(field2, ok) {
+			continue
+		}
+		if !ok || !opts.compareChild(childPath(path, k), field1, field2) {
+			return false
+		}
+	}
+	for k, field2 := range m2 {
+		if _, ok := m1[k]; ok {
+			continue
+		}
+		if !opts.TreatEmptyAsAbsent || !IsEmptyValue(field2) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareChild compares values nested in an attribute with the comparator
+// for their path, if any
+func (opts Options) compareChild(path string, v1, v2 any) bool {
+	if equal, ok := opts.lookupComparator(path); ok {
+		return equal(v1, v2)
+	}
+	return opts.compareValuesAt(path, v1, v2)
+}
+
+// childPath is the path of a nested value, which stays empty when comparing
+// without paths. List elements are matched regardless of order, so their
+// path segment is always "*".
+func childPath(path, key string) string {
+	if path == "" {
+		return ""
+	}
+	return path + "." + key
+}
+
 func (d DriftDetail) String() string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf("Attribute: %s\n", d.Attribute))
+	sb.WriteString(fmt.Sprintf("Severity: %s\n", d.Severity))
 
 	if d.InAWS && d.InTerraform {
 		sb.WriteString("Status: Values differ between AWS and Terraform\n")
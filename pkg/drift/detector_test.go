@@ -1,6 +1,8 @@
 package drift
 
 import (
+	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,7 +29,7 @@ func TestDetectDrift_NoDrift(t *testing.T) {
 
 	attributesToCheck := []string{"instance_type", "ami", "tags"}
 
-	drifts, err := DetectDrift(awsConfig, tfConfig, attributesToCheck)
+	drifts, err := DetectDrift(awsConfig, tfConfig, attributesToCheck, Options{})
 	
 	assert.NoError(t, err)
 	assert.Empty(t, drifts, "Expected no drift")
@@ -54,7 +56,7 @@ func TestDetectDrift_WithDrift(t *testing.T) {
 
 	attributesToCheck := []string{"instance_type", "ami", "tags"}
 
-	drifts, err := DetectDrift(awsConfig, tfConfig, attributesToCheck)
+	drifts, err := DetectDrift(awsConfig, tfConfig, attributesToCheck, Options{})
 	
 	assert.NoError(t, err)
 	assert.Len(t, drifts, 2, "Expected drift in 2 attributes")
@@ -76,7 +78,7 @@ func TestDetectDrift_AttributeInAWSOnly(t *testing.T) {
 
 	attributesToCheck := []string{"instance_type", "ami"}
 
-	drifts, err := DetectDrift(awsConfig, tfConfig, attributesToCheck)
+	drifts, err := DetectDrift(awsConfig, tfConfig, attributesToCheck, Options{})
 	
 	assert.NoError(t, err)
 	assert.Len(t, drifts, 1, "Expected drift in 1 attribute")
@@ -101,7 +103,7 @@ func TestDetectDrift_AttributeInTerraformOnly(t *testing.T) {
 
 	attributesToCheck := []string{"instance_type", "ami"}
 
-	drifts, err := DetectDrift(awsConfig, tfConfig, attributesToCheck)
+	drifts, err := DetectDrift(awsConfig, tfConfig, attributesToCheck, Options{})
 	
 	assert.NoError(t, err)
 	assert.Len(t, drifts, 1, "Expected drift in 1 attribute")
@@ -186,4 +188,528 @@ func TestCompareValues(t *testing.T) {
 	
 	assert.True(t, compareValues(slice1, slice2))
 	assert.False(t, compareValues(slice1, slice3))
-}
\ No newline at end of file
+}
+
+func TestCompareValues_Scalars(t *testing.T) {
+	assert.True(t, compareValues(true, true))
+	assert.False(t, compareValues(true, false))
+	assert.True(t, compareValues(int32(8), float64(8)))
+	// Scalars of different types fall through to the general comparison
+	assert.False(t, compareValues("true", true))
+	assert.False(t, compareValues("8", float64(8)))
+}
+
+func BenchmarkCompareValues_Scalars(b *testing.B) {
+	pairs := [][2]any{
+		{"t3.micro", "t3.micro"},
+		{"subnet-0abc", "subnet-0def"},
+		{true, true},
+		{float64(100), int32(100)},
+	}
+
+	b.Run("compareValues", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, pair := range pairs {
+				compareValues(pair[0], pair[1])
+			}
+		}
+	})
+	// The comparison before the scalar fast path, for reference
+	b.Run("reflect.DeepEqual", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, pair := range pairs {
+				reflect.DeepEqual(normalizeValue(pair[0]), normalizeValue(pair[1]))
+			}
+		}
+	})
+}
+
+func TestCompareValues_BigFloat(t *testing.T) {
+	// HCL `volume_size = 8` is parsed as a *big.Float
+	hclValue := big.NewFloat(8)
+
+	assert.True(t, compareValues(int32(8), hclValue))
+	assert.True(t, compareValues(hclValue, 8))
+	assert.False(t, compareValues(int32(10), hclValue))
+}
+
+func TestGetNestedValue_SliceIndex(t *testing.T) {
+	data := map[string]any{
+		"ebs_block_device": []map[string]any{
+			{"device_name": "/dev/sdf", "volume_size": 8},
+		},
+	}
+
+	val, exists := getNestedValue(data, "ebs_block_device.0.volume_size")
+	assert.True(t, exists)
+	assert.Equal(t, 8, val)
+
+	_, exists = getNestedValue(data, "ebs_block_device.1.volume_size")
+	assert.False(t, exists)
+}
+
+func TestDetectDrift_GlobPatterns(t *testing.T) {
+	awsConfig := map[string]any{
+		"tags": map[string]string{
+			"Name":        "test-instance",
+			"Environment": "dev",
+			"Owner":       "ops",
+		},
+		"ebs_block_device": []map[string]any{
+			{"device_name": "/dev/sdf", "volume_size": int32(8)},
+			{"device_name": "/dev/sdg", "volume_size": int32(20)},
+		},
+	}
+
+	tfConfig := map[string]any{
+		"tags": map[string]any{
+			"Name":        "test-instance",
+			"Environment": "production",
+		},
+		"ebs_block_device": []any{
+			map[string]any{"device_name": "/dev/sdf", "volume_size": 8.0},
+			map[string]any{"device_name": "/dev/sdg", "volume_size": 30.0},
+		},
+	}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"tags.*", "ebs_block_device.*.volume_size"}, Options{})
+
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 3)
+	assert.Contains(t, drifts, "tags.Environment")
+	assert.Contains(t, drifts, "tags.Owner")
+	assert.Contains(t, drifts, "ebs_block_device.1.volume_size")
+	assert.False(t, drifts["tags.Owner"].InTerraform)
+}
+
+func TestDetectDrift_EncryptionDisabledIsHighSeverity(t *testing.T) {
+	awsConfig := map[string]any{
+		"ebs_block_device": []map[string]any{
+			{"device_name": "/dev/sdf", "volume_size": int32(8), "encrypted": false},
+		},
+		"instance_type": "t2.micro",
+	}
+
+	tfConfig := map[string]any{
+		"ebs_block_device": []any{
+			map[string]any{"device_name": "/dev/sdf", "volume_size": 8.0, "encrypted": true},
+		},
+		"instance_type": "t2.small",
+	}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"ebs_block_device", "instance_type"}, Options{})
+
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 2)
+	assert.Equal(t, SeverityHigh, drifts["ebs_block_device"].Severity)
+	assert.Equal(t, DefaultSeverity, drifts["instance_type"].Severity)
+}
+
+func TestDetectDrift_DeleteOnTerminationIsHighSeverity(t *testing.T) {
+	awsConfig := map[string]any{
+		"ebs_block_device": []map[string]any{
+			{"device_name": "/dev/sda1", "volume_id": "vol-root", "delete_on_termination": true},
+			{"device_name": "/dev/sdf", "volume_id": "vol-data", "delete_on_termination": true},
+		},
+	}
+
+	tfConfig := map[string]any{
+		"ebs_block_device": []any{
+			map[string]any{"device_name": "/dev/sda1", "delete_on_termination": true},
+			map[string]any{"device_name": "/dev/sdf", "delete_on_termination": false},
+		},
+	}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"ebs_block_device"}, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, SeverityHigh, drifts["ebs_block_device"].Severity)
+
+	drifts, err = DetectDrift(awsConfig, tfConfig, []string{"ebs_block_device.*.delete_on_termination"}, Options{})
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 1)
+	assert.Equal(t, SeverityHigh, drifts["ebs_block_device.1.delete_on_termination"].Severity)
+}
+
+func TestLookupSeverity(t *testing.T) {
+	assert.Equal(t, SeverityHigh, lookupSeverity("ebs_block_device.0.encrypted"))
+	assert.Equal(t, SeverityHigh, lookupSeverity("root_block_device.0.delete_on_termination"))
+	assert.Equal(t, SeverityLow, lookupSeverity("tags.Environment"))
+	assert.Equal(t, DefaultSeverity, lookupSeverity("ebs_block_device.0.volume_size"))
+}
+
+func TestDetectDrift_Reasons(t *testing.T) {
+	awsConfig := map[string]any{
+		"instance_type":          "t2.micro",
+		"monitoring":             true,
+		"vpc_security_group_ids": []string{"sg-1"},
+		"key_name":               "deployer",
+	}
+
+	tfConfig := map[string]any{
+		"instance_type":          "t2.small",
+		"monitoring":             true,
+		"vpc_security_group_ids": "sg-1",
+		"subnet_id":              "subnet-1",
+	}
+
+	attributesToCheck := []string{"instance_type", "monitoring", "vpc_security_group_ids", "key_name", "subnet_id"}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, attributesToCheck, Options{})
+
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 4)
+	assert.Equal(t, ReasonValueMismatch, drifts["instance_type"].Reason)
+	assert.Equal(t, ReasonTypeMismatch, drifts["vpc_security_group_ids"].Reason)
+	assert.Equal(t, ReasonMissingInTerraform, drifts["key_name"].Reason)
+	assert.Equal(t, ReasonMissingInAWS, drifts["subnet_id"].Reason)
+}
+
+func TestDetectDrift_NetworkInterfaceBlocks(t *testing.T) {
+	awsConfig := map[string]any{
+		"network_interface": []map[string]any{
+			{"network_interface_id": "eni-1", "device_index": int32(0), "private_ips": []string{"10.0.0.10"}},
+			{"network_interface_id": "eni-2", "device_index": int32(1), "private_ips": []string{"10.0.1.10"}},
+		},
+	}
+
+	// Blocks match in any order
+	tfConfig := map[string]any{
+		"network_interface": []any{
+			map[string]any{"network_interface_id": "eni-2", "device_index": 1.0, "private_ips": []any{"10.0.1.10"}},
+			map[string]any{"network_interface_id": "eni-1", "device_index": 0.0, "private_ips": []any{"10.0.0.10"}},
+		},
+	}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"network_interface"}, Options{})
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	tfConfig["network_interface"] = []any{
+		map[string]any{"network_interface_id": "eni-3", "device_index": 1.0, "private_ips": []any{"10.0.1.10"}},
+		map[string]any{"network_interface_id": "eni-1", "device_index": 0.0, "private_ips": []any{"10.0.0.10"}},
+	}
+
+	drifts, err = DetectDrift(awsConfig, tfConfig, []string{"network_interface"}, Options{})
+	assert.NoError(t, err)
+	assert.Contains(t, drifts, "network_interface")
+}
+
+func TestDetectDrift_BlockFieldsOnOneSide(t *testing.T) {
+	awsConfig := map[string]any{
+		"ebs_block_device": []map[string]any{
+			{"device_name": "/dev/sdf", "volume_size": int32(100), "volume_type": "gp3"},
+		},
+	}
+
+	// Sharing device_name doesn't make blocks equal when other fields are
+	// missing on one side
+	tfConfig := map[string]any{
+		"ebs_block_device": []any{
+			map[string]any{"device_name": "/dev/sdf", "snapshot_id": "snap-1"},
+		},
+	}
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"ebs_block_device"}, Options{})
+	assert.NoError(t, err)
+	assert.Contains(t, drifts, "ebs_block_device")
+
+	// Under TreatEmptyAsAbsent, empty fields count as unset
+	tfConfig["ebs_block_device"] = []any{
+		map[string]any{"device_name": "/dev/sdf", "volume_size": 100.0, "volume_type": "gp3", "snapshot_id": ""},
+	}
+	drifts, err = DetectDrift(awsConfig, tfConfig, []string{"ebs_block_device"}, Options{TreatEmptyAsAbsent: true})
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+}
+
+func TestValueKind_Pointers(t *testing.T) {
+	size := int32(8)
+	var unset *int32
+
+	assert.Equal(t, "number", valueKind(&size))
+	assert.Equal(t, "number", valueKind(big.NewFloat(8)))
+	assert.Equal(t, "null", valueKind(unset))
+	assert.Equal(t, ReasonValueMismatch, mismatchReason(&size, int32(10)))
+}
+
+func TestUseAMINames(t *testing.T) {
+	awsConfig := map[string]any{"ami": "ami-0new", "ami_name": "golden-ubuntu-22.04"}
+
+	// Terraform pins by ID: nothing changes
+	tfConfig := map[string]any{"ami": "ami-0old"}
+	UseAMINames(awsConfig, tfConfig)
+	assert.Equal(t, "ami-0new", awsConfig["ami"])
+
+	// Terraform pins by name: compare names
+	tfConfig = map[string]any{"ami": "golden-ubuntu-22.04"}
+	UseAMINames(awsConfig, tfConfig)
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"ami"}, Options{})
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+}
+
+func TestNewReport(t *testing.T) {
+	drifts := map[string]DriftDetail{
+		"tags":          {Attribute: "tags"},
+		"ami":           {Attribute: "ami"},
+		"instance_type": {},
+	}
+
+	report := NewReport("i-12345", drifts)
+
+	assert.Equal(t, "i-12345", report.InstanceID)
+	assert.True(t, report.HasDrift())
+	assert.Len(t, report.Drifts, 3)
+	assert.Equal(t, "ami", report.Drifts[0].Attribute)
+	assert.Equal(t, "instance_type", report.Drifts[1].Attribute)
+	assert.Equal(t, "tags", report.Drifts[2].Attribute)
+	assert.Equal(t, drifts["tags"], report.DriftMap()["tags"])
+
+	assert.False(t, NewReport("i-12345", nil).HasDrift())
+}
+
+func TestReport_MaxSeverity(t *testing.T) {
+	report := NewReport("i-12345", map[string]DriftDetail{
+		"tags":    {Severity: SeverityLow},
+		"tenancy": {Severity: SeverityHigh},
+		"ami":     {Severity: SeverityMedium},
+	})
+
+	assert.Equal(t, SeverityHigh, report.MaxSeverity())
+	assert.Equal(t, Severity(""), NewReport("i-12345", nil).MaxSeverity())
+}
+
+func TestReport_TopSeverity(t *testing.T) {
+	report := NewReport("i-12345", map[string]DriftDetail{
+		"tags":          {Attribute: "tags", Severity: SeverityLow},
+		"tenancy":       {Attribute: "tenancy", Severity: SeverityHigh},
+		"instance_type": {Attribute: "instance_type", Severity: SeverityHigh},
+	})
+
+	top := report.TopSeverity()
+	assert.Equal(t, []DriftDetail{{Attribute: "instance_type", Severity: SeverityHigh}}, top.Drifts)
+	assert.Len(t, report.Drifts, 3)
+	assert.Empty(t, NewReport("i-12345", nil).TopSeverity().Drifts)
+}
+
+func TestIgnoreDefaults(t *testing.T) {
+	awsConfig := map[string]any{
+		"instance_type": "t2.micro",
+		"monitoring":    false,
+		"ebs_optimized": true,
+		"tenancy":       "default",
+	}
+	tfConfig := map[string]any{
+		"instance_type": "t2.small",
+	}
+
+	attributes := []string{"instance_type", "monitoring", "ebs_optimized", "tenancy"}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, attributes, Options{})
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 4)
+
+	drifts, err = DetectDrift(awsConfig, tfConfig, attributes, Options{Defaults: AWSDefaults})
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 2)
+	assert.Contains(t, drifts, "instance_type")
+	assert.Contains(t, drifts, "ebs_optimized", "non-default AWS values are still reported")
+}
+
+func TestReplacementAttributes(t *testing.T) {
+	drifts := map[string]DriftDetail{
+		"tags":          {Attribute: "tags"},
+		"instance_type": {Attribute: "instance_type"},
+		"ami":           {Attribute: "ami"},
+	}
+
+	assert.Equal(t, []string{"ami", "instance_type"}, ReplacementAttributes(drifts, ReplaceAttributes))
+	assert.Empty(t, ReplacementAttributes(map[string]DriftDetail{"tags": {Attribute: "tags"}}, ReplaceAttributes))
+	assert.Equal(t, []string{"tags"}, ReplacementAttributes(drifts, []string{"tags"}))
+}
+
+func TestRemoveUnchecked(t *testing.T) {
+	drifts := map[string]DriftDetail{
+		"ebs_block_device":             {Attribute: "ebs_block_device"},
+		"ebs_block_device.0.encrypted": {Attribute: "ebs_block_device.0.encrypted"},
+		"ebs_block_device_count":       {Attribute: "ebs_block_device_count"},
+		"instance_type":                {Attribute: "instance_type"},
+	}
+
+	RemoveUnchecked(drifts, []string{"ebs_block_device", "root_block_device"})
+
+	assert.Len(t, drifts, 2)
+	assert.Contains(t, drifts, "instance_type")
+	assert.Contains(t, drifts, "ebs_block_device_count")
+}
+
+func TestDetectDrift_SpotMarketOptions(t *testing.T) {
+	awsConfig := map[string]any{
+		"instance_lifecycle": "spot",
+		"instance_market_options": []map[string]any{
+			{"market_type": "spot", "spot_options": []map[string]any{{"spot_instance_type": "one-time"}}},
+		},
+	}
+	tfConfig := map[string]any{
+		"instance_lifecycle":      "",
+		"instance_market_options": []any{},
+	}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"instance_lifecycle", "instance_market_options"}, Options{})
+
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 2)
+	assert.Equal(t, SeverityHigh, drifts["instance_lifecycle"].Severity)
+}
+
+func TestDetectDrift_TerminationProtection(t *testing.T) {
+	awsConfig := map[string]any{"disable_api_termination": false}
+	tfConfig := map[string]any{"disable_api_termination": true}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"disable_api_termination"}, Options{})
+
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 1)
+	assert.Equal(t, SeverityHigh, drifts["disable_api_termination"].Severity)
+}
+
+func TestDetectDrift_SpotOptionsChanged(t *testing.T) {
+	awsConfig := map[string]any{
+		"instance_market_options": []map[string]any{
+			{"market_type": "spot", "spot_options": []map[string]any{{"spot_instance_type": "persistent"}}},
+		},
+	}
+	tfConfig := map[string]any{
+		"instance_market_options": []any{
+			map[string]any{"market_type": "spot", "spot_options": []any{map[string]any{"spot_instance_type": "one-time"}}},
+		},
+	}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"instance_market_options"}, Options{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, drifts, "instance_market_options")
+}
+
+func TestDetectDrift_TreatEmptyAsAbsent(t *testing.T) {
+	awsConfig := map[string]any{
+		"user_data":              "",
+		"vpc_security_group_ids": []string{},
+		"key_name":               "deployer",
+	}
+	tfConfig := map[string]any{
+		"tags":                   map[string]any{},
+		"vpc_security_group_ids": []any{},
+		"iam_instance_profile":   "",
+		"key_name":               "",
+	}
+	attributes := []string{"user_data", "tags", "vpc_security_group_ids", "iam_instance_profile", "key_name"}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, attributes, Options{})
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 4, "empty and absent differ by default")
+
+	drifts, err = DetectDrift(awsConfig, tfConfig, attributes, Options{TreatEmptyAsAbsent: true})
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 1)
+	assert.Contains(t, drifts, "key_name", "an empty value still drifts from a set one")
+}
+
+func TestIsEmptyValue(t *testing.T) {
+	assert.True(t, IsEmptyValue(nil))
+	assert.True(t, IsEmptyValue(""))
+	assert.True(t, IsEmptyValue([]any{}))
+	assert.True(t, IsEmptyValue([]string(nil)))
+	assert.True(t, IsEmptyValue(map[string]string{}))
+	assert.False(t, IsEmptyValue("a"))
+	assert.False(t, IsEmptyValue(false))
+	assert.False(t, IsEmptyValue(0))
+	assert.False(t, IsEmptyValue(map[string]any{"Name": ""}))
+}
+
+func TestDetectDrift_CPUOptionsRequireReplacement(t *testing.T) {
+	awsConfig := map[string]any{
+		"cpu_options": []map[string]any{{"core_count": int32(4), "threads_per_core": int32(2)}},
+		"hibernation": false,
+	}
+	tfConfig := map[string]any{
+		"cpu_options": []any{map[string]any{"core_count": float64(2), "threads_per_core": float64(2)}},
+		"hibernation": true,
+	}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"cpu_options", "hibernation"}, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, SeverityHigh, drifts["cpu_options"].Severity)
+	assert.Equal(t, SeverityHigh, drifts["hibernation"].Severity)
+	assert.Equal(t, []string{"cpu_options", "hibernation"}, ReplacementAttributes(drifts, ReplaceAttributes))
+}
+
+func TestDetectDrift_TagDiff(t *testing.T) {
+	awsConfig := map[string]any{
+		"tags":          map[string]string{"Name": "web", "Environment": "dev", "Owner": "ops"},
+		"instance_type": "t2.micro",
+	}
+	tfConfig := map[string]any{
+		"tags":          map[string]any{"Name": "web", "Environment": "prod", "Team": "infra"},
+		"instance_type": "t2.small",
+	}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"tags", "instance_type"}, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, &TagDiff{
+		OnlyInAWS:       []string{"Owner"},
+		OnlyInTerraform: []string{"Team"},
+		Changed:         []string{"Environment"},
+	}, drifts["tags"].TagDiff)
+	assert.Nil(t, drifts["instance_type"].TagDiff)
+}
+
+func TestDetectDrift_TagValuesComparedAsStrings(t *testing.T) {
+	awsConfig := map[string]any{
+		"tags": map[string]string{"Name": "web", "Port": "8080", "Public": "true", "Ratio": "0.5"},
+	}
+	tfConfig := map[string]any{
+		"tags": map[string]any{"Name": "web", "Port": big.NewFloat(8080), "Public": true, "Ratio": 0.5},
+	}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, []string{"tags", "tags.*"}, Options{})
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	tfConfig["tags"].(map[string]any)["Port"] = 8081
+	drifts, err = DetectDrift(awsConfig, tfConfig, []string{"tags", "tags.Port"}, Options{})
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 2)
+	assert.Equal(t, []string{"Port"}, drifts["tags"].TagDiff.Changed)
+}
+
+func TestDetectDrift_CompareTagsSubset(t *testing.T) {
+	awsConfig := map[string]any{
+		"tags": map[string]string{"Name": "web", "Environment": "prod", "PatchGroup": "weekly"},
+	}
+	tfConfig := map[string]any{
+		"tags": map[string]any{"Name": "web", "Environment": "prod"},
+	}
+	attributes := []string{"tags", "tags.*"}
+
+	drifts, err := DetectDrift(awsConfig, tfConfig, attributes, Options{})
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 2, "extra AWS tags drift by default")
+
+	opts := Options{CompareTagsSubset: true}
+	drifts, err = DetectDrift(awsConfig, tfConfig, attributes, opts)
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	// Required tags that are missing or changed in AWS still drift
+	tfConfig["tags"] = map[string]any{"Name": "web", "Environment": "staging", "Owner": "platform"}
+	drifts, err = DetectDrift(awsConfig, tfConfig, attributes, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, &TagDiff{
+		OnlyInAWS:       []string{},
+		OnlyInTerraform: []string{"Owner"},
+		Changed:         []string{"Environment"},
+	}, drifts["tags"].TagDiff)
+	assert.Contains(t, drifts, "tags.Environment")
+	assert.Contains(t, drifts, "tags.Owner")
+	assert.NotContains(t, drifts, "tags.PatchGroup")
+}
@@ -0,0 +1,27 @@
+package drift
+
+import "reflect"
+
+// IsEmptyValue reports whether a value is null, an empty string or an empty
+// list or map
+func IsEmptyValue(value any) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// emptyOrAbsent reports whether an attribute counts as unset under
+// Options.TreatEmptyAsAbsent
+func emptyOrAbsent(value any, exists bool) bool {
+	return !exists || IsEmptyValue(value)
+}
@@ -0,0 +1,131 @@
+package drift
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// IgnoreRules are the attributes and instances an ignore file excludes from
+// drift detection. Patterns may contain globs; attribute patterns are dotted
+// paths such as tags.LastPatched or ebs_block_device.*.iops.
+type IgnoreRules struct {
+	Attributes []string
+	Instances  []string
+}
+
+// ParseIgnoreRules reads ignore rules, one pattern per line. Blank lines and
+// lines starting with # are skipped. Lines starting with i-, aws_instance.
+// or module. name instances by ID or resource address; all other lines are
+// attribute paths.
+func ParseIgnoreRules(r io.Reader) (IgnoreRules, error) {
+	var rules IgnoreRules
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		pattern := strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return IgnoreRules{}, fmt.Errorf("line %d: invalid pattern %q: %w", line, pattern, err)
+		}
+
+		if isInstancePattern(pattern) {
+			rules.Instances = append(rules.Instances, pattern)
+		} else {
+			rules.Attributes = append(rules.Attributes, pattern)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return IgnoreRules{}, err
+	}
+	return rules, nil
+}
+
+func isInstancePattern(pattern string) bool {
+	for _, prefix := range []string{"i-", "aws_instance.", "module."} {
+		if strings.HasPrefix(pattern, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IgnoresInstance reports whether an instance, given by ID and, if known,
+// resource address, is ignored
+func (r IgnoreRules) IgnoresInstance(instanceID, address string) bool {
+	for _, pattern := range r.Instances {
+		if ok, _ := path.Match(pattern, instanceID); ok {
+			return true
+		}
+		if address == "" {
+			continue
+		}
+		if ok, _ := path.Match(pattern, address); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// StripAttributes returns a copy of config without the ignored attributes,
+// so they can't drift. Maps and lists along an ignored path are copied
+// rather than modified.
+func (r IgnoreRules) StripAttributes(config map[string]any) map[string]any {
+	if len(r.Attributes) == 0 {
+		return config
+	}
+
+	stripped := make(map[string]any, len(config))
+	for key, value := range config {
+		stripped[key] = value
+	}
+	for _, pattern := range r.Attributes {
+		parts := strings.Split(pattern, ".")
+		for key, value := range stripped {
+			if ok, _ := path.Match(parts[0], key); !ok {
+				continue
+			}
+			if len(parts) == 1 {
+				delete(stripped, key)
+				continue
+			}
+			stripped[key] = stripValue(value, parts[1:])
+		}
+	}
+	return stripped
+}
+
+// stripValue removes the parts of value matched by the path segments
+func stripValue(value any, parts []string) any {
+	children := childValues(value)
+	if children == nil {
+		return value
+	}
+
+	stripped := make(map[string]any, len(children))
+	for key, child := range children {
+		if ok, _ := path.Match(parts[0], key); ok {
+			if len(parts) == 1 {
+				continue
+			}
+			child = stripValue(child, parts[1:])
+		}
+		stripped[key] = child
+	}
+
+	switch value.(type) {
+	case []any, []map[string]any, []string:
+		list := make([]any, 0, len(stripped))
+		for i := 0; i < len(children); i++ {
+			if child, ok := stripped[strconv.Itoa(i)]; ok {
+				list = append(list, child)
+			}
+		}
+		return list
+	}
+	return stripped
+}
@@ -0,0 +1,71 @@
+package drift
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIgnoreRules(t *testing.T) {
+	input := `
+# noisy attributes
+tags.LastPatched
+ebs_block_device.*.iops
+
+i-0123456789abcdef0
+module.legacy.*
+`
+	rules, err := ParseIgnoreRules(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tags.LastPatched", "ebs_block_device.*.iops"}, rules.Attributes)
+	assert.Equal(t, []string{"i-0123456789abcdef0", "module.legacy.*"}, rules.Instances)
+
+	_, err = ParseIgnoreRules(strings.NewReader("tags.[Owner"))
+	assert.Error(t, err)
+}
+
+func TestIgnoreRules_IgnoresInstance(t *testing.T) {
+	rules := IgnoreRules{Instances: []string{"i-0123*", "aws_instance.bastion"}}
+
+	assert.True(t, rules.IgnoresInstance("i-0123456789abcdef0", ""))
+	assert.True(t, rules.IgnoresInstance("i-0999", "aws_instance.bastion"))
+	assert.False(t, rules.IgnoresInstance("i-0999", "aws_instance.web"))
+	assert.False(t, rules.IgnoresInstance("i-0999", ""))
+}
+
+func TestIgnoreRules_StripAttributes(t *testing.T) {
+	rules := IgnoreRules{Attributes: []string{"tags.LastPatched", "ebs_block_device.*.iops", "user_data"}}
+	config := map[string]any{
+		"instance_type": "t2.micro",
+		"user_data":     "#!/bin/bash",
+		"tags":          map[string]string{"Name": "web", "LastPatched": "2024-01-01"},
+		"ebs_block_device": []map[string]any{
+			{"device_name": "/dev/sdb", "iops": 3000},
+		},
+	}
+
+	stripped := rules.StripAttributes(config)
+	assert.Equal(t, map[string]any{
+		"instance_type": "t2.micro",
+		"tags":          map[string]any{"Name": "web"},
+		"ebs_block_device": []any{
+			map[string]any{"device_name": "/dev/sdb"},
+		},
+	}, stripped)
+
+	// The input is left as it was
+	assert.Contains(t, config, "user_data")
+	assert.Contains(t, config["tags"], "LastPatched")
+	assert.Contains(t, config["ebs_block_device"].([]map[string]any)[0], "iops")
+}
+
+func TestDetectDrift_IgnoredAttributes(t *testing.T) {
+	rules := IgnoreRules{Attributes: []string{"tags.LastPatched"}}
+	awsConfig := map[string]any{"tags": map[string]string{"Name": "web", "LastPatched": "2024-06-01"}}
+	tfConfig := map[string]any{"tags": map[string]string{"Name": "web", "LastPatched": "2024-01-01"}}
+
+	drifts, err := DetectDrift(rules.StripAttributes(awsConfig), rules.StripAttributes(tfConfig), []string{"tags"}, Options{})
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+}
@@ -0,0 +1,45 @@
+package drift
+
+import "strings"
+
+// NaturalLess orders strings with runs of digits compared by their numeric
+// value, so generated names such as i-9 and web-9 sort before i-10 and
+// web-10. Other characters compare byte by byte, as with a < b.
+func NaturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			numA, restA := digitRun(a)
+			numB, restB := digitRun(b)
+			if valueA, valueB := strings.TrimLeft(numA, "0"), strings.TrimLeft(numB, "0"); valueA != valueB {
+				if len(valueA) != len(valueB) {
+					return len(valueA) < len(valueB)
+				}
+				return valueA < valueB
+			}
+			// Equal values such as 01 and 1: fewer leading zeros first
+			if len(numA) != len(numB) {
+				return len(numA) < len(numB)
+			}
+			a, b = restA, restB
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+// digitRun splits s after its leading run of digits
+func digitRun(s string) (string, string) {
+	end := 0
+	for end < len(s) && isDigit(s[end]) {
+		end++
+	}
+	return s[:end], s[end:]
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
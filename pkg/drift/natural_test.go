@@ -0,0 +1,33 @@
+package drift
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		less bool
+	}{
+		{"i-9", "i-10", true},
+		{"i-10", "i-9", false},
+		{"web-2-b", "web-10-a", true},
+		{"i-0abc", "i-0abd", true},
+		{"i-01", "i-1", false},
+		{"i-1", "i-01", true},
+		{"i-1", "i-1", false},
+		{"i-1", "i-1a", true},
+		{"", "a", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.less, NaturalLess(tt.a, tt.b), "%q < %q", tt.a, tt.b)
+	}
+
+	ids := []string{"i-10", "i-9", "i-100", "i-1"}
+	sort.Slice(ids, func(i, j int) bool { return NaturalLess(ids[i], ids[j]) })
+	assert.Equal(t, []string{"i-1", "i-9", "i-10", "i-100"}, ids)
+}
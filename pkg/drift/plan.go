@@ -0,0 +1,77 @@
+package drift
+
+import (
+	"sort"
+	"strings"
+)
+
+// PlanReconciliation compares the drift found on an instance with the
+// drift Terraform found on it when planning. Attributes are top-level
+// attribute names, since that is the detail a plan gives.
+type PlanReconciliation struct {
+	InstanceID string `json:"instance_id"`
+	Address    string `json:"address,omitempty"`
+	// Agreed drifted according to both
+	Agreed []string `json:"agreed"`
+	// OnlyTerraform drifted according to Terraform but not to us
+	OnlyTerraform []string `json:"only_terraform"`
+	// OnlyDetected drifted according to us but not to Terraform
+	OnlyDetected []string `json:"only_detected"`
+}
+
+// Consistent reports whether both sides found the same drift
+func (r PlanReconciliation) Consistent() bool {
+	return len(r.OnlyTerraform) == 0 && len(r.OnlyDetected) == 0
+}
+
+// ReconcilePlan compares a report with the top-level attributes Terraform
+// found drifted on the same instance. Only the checked attributes are
+// compared, and a nested attribute such as tags.Name counts as its
+// top-level attribute.
+func ReconcilePlan(report Report, checked, planAttributes []string) PlanReconciliation {
+	compared := make(map[string]bool)
+	for _, attr := range checked {
+		compared[topLevelAttribute(attr)] = true
+	}
+
+	detected := make(map[string]bool)
+	for _, detail := range report.Drifts {
+		detected[topLevelAttribute(detail.Attribute)] = true
+	}
+	inPlan := make(map[string]bool)
+	for _, attr := range planAttributes {
+		if compared[attr] {
+			inPlan[attr] = true
+		}
+	}
+
+	reconciliation := PlanReconciliation{
+		InstanceID:    report.InstanceID,
+		Agreed:        []string{},
+		OnlyTerraform: []string{},
+		OnlyDetected:  []string{},
+	}
+	for attr := range detected {
+		if inPlan[attr] {
+			reconciliation.Agreed = append(reconciliation.Agreed, attr)
+		} else {
+			reconciliation.OnlyDetected = append(reconciliation.OnlyDetected, attr)
+		}
+	}
+	for attr := range inPlan {
+		if !detected[attr] {
+			reconciliation.OnlyTerraform = append(reconciliation.OnlyTerraform, attr)
+		}
+	}
+
+	sort.Strings(reconciliation.Agreed)
+	sort.Strings(reconciliation.OnlyTerraform)
+	sort.Strings(reconciliation.OnlyDetected)
+	return reconciliation
+}
+
+// topLevelAttribute returns the first segment of an attribute path
+func topLevelAttribute(attr string) string {
+	root, _, _ := strings.Cut(attr, ".")
+	return root
+}
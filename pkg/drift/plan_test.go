@@ -0,0 +1,33 @@
+package drift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcilePlan(t *testing.T) {
+	report := NewReport("i-123", map[string]DriftDetail{
+		"instance_type": {Attribute: "instance_type"},
+		"tags.Owner":    {Attribute: "tags.Owner"},
+		"monitoring":    {Attribute: "monitoring"},
+	})
+	checked := []string{"instance_type", "tags.*", "monitoring", "ami"}
+	// tags_all isn't checked, so Terraform's drift in it is left out
+	planAttributes := []string{"ami", "instance_type", "tags", "tags_all"}
+
+	reconciliation := ReconcilePlan(report, checked, planAttributes)
+
+	assert.Equal(t, "i-123", reconciliation.InstanceID)
+	assert.Equal(t, []string{"instance_type", "tags"}, reconciliation.Agreed)
+	assert.Equal(t, []string{"ami"}, reconciliation.OnlyTerraform)
+	assert.Equal(t, []string{"monitoring"}, reconciliation.OnlyDetected)
+	assert.False(t, reconciliation.Consistent())
+}
+
+func TestReconcilePlan_NoDriftInPlan(t *testing.T) {
+	reconciliation := ReconcilePlan(NewReport("i-123", nil), []string{"ami"}, nil)
+
+	assert.True(t, reconciliation.Consistent())
+	assert.Empty(t, reconciliation.Agreed)
+}
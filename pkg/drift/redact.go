@@ -0,0 +1,81 @@
+package drift
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// RedactedValue replaces the values of redacted attributes
+const RedactedValue = "***"
+
+// Redact replaces the AWS and Terraform values of drifts matching any of the
+// attribute patterns (e.g. user_data or tags.DbPassword) with RedactedValue,
+// keeping the drift itself. A pattern naming a key inside a map or list
+// attribute only masks that key.
+func Redact(drifts map[string]DriftDetail, patterns []string) {
+	for attr, detail := range drifts {
+		attrParts := strings.Split(attr, ".")
+		for _, pattern := range patterns {
+			patternParts := strings.Split(pattern, ".")
+			if !matchPrefix(patternParts, attrParts) {
+				continue
+			}
+
+			// The pattern names the attribute or a parent: mask all of it;
+			// otherwise mask the matching keys within its value
+			var rest []string
+			if len(patternParts) > len(attrParts) {
+				rest = patternParts[len(attrParts):]
+			}
+			detail.AWSValue = redactValue(detail.AWSValue, rest)
+			detail.TerraformValue = redactValue(detail.TerraformValue, rest)
+		}
+		drifts[attr] = detail
+	}
+}
+
+// matchPrefix reports whether the shorter of the pattern and attribute paths
+// matches the start of the other segment by segment
+func matchPrefix(patternParts, attrParts []string) bool {
+	for i := 0; i < len(patternParts) && i < len(attrParts); i++ {
+		if ok, _ := path.Match(patternParts[i], attrParts[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// redactValue masks the parts of value matched by the remaining path
+// segments, copying maps and lists rather than modifying them
+func redactValue(value any, parts []string) any {
+	if value == nil {
+		return nil
+	}
+	if len(parts) == 0 {
+		return RedactedValue
+	}
+
+	children := childValues(value)
+	if children == nil {
+		return value
+	}
+
+	redacted := make(map[string]any, len(children))
+	for key, child := range children {
+		if ok, _ := path.Match(parts[0], key); ok {
+			child = redactValue(child, parts[1:])
+		}
+		redacted[key] = child
+	}
+
+	switch value.(type) {
+	case []any, []map[string]any, []string:
+		list := make([]any, len(redacted))
+		for i := range list {
+			list[i] = redacted[strconv.Itoa(i)]
+		}
+		return list
+	}
+	return redacted
+}
@@ -0,0 +1,51 @@
+package drift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	drifts := map[string]DriftDetail{
+		"user_data": {Attribute: "user_data", InAWS: true, InTerraform: true, AWSValue: "abc", TerraformValue: "def"},
+		"tags": {
+			Attribute:      "tags",
+			InAWS:          true,
+			InTerraform:    true,
+			AWSValue:       map[string]string{"Name": "web", "DbPassword": "hunter2"},
+			TerraformValue: map[string]any{"Name": "web", "DbPassword": "correct-horse"},
+		},
+		"tags.ApiKey":   {Attribute: "tags.ApiKey", InAWS: true, AWSValue: "secret"},
+		"instance_type": {Attribute: "instance_type", InAWS: true, InTerraform: true, AWSValue: "t2.micro", TerraformValue: "t3.micro"},
+	}
+
+	Redact(drifts, []string{"user_data", "tags.DbPassword", "tags.Api*"})
+
+	assert.Equal(t, RedactedValue, drifts["user_data"].AWSValue)
+	assert.Equal(t, RedactedValue, drifts["user_data"].TerraformValue)
+	assert.Equal(t, map[string]any{"Name": "web", "DbPassword": RedactedValue}, drifts["tags"].AWSValue)
+	assert.Equal(t, map[string]any{"Name": "web", "DbPassword": RedactedValue}, drifts["tags"].TerraformValue)
+	assert.Equal(t, RedactedValue, drifts["tags.ApiKey"].AWSValue)
+	assert.Nil(t, drifts["tags.ApiKey"].TerraformValue)
+	assert.Equal(t, "t2.micro", drifts["instance_type"].AWSValue)
+	assert.Len(t, drifts, 4)
+}
+
+func TestRedact_NestedList(t *testing.T) {
+	drifts := map[string]DriftDetail{
+		"ebs_block_device": {
+			Attribute:      "ebs_block_device",
+			InAWS:          true,
+			InTerraform:    true,
+			AWSValue:       []map[string]any{{"device_name": "/dev/sdb", "kms_key_id": "key-1"}},
+			TerraformValue: []any{map[string]any{"device_name": "/dev/sdb", "kms_key_id": "key-2"}},
+		},
+	}
+
+	Redact(drifts, []string{"ebs_block_device.*.kms_key_id"})
+
+	expected := []any{map[string]any{"device_name": "/dev/sdb", "kms_key_id": RedactedValue}}
+	assert.Equal(t, expected, drifts["ebs_block_device"].AWSValue)
+	assert.Equal(t, expected, drifts["ebs_block_device"].TerraformValue)
+}
@@ -0,0 +1,30 @@
+package drift
+
+import "sort"
+
+// ReplaceAttributes are the attributes whose drift is usually fixed by
+// replacing the instance rather than updating it in place. It is not
+// modified; callers with their own list pass it to ReplacementAttributes.
+var ReplaceAttributes = []string{
+	"ami",
+	"instance_type",
+	"availability_zone",
+	"subnet_id",
+	"tenancy",
+	"hibernation",
+	"cpu_options",
+}
+
+// ReplacementAttributes returns the drifted attributes, in order, that are
+// listed in replaceAttributes, such as ReplaceAttributes
+func ReplacementAttributes(drifts map[string]DriftDetail, replaceAttributes []string) []string {
+	var attributes []string
+	for _, attr := range replaceAttributes {
+		if _, ok := drifts[attr]; ok {
+			attributes = append(attributes, attr)
+		}
+	}
+
+	sort.Strings(attributes)
+	return attributes
+}
@@ -0,0 +1,116 @@
+package drift
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report is the drift detection outcome for a single instance. Warnings
+// describe data that could only partly be read, e.g. a volume that couldn't
+// be described. Timings is only set when the check was timed, and Metadata
+// when it was asked for.
+type Report struct {
+	InstanceID  string
+	AccountID   string
+	ARN         string
+	Drifts      []DriftDetail
+	Unchecked   []string
+	Warnings    []string
+	StartedAt   time.Time
+	CompletedAt time.Time
+	Timings     *Timings
+	Metadata    *Metadata
+	Err         error
+}
+
+// Metadata describes the instance a report is about, for context; none of
+// it is compared
+type Metadata struct {
+	AMIName          string `json:"ami_name,omitempty"`
+	LaunchTime       string `json:"launch_time,omitempty"`
+	State            string `json:"state,omitempty"`
+	AvailabilityZone string `json:"availability_zone,omitempty"`
+}
+
+// Timings records where the time of an instance check went
+type Timings struct {
+	AWSFetch   time.Duration
+	StateParse time.Duration
+	Compare    time.Duration
+}
+
+// Total is the time the check took. The AWS fetch and state parse run
+// concurrently, so only the longer of the two counts.
+func (t Timings) Total() time.Duration {
+	return max(t.AWSFetch, t.StateParse) + t.Compare
+}
+
+// NewReport builds a Report from the result of DetectDrift, ordering the
+// drifts by attribute name
+func NewReport(instanceID string, drifts map[string]DriftDetail) Report {
+	details := make([]DriftDetail, 0, len(drifts))
+	for attr, detail := range drifts {
+		if detail.Attribute == "" {
+			detail.Attribute = attr
+		}
+		details = append(details, detail)
+	}
+	sort.Slice(details, func(i, j int) bool {
+		return details[i].Attribute < details[j].Attribute
+	})
+
+	return Report{InstanceID: instanceID, Drifts: details}
+}
+
+// RemoveUnchecked removes drifts for attributes, or their nested paths, that
+// could not be read from AWS
+func RemoveUnchecked(drifts map[string]DriftDetail, unchecked []string) {
+	for attr := range drifts {
+		for _, u := range unchecked {
+			if attr == u || strings.HasPrefix(attr, u+".") {
+				delete(drifts, attr)
+				break
+			}
+		}
+	}
+}
+
+// HasDrift reports whether any attribute drifted
+func (r Report) HasDrift() bool {
+	return len(r.Drifts) > 0
+}
+
+// MaxSeverity returns the highest severity among the drifts, or "" without drift
+func (r Report) MaxSeverity() Severity {
+	var highest Severity
+	for _, detail := range r.Drifts {
+		highest = maxSeverity(highest, detail.Severity)
+	}
+	return highest
+}
+
+// TopSeverity returns a copy of the report keeping only its highest-severity
+// drift, the first by attribute name on a tie
+func (r Report) TopSeverity() Report {
+	if len(r.Drifts) == 0 {
+		return r
+	}
+	top := r.Drifts[0]
+	for _, detail := range r.Drifts[1:] {
+		if detail.Severity.Rank() > top.Severity.Rank() {
+			top = detail
+		}
+	}
+	r.Drifts = []DriftDetail{top}
+	return r
+}
+
+// DriftMap returns the drifts keyed by attribute name
+func (r Report) DriftMap() map[string]DriftDetail {
+	drifts := make(map[string]DriftDetail, len(r.Drifts))
+	for _, detail := range r.Drifts {
+		drifts[detail.Attribute] = detail
+	}
+	return drifts
+}
@@ -0,0 +1,138 @@
+package drift
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Severity ranks how important a drift is
+type Severity string
+
+const (
+	SeverityLow    Severity = "low"
+	SeverityMedium Severity = "medium"
+	SeverityHigh   Severity = "high"
+)
+
+// DefaultSeverity is assigned to attributes without an explicit classification
+const DefaultSeverity = SeverityMedium
+
+// AttributeSeverities classifies attribute paths. Numeric path segments
+// (e.g. block device indices) are matched by "*".
+var AttributeSeverities = map[string]Severity{
+	"tags":                          SeverityLow,
+	"tenancy":                       SeverityHigh,
+	"instance_lifecycle":            SeverityHigh,
+	"disable_api_termination":       SeverityHigh,
+	"architecture":                  SeverityHigh,
+	"hibernation":                   SeverityHigh,
+	"cpu_options":                   SeverityHigh,
+	"ebs_block_device.*.encrypted":  SeverityHigh,
+	"root_block_device.*.encrypted": SeverityHigh,
+	// A volume that is deleted with its instance, or outlives it, when
+	// Terraform says otherwise risks data loss or orphaned volumes
+	"ebs_block_device.*.delete_on_termination":  SeverityHigh,
+	"root_block_device.*.delete_on_termination": SeverityHigh,
+}
+
+var severityRank = map[Severity]int{
+	SeverityLow:    1,
+	SeverityMedium: 2,
+	SeverityHigh:   3,
+}
+
+// Rank orders severities so they can be compared; unknown values rank lowest
+func (s Severity) Rank() int {
+	return severityRank[s]
+}
+
+func maxSeverity(a, b Severity) Severity {
+	if b.Rank() > a.Rank() {
+		return b
+	}
+	return a
+}
+
+// lookupSeverity returns the configured severity for an attribute path
+func lookupSeverity(attr string) Severity {
+	parts := strings.Split(wildcardIndices(attr), ".")
+
+	if severity, ok := AttributeSeverities[strings.Join(parts, ".")]; ok {
+		return severity
+	}
+	if len(parts) > 1 {
+		if severity, ok := AttributeSeverities[parts[0]]; ok {
+			return severity
+		}
+	}
+	return DefaultSeverity
+}
+
+// wildcardIndices replaces the numeric segments of an attribute path (list
+// indices) with "*"
+func wildcardIndices(attr string) string {
+	parts := strings.Split(attr, ".")
+	for i, part := range parts {
+		if _, err := strconv.Atoi(part); err == nil {
+			parts[i] = "*"
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// classifySeverity determines the severity of a drifted attribute. For lists
+// of blocks such as ebs_block_device, the keys that differ within matching
+// blocks are classified too, so that e.g. a volume losing encryption is
+// flagged even when the whole list is compared as one attribute.
+func (opts Options) classifySeverity(attr string, awsValue, tfValue any) Severity {
+	severity := lookupSeverity(attr)
+
+	for _, key := range opts.differingBlockKeys(attr, awsValue, tfValue) {
+		severity = maxSeverity(severity, lookupSeverity(attr+".*."+key))
+	}
+
+	return severity
+}
+
+// differingBlockKeys matches blocks of two lists by device_name and returns
+// the keys present on both sides whose values differ
+func (opts Options) differingBlockKeys(attr string, awsValue, tfValue any) []string {
+	awsBlocks := blocksByDeviceName(awsValue)
+	tfBlocks := blocksByDeviceName(tfValue)
+
+	seen := make(map[string]bool)
+	var keys []string
+	for name, awsBlock := range awsBlocks {
+		tfBlock, ok := tfBlocks[name]
+		if !ok {
+			continue
+		}
+
+		for key, awsField := range awsBlock {
+			tfField, ok := tfBlock[key]
+			if !ok || seen[key] || opts.compareChild(attr+".*."+key, awsField, tfField) {
+				continue
+			}
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+func blocksByDeviceName(val any) map[string]map[string]any {
+	blocks := make(map[string]map[string]any)
+
+	for _, item := range childValues(val) {
+		block := childValues(item)
+		if block == nil {
+			continue
+		}
+		if name, ok := block["device_name"].(string); ok {
+			blocks[name] = block
+		}
+	}
+
+	return blocks
+}
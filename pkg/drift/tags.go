@@ -0,0 +1,116 @@
+package drift
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// TagDiff breaks down drift in a tag map into the keys only AWS has, the keys
+// only Terraform has and the keys both have with different values
+type TagDiff struct {
+	OnlyInAWS       []string `json:"only_in_aws"`
+	OnlyInTerraform []string `json:"only_in_terraform"`
+	Changed         []string `json:"changed"`
+}
+
+// diffTags compares two tag maps key by key, or returns nil if either value
+// isn't a map
+func (opts Options) diffTags(awsValue, tfValue any) *TagDiff {
+	awsTags, tfTags := childValues(awsValue), childValues(tfValue)
+	if awsTags == nil || tfTags == nil {
+		return nil
+	}
+
+	diff := &TagDiff{OnlyInAWS: []string{}, OnlyInTerraform: []string{}, Changed: []string{}}
+	for key, value := range awsTags {
+		tfTag, ok := tfTags[key]
+		switch {
+		case !ok:
+			if !opts.CompareTagsSubset {
+				diff.OnlyInAWS = append(diff.OnlyInAWS, key)
+			}
+		case tagString(value) != tagString(tfTag):
+			diff.Changed = append(diff.Changed, key)
+		}
+	}
+	for key := range tfTags {
+		if _, ok := awsTags[key]; !ok {
+			diff.OnlyInTerraform = append(diff.OnlyInTerraform, key)
+		}
+	}
+
+	sort.Strings(diff.OnlyInAWS)
+	sort.Strings(diff.OnlyInTerraform)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+// compareTags compares a tag map, or with a path such as tags.Name a single
+// tag value, as strings. AWS only has string tag values, while Terraform may
+// hold numbers or booleans (e.g. tags = { Port = 8080 }), which must not
+// look like drift.
+func (opts Options) compareTags(awsValue, tfValue any) bool {
+	awsTags, awsIsMap := tagStrings(awsValue)
+	tfTags, tfIsMap := tagStrings(tfValue)
+	if awsIsMap != tfIsMap {
+		return false
+	}
+	if !awsIsMap {
+		if awsValue == nil || tfValue == nil {
+			return awsValue == nil && tfValue == nil
+		}
+		return tagString(awsValue) == tagString(tfValue)
+	}
+
+	if opts.CompareTagsSubset {
+		for key, value := range tfTags {
+			if awsTag, ok := awsTags[key]; !ok || awsTag != value {
+				return false
+			}
+		}
+		return true
+	}
+
+	if len(awsTags) != len(tfTags) {
+		return false
+	}
+	for key, value := range awsTags {
+		if tfTag, ok := tfTags[key]; !ok || tfTag != value {
+			return false
+		}
+	}
+	return true
+}
+
+// tagStrings converts a tag map of any value type to map[string]string,
+// reporting false if the value isn't a map
+func tagStrings(v any) (map[string]string, bool) {
+	if _, isList := normalizeValue(v).([]any); isList {
+		return nil, false
+	}
+	values := childValues(v)
+	if values == nil {
+		return nil, false
+	}
+
+	tags := make(map[string]string, len(values))
+	for key, value := range values {
+		tags[key] = tagString(value)
+	}
+	return tags, true
+}
+
+// tagString formats a tag value as AWS stores it
+func tagString(v any) string {
+	switch value := normalizeValue(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,140 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+	// Pure Go, so building doesn't need CGO
+	_ "modernc.org/sqlite"
+)
+
+// timeFormat is how times are stored, which SQLite's date and time
+// functions understand and which sorts chronologically
+const timeFormat = "2006-01-02 15:04:05"
+
+// schema creates the tables on first use. Each run has one row in runs and
+// one row in drifts per drifted attribute of each instance it checked.
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at TEXT NOT NULL,
+	completed_at TEXT NOT NULL,
+	instances INTEGER NOT NULL,
+	drifted_instances INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS drifts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	run_id INTEGER NOT NULL REFERENCES runs(id),
+	instance_id TEXT NOT NULL,
+	attribute TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	severity TEXT NOT NULL,
+	aws_value TEXT,
+	terraform_value TEXT,
+	detected_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS drifts_run_id ON drifts(run_id);
+CREATE INDEX IF NOT EXISTS drifts_instance_id ON drifts(instance_id);
+`
+
+// DB records drift runs in an SQLite database for querying history
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens the SQLite database at path, creating it and its tables if
+// they don't exist
+func Open(path string) (*DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create tables in %s: %w", path, err)
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the database
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// WriteRun records a run that started at startedAt and checked the
+// instances of reports, with a drifts row per drifted attribute. The run is
+// written in one transaction, so a failed write records nothing.
+func (d *DB) WriteRun(ctx context.Context, startedAt time.Time, reports []drift.Report) (int64, error) {
+	completedAt := time.Now()
+	drifted := 0
+	for _, report := range reports {
+		if report.HasDrift() {
+			drifted++
+		}
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO runs (started_at, completed_at, instances, drifted_instances) VALUES (?, ?, ?, ?)",
+		formatTime(startedAt), formatTime(completedAt), len(reports), drifted)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record run: %w", err)
+	}
+	runID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO drifts
+		(run_id, instance_id, attribute, reason, severity, aws_value, terraform_value, detected_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	for _, report := range reports {
+		detectedAt := report.CompletedAt
+		if detectedAt.IsZero() {
+			detectedAt = completedAt
+		}
+		for _, detail := range report.Drifts {
+			_, err := stmt.ExecContext(ctx, runID, report.InstanceID, detail.Attribute, string(detail.Reason),
+				string(detail.Severity), jsonValue(detail.InAWS, detail.AWSValue),
+				jsonValue(detail.InTerraform, detail.TerraformValue), formatTime(detectedAt))
+			if err != nil {
+				return 0, fmt.Errorf("failed to record drift of instance %s: %w", report.InstanceID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return runID, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// jsonValue encodes an attribute value as JSON, or NULL where the side
+// doesn't have the attribute
+func jsonValue(present bool, value any) sql.NullString {
+	if !present {
+		return sql.NullString{}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return sql.NullString{String: fmt.Sprint(value), Valid: true}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
@@ -0,0 +1,71 @@
+package history
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "drift.db")
+	ctx := context.Background()
+	startedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	reports := []drift.Report{
+		drift.NewReport("i-web", map[string]drift.DriftDetail{
+			"instance_type": {InAWS: true, InTerraform: true, AWSValue: "t3.large", TerraformValue: "t3.micro",
+				Severity: drift.SeverityHigh, Reason: drift.ReasonValueMismatch},
+			"tags.Owner": {InAWS: true, AWSValue: "ops", Severity: drift.SeverityLow, Reason: drift.ReasonMissingInTerraform},
+		}),
+		drift.NewReport("i-db", nil),
+	}
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	runID, err := db.WriteRun(ctx, startedAt, reports)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Close())
+
+	// Reopening finds the existing tables and appends another run
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+	secondID, err := db.WriteRun(ctx, startedAt.Add(time.Hour), reports[:1])
+	assert.NoError(t, err)
+	assert.Greater(t, secondID, runID)
+
+	var started string
+	var instances, drifted int
+	err = db.db.QueryRow("SELECT started_at, instances, drifted_instances FROM runs WHERE id = ?", runID).
+		Scan(&started, &instances, &drifted)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-10-01 12:00:00", started)
+	assert.Equal(t, 2, instances)
+	assert.Equal(t, 1, drifted)
+
+	var reason, severity, awsValue string
+	var tfValue *string
+	err = db.db.QueryRow(`SELECT reason, severity, aws_value, terraform_value FROM drifts
+		WHERE run_id = ? AND attribute = 'tags.Owner'`, runID).Scan(&reason, &severity, &awsValue, &tfValue)
+	assert.NoError(t, err)
+	assert.Equal(t, "missing_in_terraform", reason)
+	assert.Equal(t, "low", severity)
+	assert.Equal(t, `"ops"`, awsValue)
+	assert.Nil(t, tfValue, "missing in Terraform is stored as NULL")
+
+	// The kind of query the history is for
+	var instanceID string
+	var count int
+	err = db.db.QueryRow(`SELECT instance_id, COUNT(*) FROM drifts JOIN runs ON runs.id = drifts.run_id
+		WHERE runs.started_at >= '2026-10-01' GROUP BY instance_id ORDER BY COUNT(*) DESC LIMIT 1`).Scan(&instanceID, &count)
+	assert.NoError(t, err)
+	assert.Equal(t, "i-web", instanceID)
+	assert.Equal(t, 4, count)
+}
@@ -24,6 +24,16 @@ var (
 		[]string{"api"},
 	)
 
+	// amiNameLookups counts AMI name resolutions by whether the cache had
+	// the name; misses are the ones that may call DescribeImages
+	amiNameLookups = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "awsterror_ami_name_lookups_total",
+			Help: "Total number of AMI name lookups, by cache hit or miss",
+		},
+		[]string{"cache"},
+	)
+
 	// Drift detection metrics
 	driftChecksTotal = promauto.NewCounter(
 		prometheus.CounterOpts{
@@ -37,7 +47,7 @@ var (
 			Name: "awsterror_drift_detected_total",
 			Help: "Total number of drifts detected",
 		},
-		[]string{"attribute"},
+		[]string{"attribute", "reason"},
 	)
 
 	driftCheckLatency = promauto.NewHistogram(
@@ -55,13 +65,47 @@ func RecordAWSAPICall(api string, status string, latency float64) {
 	awsAPILatency.WithLabelValues(api).Observe(latency)
 }
 
+// RecordAMINameLookup records an AMI name lookup and whether the cache had it
+func RecordAMINameLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	amiNameLookups.WithLabelValues(result).Inc()
+}
+
 // RecordDriftCheck records metrics for a drift check
 func RecordDriftCheck(latency float64) {
 	driftChecksTotal.Inc()
 	driftCheckLatency.Observe(latency)
 }
 
-// RecordDriftDetected records a detected drift for a specific attribute
-func RecordDriftDetected(attribute string) {
-	driftDetectedTotal.WithLabelValues(attribute).Inc()
-}
\ No newline at end of file
+// RecordDriftDetected records a detected drift for a specific attribute and
+// the reason it drifted (value_mismatch, missing_in_aws, ...)
+func RecordDriftDetected(attribute, reason string) {
+	driftDetectedTotal.WithLabelValues(attribute, reason).Inc()
+}
+
+// AWSAPICallCounts returns how many AWS API calls have been recorded, and
+// how many of them failed
+func AWSAPICallCounts() (total, failed int) {
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		return 0, 0
+	}
+	for _, family := range families {
+		if family.GetName() != "awsterror_aws_api_calls_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			count := int(metric.GetCounter().GetValue())
+			total += count
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "status" && label.GetValue() == "error" {
+					failed += count
+				}
+			}
+		}
+	}
+	return total, failed
+}
@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAWSAPICallCounts(t *testing.T) {
+	// Counters are global, so only the change is checked
+	total, errors := AWSAPICallCounts()
+
+	RecordAWSAPICall("DescribeInstances", "success", 0.1)
+	RecordAWSAPICall("DescribeVolumes", "success", 0.1)
+	RecordAWSAPICall("DescribeVolumes", "error", 0.1)
+
+	newTotal, newErrors := AWSAPICallCounts()
+	assert.Equal(t, 3, newTotal-total)
+	assert.Equal(t, 1, newErrors-errors)
+}
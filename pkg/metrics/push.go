@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// Push sends the collected metrics to a Prometheus Pushgateway under job,
+// replacing the metrics previously pushed for it. Short-lived runs, like
+// cron jobs, can't be scraped, so they push once on completion instead.
+func Push(ctx context.Context, url, job string) error {
+	return push.New(url, job).
+		Collector(awsAPICallsTotal).
+		Collector(awsAPILatency).
+		Collector(amiNameLookups).
+		Collector(driftChecksTotal).
+		Collector(driftDetectedTotal).
+		Collector(driftCheckLatency).
+		PushContext(ctx)
+}
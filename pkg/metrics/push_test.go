@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPush(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	RecordDriftCheck(0.5)
+	RecordDriftDetected("instance_type", "value_mismatch")
+	RecordAWSAPICall("DescribeInstances", "success", 0.1)
+	RecordAMINameLookup(true)
+
+	err := Push(context.Background(), server.URL, "nightly-drift")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/metrics/job/nightly-drift", path)
+	assert.Contains(t, body, "awsterror_drift_checks_total")
+	assert.Contains(t, body, "awsterror_drift_detected_total")
+	assert.Contains(t, body, "value_mismatch")
+	assert.Contains(t, body, "awsterror_aws_api_latency_seconds")
+	assert.Contains(t, body, "awsterror_ami_name_lookups_total")
+}
+
+func TestPush_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	assert.Error(t, Push(context.Background(), server.URL, "aws-terror"))
+}
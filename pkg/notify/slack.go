@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+const maxSlackAttributes = 5
+
+// Slack posts a concise drift summary to a Slack incoming webhook
+type Slack struct {
+	webhook *Webhook
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Fallback string       `json:"fallback"`
+	Color    string       `json:"color"`
+	Title    string       `json:"title"`
+	Fields   []slackField `json:"fields"`
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// NewSlack creates a Slack notifier for the given incoming webhook URL
+func NewSlack(url string, timeout time.Duration) *Slack {
+	return &Slack{webhook: NewWebhook(url, timeout)}
+}
+
+// Notify posts a message for the instance if any drift was found
+func (s *Slack) Notify(ctx context.Context, instanceID string, drifts map[string]drift.DriftDetail) error {
+	if len(drifts) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(buildSlackMessage(instanceID, drifts))
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+
+	return s.webhook.Send(ctx, payload)
+}
+
+func buildSlackMessage(instanceID string, drifts map[string]drift.DriftDetail) slackMessage {
+	attributes := make([]string, 0, len(drifts))
+	for attr := range drifts {
+		attributes = append(attributes, attr)
+	}
+	// The most severe drift is listed first
+	sort.Slice(attributes, func(i, j int) bool {
+		ri, rj := drifts[attributes[i]].Severity.Rank(), drifts[attributes[j]].Severity.Rank()
+		if ri != rj {
+			return ri > rj
+		}
+		return attributes[i] < attributes[j]
+	})
+
+	top := attributes
+	if len(top) > maxSlackAttributes {
+		top = top[:maxSlackAttributes]
+	}
+	topValue := strings.Join(top, ", ")
+	if len(attributes) > maxSlackAttributes {
+		topValue += fmt.Sprintf(" (+%d more)", len(attributes)-maxSlackAttributes)
+	}
+
+	summary := fmt.Sprintf("Drift detected on %s in %d attributes", instanceID, len(drifts))
+
+	return slackMessage{
+		Text: ":warning: " + summary,
+		Attachments: []slackAttachment{
+			{
+				Fallback: summary,
+				Color:    "warning",
+				Title:    "AWS-Terror drift report",
+				Fields: []slackField{
+					{Title: "Instance", Value: instanceID, Short: true},
+					{Title: "Drifted attributes", Value: fmt.Sprintf("%d", len(drifts)), Short: true},
+					{Title: "Attributes", Value: topValue},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSlackMessage(t *testing.T) {
+	drifts := map[string]drift.DriftDetail{
+		"tags":          {Attribute: "tags"},
+		"instance_type": {Attribute: "instance_type"},
+		"ami":           {Attribute: "ami"},
+		"subnet_id":     {Attribute: "subnet_id"},
+		"monitoring":    {Attribute: "monitoring"},
+		"ebs_optimized": {Attribute: "ebs_optimized"},
+	}
+
+	msg := buildSlackMessage("i-12345", drifts)
+
+	assert.Contains(t, msg.Text, "i-12345")
+	assert.Len(t, msg.Attachments, 1)
+	fields := msg.Attachments[0].Fields
+	assert.Equal(t, "i-12345", fields[0].Value)
+	assert.Equal(t, "6", fields[1].Value)
+	assert.Equal(t, "ami, ebs_optimized, instance_type, monitoring, subnet_id (+1 more)", fields[2].Value)
+}
+
+func TestBuildSlackMessage_MostSevereFirst(t *testing.T) {
+	drifts := map[string]drift.DriftDetail{
+		"ami":                    {Attribute: "ami", Severity: drift.SeverityLow},
+		"ebs_optimized":          {Attribute: "ebs_optimized", Severity: drift.SeverityLow},
+		"instance_type":          {Attribute: "instance_type", Severity: drift.SeverityMedium},
+		"monitoring":             {Attribute: "monitoring", Severity: drift.SeverityLow},
+		"subnet_id":              {Attribute: "subnet_id", Severity: drift.SeverityLow},
+		"vpc_security_group_ids": {Attribute: "vpc_security_group_ids", Severity: drift.SeverityHigh},
+		"tags":                   {Attribute: "tags", Severity: drift.SeverityHigh},
+	}
+
+	msg := buildSlackMessage("i-12345", drifts)
+
+	assert.Equal(t, "tags, vpc_security_group_ids, instance_type, ami, ebs_optimized (+2 more)", msg.Attachments[0].Fields[2].Value)
+}
+
+func TestSlackNotify_SkipsWhenNoDrift(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	slack := NewSlack(server.URL, 5*time.Second)
+	err := slack.Notify(context.Background(), "i-12345", map[string]drift.DriftDetail{})
+
+	assert.NoError(t, err)
+	assert.False(t, called)
+}
+
+func TestSlackNotify_PostsMessage(t *testing.T) {
+	var msg slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&msg)
+	}))
+	defer server.Close()
+
+	slack := NewSlack(server.URL, 5*time.Second)
+	err := slack.Notify(context.Background(), "i-12345", map[string]drift.DriftDetail{
+		"ami": {Attribute: "ami"},
+	})
+
+	assert.NoError(t, err)
+	assert.Contains(t, msg.Text, "Drift detected on i-12345 in 1 attributes")
+}
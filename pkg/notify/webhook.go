@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+const defaultMaxRetries = 3
+
+// Webhook posts drift reports to an HTTP endpoint
+type Webhook struct {
+	url        string
+	httpClient *http.Client
+	maxRetries uint64
+}
+
+// NewWebhook creates a webhook that posts to url, giving up on a single
+// request after timeout
+func NewWebhook(url string, timeout time.Duration) *Webhook {
+	return &Webhook{
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
+		maxRetries: defaultMaxRetries,
+	}
+}
+
+// Send POSTs the JSON payload to the webhook, retrying on network errors
+// and 5xx responses
+func (w *Webhook) Send(ctx context.Context, payload []byte) error {
+	operation := func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("failed to create webhook request: %w", err))
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := w.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("webhook request failed: %w", err)
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		}
+		if resp.StatusCode >= 300 {
+			return backoff.Permanent(fmt.Errorf("webhook returned status %d", resp.StatusCode))
+		}
+		return nil
+	}
+
+	backoffConfig := backoff.WithContext(
+		backoff.WithMaxRetries(backoff.NewExponentialBackOff(), w.maxRetries), ctx)
+
+	return backoff.Retry(operation, backoffConfig)
+}
@@ -0,0 +1,15 @@
+package output
+
+import "fmt"
+
+// FormatAPICalls summarizes the AWS API calls a run made, e.g.
+// "AWS API calls: 143 (2 errors)"
+func FormatAPICalls(total, failed int) string {
+	summary := fmt.Sprintf("AWS API calls: %d", total)
+	if failed == 1 {
+		summary += " (1 error)"
+	} else if failed > 1 {
+		summary += fmt.Sprintf(" (%d errors)", failed)
+	}
+	return summary
+}
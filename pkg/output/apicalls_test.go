@@ -0,0 +1,13 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAPICalls(t *testing.T) {
+	assert.Equal(t, "AWS API calls: 143 (2 errors)", FormatAPICalls(143, 2))
+	assert.Equal(t, "AWS API calls: 5 (1 error)", FormatAPICalls(5, 1))
+	assert.Equal(t, "AWS API calls: 12", FormatAPICalls(12, 0))
+}
@@ -0,0 +1,136 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FormatConfig renders a flattened attribute map for a single instance, as
+// fetched from AWS or parsed from Terraform, in the requested format
+func FormatConfig(config map[string]any, instanceID, format string) string {
+	switch strings.ToLower(format) {
+	case "json":
+		return formatConfigJSON(config, instanceID)
+	case "yaml":
+		return formatConfigYAML(config, instanceID)
+	default:
+		return formatConfigText(config, instanceID)
+	}
+}
+
+func formatConfigText(config map[string]any, instanceID string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Configuration for EC2 Instance: %s\n\n", instanceID))
+	for _, key := range sortedKeys(config) {
+		sb.WriteString(fmt.Sprintf("%s: %v\n", key, derefValue(config[key])))
+	}
+
+	return sb.String()
+}
+
+func formatConfigJSON(config map[string]any, instanceID string) string {
+	result := struct {
+		InstanceID string         `json:"instance_id"`
+		Attributes map[string]any `json:"attributes"`
+	}{
+		InstanceID: instanceID,
+		Attributes: config,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("Error formatting JSON: %v", err)
+	}
+
+	return string(jsonData)
+}
+
+func formatConfigYAML(config map[string]any, instanceID string) string {
+	return formatYAMLDocument(yamlMap{
+		{"instance_id", instanceID},
+		{"attributes", jsonValue(config)},
+	})
+}
+
+// yamlMap is a YAML mapping that keeps the order of its entries
+type yamlMap []yamlEntry
+
+type yamlEntry struct {
+	key   string
+	value any
+}
+
+func (m yamlMap) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, entry := range m {
+		var value yaml.Node
+		if err := value.Encode(entry.value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: entry.key}, &value)
+	}
+	return node, nil
+}
+
+// formatYAMLDocument encodes a document with two-space indentation
+func formatYAMLDocument(document yamlMap) string {
+	var sb strings.Builder
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Sprintf("Error formatting YAML: %v", err)
+	}
+	encoder.Close()
+	return sb.String()
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toAnyMap converts any map keyed by strings (e.g. map[string]string) to a
+// map[string]any
+func toAnyMap(val any) (map[string]any, bool) {
+	if m, ok := val.(map[string]any); ok {
+		return m, true
+	}
+
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+
+	result := make(map[string]any, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		result[iter.Key().String()] = iter.Value().Interface()
+	}
+	return result, true
+}
+
+// derefValue follows pointers such as the *int32 and *bool fields returned
+// by the AWS SDK so the underlying value is printed
+func derefValue(val any) any {
+	rv := reflect.ValueOf(val)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	return rv.Interface()
+}
@@ -0,0 +1,60 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatConfig_TextFormat(t *testing.T) {
+	config := map[string]any{
+		"instance_type": "t2.micro",
+		"ami":           "ami-12345",
+	}
+
+	result := FormatConfig(config, "i-12345", "text")
+
+	assert.Contains(t, result, "Configuration for EC2 Instance: i-12345")
+	assert.Contains(t, result, "ami: ami-12345\ninstance_type: t2.micro\n")
+}
+
+func TestFormatConfig_JsonFormat(t *testing.T) {
+	config := map[string]any{
+		"instance_type": "t2.micro",
+		"tags":          map[string]string{"Name": "test-instance"},
+	}
+
+	result := FormatConfig(config, "i-12345", "json")
+
+	var parsed map[string]any
+	err := json.Unmarshal([]byte(result), &parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, "i-12345", parsed["instance_id"])
+	attributes := parsed["attributes"].(map[string]any)
+	assert.Equal(t, map[string]any{"Name": "test-instance"}, attributes["tags"])
+}
+
+func TestFormatConfig_YamlFormat(t *testing.T) {
+	size := int32(8)
+	config := map[string]any{
+		"instance_type": "t2.micro",
+		"tags":          map[string]string{"Name": "test-instance"},
+		"ebs_block_device": []map[string]any{
+			{"device_name": "/dev/sdf", "volume_size": &size},
+		},
+	}
+
+	result := FormatConfig(config, "i-12345", "yaml")
+
+	expected := `instance_id: i-12345
+attributes:
+  ebs_block_device:
+    - device_name: /dev/sdf
+      volume_size: 8
+  instance_type: t2.micro
+  tags:
+    Name: test-instance
+`
+	assert.Equal(t, expected, result)
+}
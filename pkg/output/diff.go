@@ -0,0 +1,90 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+func formatDiff(report drift.Report, contextLines int) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Drift diff for EC2 Instance: %s\n", report.InstanceID))
+	sb.WriteString("--- terraform\n")
+	sb.WriteString("+++ aws\n")
+
+	for _, detail := range report.Drifts {
+		sb.WriteString(fmt.Sprintf("@@ %s @@\n", detail.Attribute))
+
+		tfMap, tfIsMap := toAnyMap(detail.TerraformValue)
+		awsMap, awsIsMap := toAnyMap(detail.AWSValue)
+		if tfIsMap && awsIsMap {
+			writeMapDiff(&sb, tfMap, awsMap, contextLines)
+			continue
+		}
+
+		if detail.InTerraform {
+			sb.WriteString(fmt.Sprintf("- %v\n", derefValue(detail.TerraformValue)))
+		}
+		if detail.InAWS {
+			sb.WriteString(fmt.Sprintf("+ %v\n", derefValue(detail.AWSValue)))
+		}
+	}
+
+	return sb.String()
+}
+
+// writeMapDiff writes the changed keys of two maps, with up to contextLines
+// unchanged keys before and after each change
+func writeMapDiff(sb *strings.Builder, tfMap, awsMap map[string]any, contextLines int) {
+	keySet := make(map[string]any, len(tfMap)+len(awsMap))
+	for k := range tfMap {
+		keySet[k] = nil
+	}
+	for k := range awsMap {
+		keySet[k] = nil
+	}
+	keys := sortedKeys(keySet)
+
+	changed := make([]bool, len(keys))
+	for i, k := range keys {
+		tfVal, inTF := tfMap[k]
+		awsVal, inAWS := awsMap[k]
+		changed[i] = inTF != inAWS ||
+			fmt.Sprintf("%v", derefValue(tfVal)) != fmt.Sprintf("%v", derefValue(awsVal))
+	}
+
+	lastWritten := -1
+	for i, k := range keys {
+		if !withinContext(changed, i, contextLines) {
+			continue
+		}
+		if lastWritten >= 0 && i > lastWritten+1 {
+			sb.WriteString("  ...\n")
+		}
+		lastWritten = i
+
+		tfVal, inTF := tfMap[k]
+		awsVal, inAWS := awsMap[k]
+		if !changed[i] {
+			sb.WriteString(fmt.Sprintf("  %s: %v\n", k, derefValue(tfVal)))
+			continue
+		}
+		if inTF {
+			sb.WriteString(fmt.Sprintf("- %s: %v\n", k, derefValue(tfVal)))
+		}
+		if inAWS {
+			sb.WriteString(fmt.Sprintf("+ %s: %v\n", k, derefValue(awsVal)))
+		}
+	}
+}
+
+func withinContext(changed []bool, i, contextLines int) bool {
+	for j := i - contextLines; j <= i+contextLines; j++ {
+		if j >= 0 && j < len(changed) && changed[j] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDiff(t *testing.T) {
+	drifts := map[string]drift.DriftDetail{
+		"instance_type": {
+			Attribute:      "instance_type",
+			InAWS:          true,
+			InTerraform:    true,
+			AWSValue:       "t2.micro",
+			TerraformValue: "t2.small",
+		},
+		"tags": {
+			Attribute:   "tags",
+			InAWS:       true,
+			InTerraform: true,
+			AWSValue: map[string]string{
+				"A": "1", "B": "2", "C": "changed", "D": "4", "E": "5",
+			},
+			TerraformValue: map[string]any{
+				"A": "1", "B": "2", "C": "3", "D": "4", "E": "5",
+			},
+		},
+	}
+
+	report := drift.NewReport("i-12345", drifts)
+
+	result := FormatReport(report, "diff", Options{})
+	assert.Contains(t, result, "@@ instance_type @@\n- t2.small\n+ t2.micro\n")
+	assert.Contains(t, result, "@@ tags @@\n- C: 3\n+ C: changed\n")
+	assert.NotContains(t, result, "B: 2")
+
+	result = FormatReport(report, "diff", Options{ContextLines: 1})
+	assert.Contains(t, result, "@@ tags @@\n  B: 2\n- C: 3\n+ C: changed\n  D: 4\n")
+	assert.NotContains(t, result, "A: 1")
+}
@@ -3,39 +3,154 @@ package output
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/katungi/aws-terror/pkg/drift"
 )
 
+// SchemaVersion is the version of the JSON and YAML report structure, written
+// as schema_version. It is bumped whenever a field is renamed, removed or
+// changes meaning, so consumers can tell which structure they are reading.
+const SchemaVersion = 2
+
+// FormatDriftResults formats the drift map returned by DetectDrift. It is a
+// shim over FormatReport for callers that don't track timestamps or errors.
 func FormatDriftResults(drifts map[string]drift.DriftDetail, instanceID, format string) string {
+	return FormatReport(drift.NewReport(instanceID, drifts), format, Options{})
+}
+
+// Options configures how reports are formatted
+type Options struct {
+	// ContextLines is the number of unchanged map keys shown around each
+	// changed key in the diff output, like diff -U. Zero shows only changes.
+	ContextLines int
+}
+
+// FormatReport formats a drift report as text, json, yaml or diff
+func FormatReport(report drift.Report, format string, opts Options) string {
+	switch strings.ToLower(format) {
+	case "json":
+		return formatJSON(report)
+	case "yaml":
+		return formatYAML(report)
+	case "diff":
+		return formatDiff(report, opts.ContextLines)
+	case "wide", "table":
+		return FormatTable([]drift.Report{report}, 0)
+	default:
+		return formatText(report)
+	}
+}
+
+// Now returns the detection time for reports built without timestamps.
+// Tests can replace it to freeze time_detected.
+var Now = time.Now
+
+// completedAt returns when the report was completed, defaulting to Now for
+// reports built without timestamps
+func completedAt(report drift.Report) time.Time {
+	if report.CompletedAt.IsZero() {
+		return Now()
+	}
+	return report.CompletedAt
+}
+
+// FileExtension returns the file extension for reports in the given format
+func FileExtension(format string) string {
 	switch strings.ToLower(format) {
 	case "json":
-		return formatJSON(drifts, instanceID)
+		return "json"
 	case "yaml":
-		return formatYAML(drifts, instanceID)
+		return "yaml"
+	case "diff":
+		return "diff"
 	default:
-		return formatText(drifts, instanceID)
+		return "txt"
+	}
+}
+
+// FormatDriftedAttributes lists only the names of the drifted attributes,
+// one per line or as a JSON array
+func FormatDriftedAttributes(drifts map[string]drift.DriftDetail, format string) string {
+	attributes := make([]string, 0, len(drifts))
+	for attr := range drifts {
+		attributes = append(attributes, attr)
+	}
+	sort.Strings(attributes)
+
+	if strings.ToLower(format) == "json" {
+		jsonData, err := json.Marshal(attributes)
+		if err != nil {
+			return fmt.Sprintf("Error formatting JSON: %v", err)
+		}
+		return string(jsonData)
 	}
+
+	return strings.Join(attributes, "\n")
 }
 
-func formatText(drifts map[string]drift.DriftDetail, instanceID string) string {
+func formatText(report drift.Report) string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("Drift Detection Results for EC2 Instance: %s\n\n", instanceID))
+	sb.WriteString(fmt.Sprintf("Drift Detection Results for EC2 Instance: %s\n", report.InstanceID))
+	if report.AccountID != "" {
+		sb.WriteString(fmt.Sprintf("Account: %s\n", report.AccountID))
+	}
+	if report.ARN != "" {
+		sb.WriteString(fmt.Sprintf("ARN: %s\n", report.ARN))
+	}
+	if metadata := report.Metadata; metadata != nil {
+		for _, field := range []struct{ label, value string }{
+			{"AMI name", metadata.AMIName},
+			{"Launched", metadata.LaunchTime},
+			{"State", metadata.State},
+			{"Availability zone", metadata.AvailabilityZone},
+		} {
+			if field.value != "" {
+				sb.WriteString(fmt.Sprintf("%s: %s\n", field.label, field.value))
+			}
+		}
+	}
+	sb.WriteString("\n")
+
+	if report.Err != nil {
+		sb.WriteString(fmt.Sprintf("Error: %v\n", report.Err))
+		return sb.String()
+	}
+
+	if len(report.Unchecked) > 0 {
+		sb.WriteString(fmt.Sprintf("Unchecked (could not be read from AWS): %s\n\n", strings.Join(report.Unchecked, ", ")))
+	}
+
+	if len(report.Warnings) > 0 {
+		sb.WriteString("Warnings:\n")
+		for _, warning := range report.Warnings {
+			sb.WriteString(fmt.Sprintf("  - %s\n", warning))
+		}
+		sb.WriteString("\n")
+	}
 
-	if len(drifts) == 0 {
+	if !report.HasDrift() {
 		sb.WriteString("No configuration drift detected! AWS and Terraform configurations are in sync.\n")
 		return sb.String()
 	}
 
-	sb.WriteString(fmt.Sprintf("Found %d attributes with configuration drift:\n\n", len(drifts)))
+	sb.WriteString(fmt.Sprintf("Found %d attributes with configuration drift:\n\n", len(report.Drifts)))
 
-	for _, detail := range drifts {
+	for _, detail := range report.Drifts {
 		sb.WriteString(fmt.Sprintf("--- %s ---\n", detail.Attribute))
+		if detail.Severity != "" {
+			sb.WriteString(fmt.Sprintf("Severity: %s\n", detail.Severity))
+		}
 
-		if detail.InAWS && detail.InTerraform {
+		if detail.TagDiff != nil {
+			sb.WriteString("Status: Tags differ between AWS and Terraform\n")
+			writeTagDiff(&sb, detail)
+		} else if detail.InAWS && detail.InTerraform {
 			sb.WriteString("Status: Values differ between AWS and Terraform\n")
 			sb.WriteString(fmt.Sprintf("AWS value: %v\n", detail.AWSValue))
 			sb.WriteString(fmt.Sprintf("Terraform value: %v\n", detail.TerraformValue))
@@ -50,25 +165,54 @@ func formatText(drifts map[string]drift.DriftDetail, instanceID string) string {
 		sb.WriteString("\n")
 	}
 
-	sb.WriteString(fmt.Sprintf("\nDetection completed at: %s\n", time.Now().Format(time.RFC1123)))
+	sb.WriteString(fmt.Sprintf("\nDetection completed at: %s\n", completedAt(report).Format(time.RFC1123)))
 	return sb.String()
 }
 
-func formatJSON(drifts map[string]drift.DriftDetail, instanceID string) string {
+func formatJSON(report drift.Report) string {
 	type jsonResult struct {
-		InstanceID   string                       `json:"instance_id"`
-		DriftFound   bool                         `json:"drift_found"`
-		DriftCount   int                          `json:"drift_count"`
-		Drifts       map[string]drift.DriftDetail `json:"drifts"`
-		TimeDetected string                       `json:"time_detected"`
+		SchemaVersion int                          `json:"schema_version"`
+		InstanceID    string                       `json:"instance_id"`
+		AccountID     string                       `json:"account_id,omitempty"`
+		ARN           string                       `json:"arn,omitempty"`
+		DriftFound    bool                         `json:"drift_found"`
+		DriftCount    int                          `json:"drift_count"`
+		Drifts        map[string]drift.DriftDetail `json:"drifts"`
+		Unchecked     []string                     `json:"unchecked,omitempty"`
+		Warnings      []string                     `json:"warnings,omitempty"`
+		StartedAt     string                       `json:"started_at,omitempty"`
+		TimeDetected  string                       `json:"time_detected"`
+		Timings       map[string]float64           `json:"timings_ms,omitempty"`
+		Metadata      *drift.Metadata              `json:"metadata,omitempty"`
+		Error         string                       `json:"error,omitempty"`
 	}
 
 	result := jsonResult{
-		InstanceID:   instanceID,
-		DriftFound:   len(drifts) > 0,
-		DriftCount:   len(drifts),
-		Drifts:       drifts,
-		TimeDetected: time.Now().Format(time.RFC3339),
+		SchemaVersion: SchemaVersion,
+		InstanceID:    report.InstanceID,
+		AccountID:     report.AccountID,
+		ARN:           report.ARN,
+		DriftFound:    report.HasDrift(),
+		DriftCount:    len(report.Drifts),
+		Drifts:        make(map[string]drift.DriftDetail, len(report.Drifts)),
+		Unchecked:     report.Unchecked,
+		Warnings:      report.Warnings,
+		TimeDetected:  completedAt(report).Format(time.RFC3339),
+		Metadata:      report.Metadata,
+	}
+	if !report.StartedAt.IsZero() {
+		result.StartedAt = report.StartedAt.Format(time.RFC3339)
+	}
+	if report.Timings != nil {
+		result.Timings = timingsMillis(*report.Timings)
+	}
+	if report.Err != nil {
+		result.Error = report.Err.Error()
+	}
+	for _, detail := range report.Drifts {
+		detail.AWSValue = jsonValue(detail.AWSValue)
+		detail.TerraformValue = jsonValue(detail.TerraformValue)
+		result.Drifts[detail.Attribute] = detail
 	}
 
 	jsonData, err := json.MarshalIndent(result, "", "  ")
@@ -79,31 +223,192 @@ func formatJSON(drifts map[string]drift.DriftDetail, instanceID string) string {
 	return string(jsonData)
 }
 
-func formatYAML(drifts map[string]drift.DriftDetail, instanceID string) string {
-	var sb strings.Builder
+// writeTagDiff lists the tags only in AWS, only in Terraform and with
+// different values, instead of printing both tag maps
+func writeTagDiff(sb *strings.Builder, detail drift.DriftDetail) {
+	awsTag := tagValue(detail.AWSValue)
+	tfTag := tagValue(detail.TerraformValue)
 
-	sb.WriteString(fmt.Sprintf("instance_id: %s\n", instanceID))
-	sb.WriteString(fmt.Sprintf("drift_found: %t\n", len(drifts) > 0))
-	sb.WriteString(fmt.Sprintf("drift_count: %d\n", len(drifts)))
-	sb.WriteString(fmt.Sprintf("time_detected: %s\n", time.Now().Format(time.RFC3339)))
+	tagList := func(keys []string, tag func(string) any) string {
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = fmt.Sprintf("%s=%v", key, tag(key))
+		}
+		return strings.Join(items, ", ")
+	}
 
-	if len(drifts) > 0 {
-		sb.WriteString("drifts:\n")
+	if len(detail.TagDiff.OnlyInAWS) > 0 {
+		sb.WriteString(fmt.Sprintf("Only in AWS: %s\n", tagList(detail.TagDiff.OnlyInAWS, awsTag)))
+	}
+	if len(detail.TagDiff.OnlyInTerraform) > 0 {
+		sb.WriteString(fmt.Sprintf("Only in Terraform: %s\n", tagList(detail.TagDiff.OnlyInTerraform, tfTag)))
+	}
+	if len(detail.TagDiff.Changed) > 0 {
+		items := make([]string, len(detail.TagDiff.Changed))
+		for i, key := range detail.TagDiff.Changed {
+			items[i] = fmt.Sprintf("%s (AWS: %v, Terraform: %v)", key, awsTag(key), tfTag(key))
+		}
+		sb.WriteString(fmt.Sprintf("Changed: %s\n", strings.Join(items, ", ")))
+	}
+}
 
-		for attr, detail := range drifts {
-			sb.WriteString(fmt.Sprintf("  %s:\n", attr))
-			sb.WriteString(fmt.Sprintf("    in_aws: %t\n", detail.InAWS))
-			sb.WriteString(fmt.Sprintf("    in_terraform: %t\n", detail.InTerraform))
+// tagValue returns a func looking up tag values in a tag map. A value that is
+// no longer a map, because --redact masked the whole tags attribute, is
+// printed for every tag.
+func tagValue(value any) func(string) any {
+	tags, ok := toAnyMap(value)
+	if !ok {
+		return func(string) any { return value }
+	}
+	return func(key string) any { return tags[key] }
+}
 
-			if detail.InAWS {
-				sb.WriteString(fmt.Sprintf("    aws_value: \"%v\"\n", detail.AWSValue))
+// jsonValue converts a drift value into plain JSON types, keeping nested maps
+// and lists as objects and arrays and HCL numbers (*big.Float) as numbers
+func jsonValue(v any) any {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case *big.Float:
+		if val == nil {
+			return nil
+		}
+		f, _ := val.Float64()
+		return f
+	case map[string]any:
+		result := make(map[string]any, len(val))
+		for key, item := range val {
+			result[key] = jsonValue(item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(val))
+		for i, item := range val {
+			result[i] = jsonValue(item)
+		}
+		return result
+	}
+
+	// Other maps and slices, e.g. []map[string]any from the AWS client, and
+	// pointers such as the *int32 fields of AWS types
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return jsonValue(rv.Elem().Interface())
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return v
+		}
+		result := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			result[iter.Key().String()] = jsonValue(iter.Value().Interface())
+		}
+		return result
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		result := make([]any, rv.Len())
+		for i := range result {
+			result[i] = jsonValue(rv.Index(i).Interface())
+		}
+		return result
+	}
+	return v
+}
+
+func formatYAML(report drift.Report) string {
+	document := yamlMap{
+		{"schema_version", SchemaVersion},
+		{"instance_id", report.InstanceID},
+	}
+	if report.AccountID != "" {
+		document = append(document, yamlEntry{"account_id", report.AccountID})
+	}
+	if report.ARN != "" {
+		document = append(document, yamlEntry{"arn", report.ARN})
+	}
+	document = append(document,
+		yamlEntry{"drift_found", report.HasDrift()},
+		yamlEntry{"drift_count", len(report.Drifts)},
+	)
+	if !report.StartedAt.IsZero() {
+		document = append(document, yamlEntry{"started_at", report.StartedAt.Truncate(time.Second)})
+	}
+	document = append(document, yamlEntry{"time_detected", completedAt(report).Truncate(time.Second)})
+	if report.Timings != nil {
+		timings := yamlMap{}
+		for _, phase := range timingPhases {
+			timings = append(timings, yamlEntry{phase, timingsMillis(*report.Timings)[phase]})
+		}
+		document = append(document, yamlEntry{"timings_ms", timings})
+	}
+	if metadata := report.Metadata; metadata != nil {
+		fields := yamlMap{}
+		for _, field := range []yamlEntry{
+			{"ami_name", metadata.AMIName},
+			{"launch_time", metadata.LaunchTime},
+			{"state", metadata.State},
+			{"availability_zone", metadata.AvailabilityZone},
+		} {
+			if field.value != "" {
+				fields = append(fields, field)
 			}
+		}
+		document = append(document, yamlEntry{"metadata", fields})
+	}
+	if report.Err != nil {
+		document = append(document, yamlEntry{"error", report.Err.Error()})
+	}
+	if len(report.Unchecked) > 0 {
+		document = append(document, yamlEntry{"unchecked", report.Unchecked})
+	}
+	if len(report.Warnings) > 0 {
+		document = append(document, yamlEntry{"warnings", report.Warnings})
+	}
 
+	if report.HasDrift() {
+		drifts := yamlMap{}
+		for _, detail := range report.Drifts {
+			fields := yamlMap{
+				{"in_aws", detail.InAWS},
+				{"in_terraform", detail.InTerraform},
+			}
+			if detail.Severity != "" {
+				fields = append(fields, yamlEntry{"severity", detail.Severity})
+			}
+			if detail.Reason != "" {
+				fields = append(fields, yamlEntry{"reason", detail.Reason})
+			}
+			if detail.InAWS {
+				fields = append(fields, yamlEntry{"aws_value", jsonValue(detail.AWSValue)})
+			}
 			if detail.InTerraform {
-				sb.WriteString(fmt.Sprintf("    terraform_value: \"%v\"\n", detail.TerraformValue))
+				fields = append(fields, yamlEntry{"terraform_value", jsonValue(detail.TerraformValue)})
 			}
+			if detail.TagDiff != nil {
+				fields = append(fields,
+					yamlEntry{"only_in_aws", nonNil(detail.TagDiff.OnlyInAWS)},
+					yamlEntry{"only_in_terraform", nonNil(detail.TagDiff.OnlyInTerraform)},
+					yamlEntry{"changed", nonNil(detail.TagDiff.Changed)},
+				)
+			}
+			drifts = append(drifts, yamlEntry{detail.Attribute, fields})
 		}
+		document = append(document, yamlEntry{"drifts", drifts})
 	}
 
-	return sb.String()
+	return formatYAMLDocument(document)
+}
+
+// nonNil returns an empty list for nil, so it is written as []
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
 }
@@ -2,8 +2,11 @@ package output
 
 import (
 	"encoding/json"
+	"errors"
+	"math/big"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/katungi/aws-terror/pkg/drift"
 	"github.com/stretchr/testify/assert"
@@ -63,6 +66,38 @@ func TestFormatDriftResults_JsonFormat(t *testing.T) {
 	assert.Equal(t, float64(1), jsonData["drift_count"])
 }
 
+func TestFormatReport_JSONNestedValues(t *testing.T) {
+	report := drift.NewReport("i-12345", map[string]drift.DriftDetail{
+		"tags": {
+			Attribute:      "tags",
+			InAWS:          true,
+			InTerraform:    true,
+			AWSValue:       map[string]string{"Name": "web", "Environment": "dev"},
+			TerraformValue: map[string]any{"Name": "web", "Environment": "prod"},
+		},
+		"ebs_block_device": {
+			Attribute:      "ebs_block_device",
+			InAWS:          true,
+			InTerraform:    true,
+			AWSValue:       []map[string]any{{"volume_size": int32(8)}},
+			TerraformValue: []any{map[string]any{"volume_size": big.NewFloat(10)}},
+		},
+	})
+
+	var result struct {
+		Drifts map[string]struct {
+			AWSValue       any
+			TerraformValue any
+		} `json:"drifts"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(FormatReport(report, "json", Options{})), &result))
+
+	assert.Equal(t, map[string]any{"Name": "web", "Environment": "dev"}, result.Drifts["tags"].AWSValue)
+	assert.Equal(t, map[string]any{"Name": "web", "Environment": "prod"}, result.Drifts["tags"].TerraformValue)
+	assert.Equal(t, []any{map[string]any{"volume_size": float64(8)}}, result.Drifts["ebs_block_device"].AWSValue)
+	assert.Equal(t, []any{map[string]any{"volume_size": float64(10)}}, result.Drifts["ebs_block_device"].TerraformValue)
+}
+
 func TestFormatDriftResults_YamlFormat(t *testing.T) {
 	// Create test drift data
 	drifts := map[string]drift.DriftDetail{
@@ -84,8 +119,8 @@ func TestFormatDriftResults_YamlFormat(t *testing.T) {
 	assert.True(t, strings.Contains(result, "  instance_type:"))
 	assert.True(t, strings.Contains(result, "    in_aws: true"))
 	assert.True(t, strings.Contains(result, "    in_terraform: true"))
-	assert.True(t, strings.Contains(result, "    aws_value: \"t2.micro\""))
-	assert.True(t, strings.Contains(result, "    terraform_value: \"t2.small\""))
+	assert.True(t, strings.Contains(result, "    aws_value: t2.micro"))
+	assert.True(t, strings.Contains(result, "    terraform_value: t2.small"))
 }
 
 func TestFormatDriftResults_NoDrift(t *testing.T) {
@@ -95,4 +130,225 @@ func TestFormatDriftResults_NoDrift(t *testing.T) {
 	result := FormatDriftResults(drifts, "i-12345", "text")
 	
 	assert.Contains(t, result, "No configuration drift detected")
-}
\ No newline at end of file
+}
+
+func TestFormatDriftedAttributes(t *testing.T) {
+	drifts := map[string]drift.DriftDetail{
+		"tags":          {Attribute: "tags", InAWS: true, InTerraform: true},
+		"instance_type": {Attribute: "instance_type", InAWS: true, InTerraform: true},
+	}
+
+	assert.Equal(t, "instance_type\ntags", FormatDriftedAttributes(drifts, "text"))
+	assert.Equal(t, `["instance_type","tags"]`, FormatDriftedAttributes(drifts, "json"))
+	assert.Equal(t, "[]", FormatDriftedAttributes(map[string]drift.DriftDetail{}, "json"))
+}
+
+func TestFormatDriftResults_HighSeverity(t *testing.T) {
+	drifts := map[string]drift.DriftDetail{
+		"ebs_block_device": {
+			Attribute:      "ebs_block_device",
+			InAWS:          true,
+			InTerraform:    true,
+			AWSValue:       false,
+			TerraformValue: true,
+			Severity:       drift.SeverityHigh,
+		},
+	}
+
+	assert.Contains(t, FormatDriftResults(drifts, "i-12345", "text"), "Severity: high\n")
+	assert.Contains(t, FormatDriftResults(drifts, "i-12345", "yaml"), "severity: high")
+}
+
+func TestFormatDriftResults_Reason(t *testing.T) {
+	drifts := map[string]drift.DriftDetail{
+		"ami": {
+			Attribute:   "ami",
+			InAWS:       false,
+			InTerraform: true,
+			Reason:      drift.ReasonMissingInAWS,
+		},
+	}
+
+	assert.Contains(t, FormatDriftResults(drifts, "i-12345", "yaml"), "reason: missing_in_aws")
+	assert.Contains(t, FormatDriftResults(drifts, "i-12345", "json"), `"Reason": "missing_in_aws"`)
+}
+
+func TestFileExtension(t *testing.T) {
+	assert.Equal(t, "json", FileExtension("JSON"))
+	assert.Equal(t, "yaml", FileExtension("yaml"))
+	assert.Equal(t, "diff", FileExtension("diff"))
+	assert.Equal(t, "txt", FileExtension("text"))
+}
+
+func TestFormatReport(t *testing.T) {
+	startedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	report := drift.NewReport("i-12345", map[string]drift.DriftDetail{
+		"instance_type": {Attribute: "instance_type", InAWS: true, InTerraform: true, AWSValue: "t2.micro", TerraformValue: "t2.small"},
+	})
+	report.StartedAt = startedAt
+	report.CompletedAt = startedAt.Add(2 * time.Second)
+
+	var jsonData map[string]interface{}
+	err := json.Unmarshal([]byte(FormatReport(report, "json", Options{})), &jsonData)
+	assert.NoError(t, err, "Should be valid JSON")
+	assert.Equal(t, "2025-03-01T12:00:00Z", jsonData["started_at"])
+	assert.Equal(t, "2025-03-01T12:00:02Z", jsonData["time_detected"])
+	assert.NotContains(t, jsonData, "error")
+
+	yamlResult := FormatReport(report, "yaml", Options{})
+	assert.Contains(t, yamlResult, "started_at: 2025-03-01T12:00:00Z")
+
+	report.Err = errors.New("instance not found")
+	assert.Contains(t, FormatReport(report, "text", Options{}), "Error: instance not found")
+	assert.Contains(t, FormatReport(report, "json", Options{}), `"error": "instance not found"`)
+}
+
+func TestFormatReport_Metadata(t *testing.T) {
+	report := drift.NewReport("i-12345", nil)
+	report.Metadata = &drift.Metadata{
+		AMIName:          "golden-2025-03",
+		LaunchTime:       "2025-03-01T08:30:00Z",
+		State:            "running",
+		AvailabilityZone: "us-east-1a",
+	}
+
+	text := FormatReport(report, "text", Options{})
+	assert.Contains(t, text, "AMI name: golden-2025-03\nLaunched: 2025-03-01T08:30:00Z\nState: running\nAvailability zone: us-east-1a\n")
+
+	var jsonData map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(FormatReport(report, "json", Options{})), &jsonData))
+	assert.Equal(t, map[string]any{
+		"ami_name":          "golden-2025-03",
+		"launch_time":       "2025-03-01T08:30:00Z",
+		"state":             "running",
+		"availability_zone": "us-east-1a",
+	}, jsonData["metadata"])
+
+	assert.Contains(t, FormatReport(report, "yaml", Options{}), "metadata:\n  ami_name: golden-2025-03\n  launch_time: \"2025-03-01T08:30:00Z\"\n")
+
+	report.Metadata = nil
+	assert.NotContains(t, FormatReport(report, "json", Options{}), "metadata")
+	assert.NotContains(t, FormatReport(report, "yaml", Options{}), "metadata")
+}
+
+func TestFormatReport_DeterministicOrder(t *testing.T) {
+	drifts := map[string]drift.DriftDetail{}
+	for _, attr := range []string{"tags", "ami", "subnet_id", "instance_type", "ebs_block_device"} {
+		drifts[attr] = drift.DriftDetail{Attribute: attr, InAWS: true, InTerraform: true, AWSValue: "a", TerraformValue: "b"}
+	}
+	completedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	newReport := func() drift.Report {
+		report := drift.NewReport("i-12345", drifts)
+		report.CompletedAt = completedAt
+		return report
+	}
+	report := newReport()
+
+	for _, format := range []string{"text", "yaml", "json", "diff"} {
+		first := FormatReport(report, format, Options{})
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, first, FormatReport(newReport(), format, Options{}), format)
+		}
+	}
+
+	text := FormatReport(report, "text", Options{})
+	positions := make([]int, 0, len(report.Drifts))
+	for _, attr := range []string{"ami", "ebs_block_device", "instance_type", "subnet_id", "tags"} {
+		positions = append(positions, strings.Index(text, "--- "+attr+" ---"))
+	}
+	assert.IsIncreasing(t, positions)
+}
+
+func TestFormatReport_AccountMetadata(t *testing.T) {
+	report := drift.NewReport("i-12345", nil)
+	report.AccountID = "012345678901"
+	report.ARN = "arn:aws:ec2:us-east-1:012345678901:instance/i-12345"
+
+	assert.Contains(t, FormatReport(report, "text", Options{}), "Account: 012345678901\nARN: arn:aws:ec2:us-east-1:012345678901:instance/i-12345\n")
+	assert.Contains(t, FormatReport(report, "yaml", Options{}), "account_id: \"012345678901\"\n")
+
+	var jsonData map[string]interface{}
+	err := json.Unmarshal([]byte(FormatReport(report, "json", Options{})), &jsonData)
+	assert.NoError(t, err)
+	assert.Equal(t, "012345678901", jsonData["account_id"])
+	assert.Equal(t, report.ARN, jsonData["arn"])
+
+	// Reports without metadata omit the fields
+	assert.NotContains(t, FormatReport(drift.NewReport("i-12345", nil), "json", Options{}), "account_id")
+}
+
+func TestFormatReport_Unchecked(t *testing.T) {
+	report := drift.NewReport("i-12345", nil)
+	report.Unchecked = []string{"ebs_block_device", "root_block_device"}
+
+	assert.Contains(t, FormatReport(report, "text", Options{}), "Unchecked (could not be read from AWS): ebs_block_device, root_block_device")
+	assert.Contains(t, FormatReport(report, "yaml", Options{}), "unchecked:\n  - ebs_block_device\n  - root_block_device\n")
+	assert.Contains(t, FormatReport(report, "json", Options{}), `"unchecked": [`)
+	assert.Regexp(t, `i-12345\s+ebs_block_device\s+unchecked`, FormatReport(report, "wide", Options{}))
+}
+
+func TestFormatReport_Warnings(t *testing.T) {
+	report := drift.NewReport("i-12345", nil)
+	report.Warnings = []string{"Failed to get volume information for vol-1: timeout"}
+
+	assert.Contains(t, FormatReport(report, "text", Options{}), "Warnings:\n  - Failed to get volume information for vol-1: timeout\n")
+	assert.Contains(t, FormatReport(report, "yaml", Options{}), "warnings:\n  - 'Failed to get volume information for vol-1: timeout'\n")
+
+	var result map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(FormatReport(report, "json", Options{})), &result))
+	assert.Equal(t, []any{"Failed to get volume information for vol-1: timeout"}, result["warnings"])
+}
+
+func TestFormatDriftResults_TagDiff(t *testing.T) {
+	drifts := map[string]drift.DriftDetail{
+		"tags": {
+			Attribute:      "tags",
+			InAWS:          true,
+			InTerraform:    true,
+			AWSValue:       map[string]string{"Name": "web", "Owner": "ops"},
+			TerraformValue: map[string]any{"Name": "app", "Team": "infra"},
+			TagDiff: &drift.TagDiff{
+				OnlyInAWS:       []string{"Owner"},
+				OnlyInTerraform: []string{"Team"},
+				Changed:         []string{"Name"},
+			},
+		},
+	}
+
+	result := FormatDriftResults(drifts, "i-12345", "text")
+	assert.Contains(t, result, "Only in AWS: Owner=ops\n")
+	assert.Contains(t, result, "Only in Terraform: Team=infra\n")
+	assert.Contains(t, result, "Changed: Name (AWS: web, Terraform: app)\n")
+	assert.NotContains(t, result, "AWS value:")
+}
+
+func TestFormatDriftResults_TagDiffRedacted(t *testing.T) {
+	drifts := map[string]drift.DriftDetail{
+		"tags": {
+			Attribute:      "tags",
+			InAWS:          true,
+			InTerraform:    true,
+			AWSValue:       drift.RedactedValue,
+			TerraformValue: drift.RedactedValue,
+			TagDiff: &drift.TagDiff{
+				OnlyInAWS:       []string{"Owner"},
+				OnlyInTerraform: []string{},
+				Changed:         []string{"Secret"},
+			},
+		},
+	}
+
+	result := FormatDriftResults(drifts, "i-12345", "text")
+	assert.Contains(t, result, "Only in AWS: Owner=***\n")
+	assert.Contains(t, result, "Changed: Secret (AWS: ***, Terraform: ***)\n")
+	assert.NotContains(t, result, "<nil>")
+
+	var report map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(FormatDriftResults(drifts, "i-12345", "json")), &report))
+	tagDiff := report["drifts"].(map[string]any)["tags"].(map[string]any)["TagDiff"]
+	assert.Equal(t, map[string]any{
+		"only_in_aws":       []any{"Owner"},
+		"only_in_terraform": []any{},
+		"changed":           []any{"Secret"},
+	}, tagDiff)
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestFormatReport_Golden(t *testing.T) {
+	defer func(now func() time.Time) { Now = now }(Now)
+	Now = func() time.Time { return time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	drifts := map[string]drift.DriftDetail{
+		"instance_type": {
+			Attribute:      "instance_type",
+			InAWS:          true,
+			InTerraform:    true,
+			AWSValue:       "t2.micro",
+			TerraformValue: "t2.small",
+			Severity:       drift.SeverityMedium,
+			Reason:         drift.ReasonValueMismatch,
+		},
+		"tags": {
+			Attribute:      "tags",
+			InAWS:          true,
+			InTerraform:    true,
+			AWSValue:       map[string]string{"Name": "web", "Environment": "dev"},
+			TerraformValue: map[string]any{"Name": "web", "Environment": "prod", "Team": "infra"},
+			Severity:       drift.SeverityLow,
+			Reason:         drift.ReasonValueMismatch,
+			TagDiff: &drift.TagDiff{
+				OnlyInAWS:       []string{},
+				OnlyInTerraform: []string{"Team"},
+				Changed:         []string{"Environment"},
+			},
+		},
+		"monitoring": {
+			Attribute: "monitoring",
+			InAWS:     true,
+			AWSValue:  true,
+			Severity:  drift.SeverityMedium,
+			Reason:    drift.ReasonMissingInTerraform,
+		},
+	}
+
+	for _, format := range []string{"text", "json", "yaml", "diff", "wide"} {
+		t.Run(format, func(t *testing.T) {
+			got := FormatDriftResults(drifts, "i-12345", format)
+			path := filepath.Join("testdata", "report."+format+".golden")
+
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			assert.Equal(t, string(want), got)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+// jsonReport is the subset of the JSON report format that is read back
+type jsonReport struct {
+	SchemaVersion int                          `json:"schema_version"`
+	InstanceID    string                       `json:"instance_id"`
+	AccountID     string                       `json:"account_id"`
+	ARN           string                       `json:"arn"`
+	Drifts        map[string]drift.DriftDetail `json:"drifts"`
+	Unchecked     []string                     `json:"unchecked"`
+	Warnings      []string                     `json:"warnings"`
+	StartedAt     string                       `json:"started_at"`
+	TimeDetected  string                       `json:"time_detected"`
+	Metadata      *drift.Metadata              `json:"metadata"`
+	Error         string                       `json:"error"`
+}
+
+// ParseJSONReports reads reports saved with --output json: one or more
+// report objects, or arrays of them, one after another. Reports without a
+// schema_version predate it and are read as the current version; reports
+// from a newer version are rejected rather than misread.
+func ParseJSONReports(r io.Reader) ([]drift.Report, error) {
+	var reports []drift.Report
+	decoder := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse JSON report: %w", err)
+		}
+
+		var batch []jsonReport
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			if err := json.Unmarshal(raw, &batch); err != nil {
+				return nil, fmt.Errorf("failed to parse JSON report: %w", err)
+			}
+		} else {
+			var single jsonReport
+			if err := json.Unmarshal(raw, &single); err != nil {
+				return nil, fmt.Errorf("failed to parse JSON report: %w", err)
+			}
+			batch = append(batch, single)
+		}
+
+		for _, parsed := range batch {
+			if parsed.InstanceID == "" {
+				return nil, fmt.Errorf("invalid JSON report: missing instance_id")
+			}
+			if parsed.SchemaVersion > SchemaVersion {
+				return nil, fmt.Errorf("JSON report for %s has schema_version %d; this version of aws-terror reads up to %d", parsed.InstanceID, parsed.SchemaVersion, SchemaVersion)
+			}
+			reports = append(reports, parsed.report())
+		}
+	}
+	return reports, nil
+}
+
+func (j jsonReport) report() drift.Report {
+	report := drift.NewReport(j.InstanceID, j.Drifts)
+	report.AccountID = j.AccountID
+	report.ARN = j.ARN
+	report.Unchecked = j.Unchecked
+	report.Warnings = j.Warnings
+	report.Metadata = j.Metadata
+	report.StartedAt, _ = time.Parse(time.RFC3339, j.StartedAt)
+	report.CompletedAt, _ = time.Parse(time.RFC3339, j.TimeDetected)
+	if j.Error != "" {
+		report.Err = errors.New(j.Error)
+	}
+	return report
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+func TestParseJSONReports_RoundTrip(t *testing.T) {
+	report := drift.NewReport("i-1", map[string]drift.DriftDetail{
+		"instance_type": {InAWS: true, InTerraform: true, AWSValue: "t2.micro", TerraformValue: "t3.micro", Severity: drift.SeverityMedium, Reason: drift.ReasonValueMismatch},
+	})
+	report.AccountID = "123456789012"
+	report.Metadata = &drift.Metadata{AMIName: "golden-2025-03", State: "running"}
+	report.CompletedAt = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	failed := drift.NewReport("i-2", nil)
+	failed.Err = errors.New("instance not found")
+
+	// Reports one after another and in an array
+	input := FormatReport(report, "json", Options{}) + "\n" + FormatReport(failed, "json", Options{}) + "\n[" + FormatReport(report, "json", Options{}) + "]"
+
+	reports, err := ParseJSONReports(strings.NewReader(input))
+
+	assert.NoError(t, err)
+	assert.Len(t, reports, 3)
+	assert.Equal(t, "i-1", reports[0].InstanceID)
+	assert.Equal(t, "123456789012", reports[0].AccountID)
+	assert.Equal(t, report.CompletedAt, reports[0].CompletedAt)
+	assert.Equal(t, report.Drifts, reports[0].Drifts)
+	assert.Equal(t, report.Metadata, reports[0].Metadata)
+	assert.Nil(t, reports[1].Metadata)
+	assert.EqualError(t, reports[1].Err, "instance not found")
+	assert.Equal(t, "i-1", reports[2].InstanceID)
+}
+
+func TestParseJSONReports_OutputFile(t *testing.T) {
+	// Written by drift --output json --output-file
+	data, err := os.ReadFile(filepath.Join("testdata", "output-file.json"))
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+
+	reports, err := ParseJSONReports(strings.NewReader(string(data)))
+
+	assert.NoError(t, err)
+	assert.Len(t, reports, 3)
+	assert.Equal(t, "i-0a1b2c3d4e5f60001", reports[0].InstanceID)
+	assert.Equal(t, "arn:aws:ec2:us-east-1:123456789012:instance/i-0a1b2c3d4e5f60001", reports[0].ARN)
+	assert.Len(t, reports[0].Drifts, 2)
+	assert.Equal(t, drift.ReasonMissingInTerraform, reports[0].Drifts[1].Reason)
+	assert.ErrorContains(t, reports[1].Err, "not found")
+	assert.Empty(t, reports[2].Drifts)
+}
+
+func TestParseJSONReports_Invalid(t *testing.T) {
+	_, err := ParseJSONReports(strings.NewReader(`{"drifts": {}}`))
+	assert.ErrorContains(t, err, "missing instance_id")
+
+	_, err = ParseJSONReports(strings.NewReader(`Results for instance i-1:`))
+	assert.ErrorContains(t, err, "failed to parse JSON report")
+}
+
+func TestParseJSONReports_SchemaVersion(t *testing.T) {
+	// Reports saved before schema_version was added are still read
+	reports, err := ParseJSONReports(strings.NewReader(`{"instance_id": "i-1", "drifts": {}}`))
+	assert.NoError(t, err)
+	assert.Len(t, reports, 1)
+
+	_, err = ParseJSONReports(strings.NewReader(`{"schema_version": 99, "instance_id": "i-1", "drifts": {}}`))
+	assert.ErrorContains(t, err, "schema_version 99")
+}
@@ -0,0 +1,48 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+// reasonLabels name each reason in the summary, singular and plural, in
+// summary order
+var reasonLabels = []struct {
+	reason           drift.Reason
+	singular, plural string
+}{
+	{drift.ReasonValueMismatch, "value mismatch", "value mismatches"},
+	{drift.ReasonMissingInTerraform, "missing in Terraform", "missing in Terraform"},
+	{drift.ReasonMissingInAWS, "missing in AWS", "missing in AWS"},
+	{drift.ReasonTypeMismatch, "type mismatch", "type mismatches"},
+}
+
+// FormatReasonCounts summarizes how many drifts were found for each reason,
+// e.g. "12 value mismatches, 3 missing in Terraform, 1 missing in AWS".
+// Reasons with no drift are left out, and drifts without a known reason are
+// counted as other.
+func FormatReasonCounts(counts map[drift.Reason]int) string {
+	var parts []string
+	known := 0
+	for _, label := range reasonLabels {
+		count := counts[label.reason]
+		known += count
+		switch {
+		case count == 1:
+			parts = append(parts, fmt.Sprintf("1 %s", label.singular))
+		case count > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", count, label.plural))
+		}
+	}
+
+	other := 0
+	for _, count := range counts {
+		other += count
+	}
+	if other -= known; other > 0 {
+		parts = append(parts, fmt.Sprintf("%d other", other))
+	}
+	return strings.Join(parts, ", ")
+}
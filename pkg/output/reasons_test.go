@@ -0,0 +1,22 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+func TestFormatReasonCounts(t *testing.T) {
+	counts := map[drift.Reason]int{
+		drift.ReasonMissingInAWS:       1,
+		drift.ReasonValueMismatch:      12,
+		drift.ReasonMissingInTerraform: 3,
+		drift.ReasonTypeMismatch:       1,
+		"":                             2,
+	}
+
+	assert.Equal(t, "12 value mismatches, 3 missing in Terraform, 1 missing in AWS, 1 type mismatch, 2 other", FormatReasonCounts(counts))
+	assert.Equal(t, "", FormatReasonCounts(nil))
+}
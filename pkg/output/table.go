@@ -0,0 +1,115 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+const (
+	tablePadding       = 2
+	minTableValueWidth = 10
+)
+
+// IsTableFormat reports whether format is the wide table output, which is
+// rendered once across all instances rather than per instance
+func IsTableFormat(format string) bool {
+	switch strings.ToLower(format) {
+	case "wide", "table":
+		return true
+	default:
+		return false
+	}
+}
+
+// FormatTable renders the reports as one aligned table with a row per
+// drifted attribute. Values are truncated so rows fit in width columns;
+// a width of 0 disables truncation.
+func FormatTable(reports []drift.Report, width int) string {
+	rows := [][]string{{"INSTANCE", "ATTRIBUTE", "STATUS", "AWS", "TERRAFORM"}}
+	for _, report := range reports {
+		switch {
+		case report.Err != nil:
+			rows = append(rows, []string{report.InstanceID, "-", "error", report.Err.Error(), ""})
+		case !report.HasDrift():
+			rows = append(rows, []string{report.InstanceID, "-", "in sync", "", ""})
+		}
+
+		for _, attr := range report.Unchecked {
+			rows = append(rows, []string{report.InstanceID, attr, "unchecked", "", ""})
+		}
+
+		for _, detail := range report.Drifts {
+			row := []string{report.InstanceID, detail.Attribute, tableStatus(detail), "", ""}
+			if detail.InAWS {
+				row[3] = fmt.Sprintf("%v", derefValue(detail.AWSValue))
+			}
+			if detail.InTerraform {
+				row[4] = fmt.Sprintf("%v", derefValue(detail.TerraformValue))
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	if valueWidth := tableValueWidth(rows, width); valueWidth > 0 {
+		for _, row := range rows[1:] {
+			row[3] = truncate(row[3], valueWidth)
+			row[4] = truncate(row[4], valueWidth)
+		}
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, tablePadding, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+
+	// Empty trailing cells leave padding behind
+	lines := strings.Split(strings.TrimRight(sb.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func tableStatus(detail drift.DriftDetail) string {
+	switch {
+	case detail.InAWS && detail.InTerraform:
+		return "differs"
+	case detail.InAWS:
+		return "aws only"
+	default:
+		return "terraform only"
+	}
+}
+
+// tableValueWidth splits the width left after the instance, attribute and
+// status columns between the two value columns. It returns 0 when no
+// truncation is needed.
+func tableValueWidth(rows [][]string, width int) int {
+	if width <= 0 {
+		return 0
+	}
+
+	fixed := 0
+	for col := 0; col < 3; col++ {
+		longest := 0
+		for _, row := range rows {
+			longest = max(longest, len(row[col]))
+		}
+		fixed += longest + tablePadding
+	}
+
+	valueWidth := (width - fixed - tablePadding) / 2
+	return max(valueWidth, minTableValueWidth)
+}
+
+func truncate(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+	return s[:width-3] + "..."
+}
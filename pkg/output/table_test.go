@@ -0,0 +1,59 @@
+package output
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTable(t *testing.T) {
+	reports := []drift.Report{
+		drift.NewReport("i-12345", map[string]drift.DriftDetail{
+			"instance_type": {Attribute: "instance_type", InAWS: true, InTerraform: true, AWSValue: "t2.micro", TerraformValue: "t2.small"},
+			"monitoring":    {Attribute: "monitoring", InAWS: true, AWSValue: true},
+		}),
+		drift.NewReport("i-67890", nil),
+		{InstanceID: "i-00000", Err: errors.New("instance not found")},
+	}
+
+	lines := strings.Split(FormatTable(reports, 0), "\n")
+
+	assert.Len(t, lines, 5)
+	assert.Regexp(t, `^INSTANCE\s+ATTRIBUTE\s+STATUS\s+AWS\s+TERRAFORM$`, lines[0])
+	assert.Regexp(t, `^i-12345\s+instance_type\s+differs\s+t2.micro\s+t2.small$`, lines[1])
+	assert.Regexp(t, `^i-12345\s+monitoring\s+aws only\s+true$`, lines[2])
+	assert.Regexp(t, `^i-67890\s+-\s+in sync$`, lines[3])
+	assert.Regexp(t, `^i-00000\s+-\s+error\s+instance not found$`, lines[4])
+
+	// Columns are aligned
+	assert.Equal(t, strings.Index(lines[0], "STATUS"), strings.Index(lines[1], "differs"))
+}
+
+func TestFormatTable_TruncatesToWidth(t *testing.T) {
+	reports := []drift.Report{
+		drift.NewReport("i-12345", map[string]drift.DriftDetail{
+			"tags": {
+				Attribute:      "tags",
+				InAWS:          true,
+				InTerraform:    true,
+				AWSValue:       map[string]string{"Name": "web", "Environment": "production", "Owner": "platform-team"},
+				TerraformValue: map[string]string{"Name": "web", "Environment": "staging", "Owner": "platform-team"},
+			},
+		}),
+	}
+
+	for _, line := range strings.Split(FormatTable(reports, 80), "\n") {
+		assert.LessOrEqual(t, len(line), 80)
+	}
+	assert.Contains(t, FormatTable(reports, 80), "...")
+	assert.NotContains(t, FormatTable(reports, 0), "...")
+}
+
+func TestIsTableFormat(t *testing.T) {
+	assert.True(t, IsTableFormat("wide"))
+	assert.True(t, IsTableFormat("TABLE"))
+	assert.False(t, IsTableFormat("text"))
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+// timingPhases are the keys of the timings_ms report field, in output order
+var timingPhases = []string{"aws_fetch", "state_parse", "compare", "total"}
+
+// timingsMillis returns the phase timings in milliseconds, keyed by phase
+func timingsMillis(t drift.Timings) map[string]float64 {
+	return map[string]float64{
+		"aws_fetch":   millis(t.AWSFetch),
+		"state_parse": millis(t.StateParse),
+		"compare":     millis(t.Compare),
+		"total":       millis(t.Total()),
+	}
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// FormatTimingSummary summarizes the timings of the timed reports: the
+// minimum, maximum and average check time overall and per phase, followed by
+// the slowest instances
+func FormatTimingSummary(reports []drift.Report, slowest int) string {
+	var timed []drift.Report
+	for _, report := range reports {
+		if report.Timings != nil {
+			timed = append(timed, report)
+		}
+	}
+	if len(timed) == 0 {
+		return "No instance timings recorded\n"
+	}
+
+	sort.SliceStable(timed, func(i, j int) bool {
+		return timed[i].Timings.Total() > timed[j].Timings.Total()
+	})
+
+	phases := []struct {
+		name  string
+		value func(drift.Timings) time.Duration
+	}{
+		{"total", drift.Timings.Total},
+		{"aws fetch", func(t drift.Timings) time.Duration { return t.AWSFetch }},
+		{"state parse", func(t drift.Timings) time.Duration { return t.StateParse }},
+		{"compare", func(t drift.Timings) time.Duration { return t.Compare }},
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Timings for %d instances:\n", len(timed)))
+	for _, phase := range phases {
+		minimum, maximum, sum := time.Duration(-1), time.Duration(0), time.Duration(0)
+		for _, report := range timed {
+			d := phase.value(*report.Timings)
+			if minimum < 0 || d < minimum {
+				minimum = d
+			}
+			if d > maximum {
+				maximum = d
+			}
+			sum += d
+		}
+		sb.WriteString(fmt.Sprintf("  %-12s min %-10s max %-10s avg %s\n", phase.name+":",
+			roundDuration(minimum), roundDuration(maximum), roundDuration(sum/time.Duration(len(timed)))))
+	}
+
+	if slowest > len(timed) {
+		slowest = len(timed)
+	}
+	if slowest > 0 {
+		sb.WriteString("Slowest instances:\n")
+		for _, report := range timed[:slowest] {
+			t := report.Timings
+			sb.WriteString(fmt.Sprintf("  %-20s %-10s (aws fetch %s, state parse %s, compare %s)\n", report.InstanceID,
+				roundDuration(t.Total()), roundDuration(t.AWSFetch), roundDuration(t.StateParse), roundDuration(t.Compare)))
+		}
+	}
+	return sb.String()
+}
+
+// roundDuration rounds a timing for display
+func roundDuration(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(10 * time.Microsecond)
+}
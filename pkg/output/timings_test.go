@@ -0,0 +1,53 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+func timedReport(id string, fetch, parse, compare time.Duration) drift.Report {
+	report := drift.NewReport(id, nil)
+	report.Timings = &drift.Timings{AWSFetch: fetch, StateParse: parse, Compare: compare}
+	return report
+}
+
+func TestFormatTimingSummary(t *testing.T) {
+	reports := []drift.Report{
+		timedReport("i-fast", 100*time.Millisecond, 10*time.Millisecond, time.Millisecond),
+		timedReport("i-slow", 2*time.Second, 20*time.Millisecond, time.Millisecond),
+		timedReport("i-mid", 500*time.Millisecond, 30*time.Millisecond, time.Millisecond),
+		drift.NewReport("i-untimed", nil),
+	}
+
+	summary := FormatTimingSummary(reports, 2)
+
+	assert.Contains(t, summary, "Timings for 3 instances:")
+	assert.Contains(t, summary, "aws fetch:   min 100ms      max 2s         avg 866.67ms")
+	assert.Contains(t, summary, "state parse: min 10ms       max 30ms       avg 20ms")
+	lines := strings.Split(summary, "Slowest instances:\n")[1]
+	assert.True(t, strings.HasPrefix(lines, "  i-slow "))
+	assert.Contains(t, lines, "i-mid")
+	assert.NotContains(t, lines, "i-fast")
+}
+
+func TestFormatTimingSummary_NoTimings(t *testing.T) {
+	assert.Equal(t, "No instance timings recorded\n", FormatTimingSummary([]drift.Report{drift.NewReport("i-1", nil)}, 5))
+}
+
+func TestFormatReport_JSONTimings(t *testing.T) {
+	var result map[string]any
+	err := json.Unmarshal([]byte(FormatReport(timedReport("i-1", 1500*time.Microsecond, 2*time.Millisecond, 0), "json", Options{})), &result)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"aws_fetch": 1.5, "state_parse": 2.0, "compare": 0.0, "total": 2.0}, result["timings_ms"])
+
+	var untimed map[string]any
+	err = json.Unmarshal([]byte(FormatReport(drift.NewReport("i-1", nil), "json", Options{})), &untimed)
+	assert.NoError(t, err)
+	assert.NotContains(t, untimed, "timings_ms")
+}
@@ -0,0 +1,150 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	clearLine  = "\r\033[K"
+	hideCursor = "\033[?25l"
+	showCursor = "\033[?25h"
+)
+
+var frames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// writeMu serializes terminal writes, since several spinners (e.g. the
+// command's and the state parser's) may draw on the same line
+var writeMu sync.Mutex
+
+// Spinner shows an animated message on stderr while work is in progress. It
+// is safe for concurrent use, and draws nothing when stderr isn't a terminal.
+type Spinner struct {
+	mu       sync.Mutex
+	out      io.Writer
+	enabled  bool
+	message  string
+	interval time.Duration
+	running  bool
+	done     chan struct{}
+	stopped  chan struct{}
+}
+
+// NewSpinner returns a stopped spinner showing message
+func NewSpinner(message string) *Spinner {
+	return newSpinner(message, os.Stderr, isTerminal(os.Stderr))
+}
+
+func newSpinner(message string, out io.Writer, enabled bool) *Spinner {
+	return &Spinner{
+		out:      out,
+		enabled:  enabled,
+		message:  message,
+		interval: 100 * time.Millisecond,
+	}
+}
+
+// isTerminal reports whether f is a character device, honoring TERM=dumb
+func isTerminal(f *os.File) bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Start begins drawing the spinner. Starting a running spinner does nothing.
+func (s *Spinner) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running || !s.enabled {
+		return
+	}
+
+	s.running = true
+	s.done = make(chan struct{})
+	s.stopped = make(chan struct{})
+	s.write(hideCursor)
+	go s.run(s.done, s.stopped)
+}
+
+func (s *Spinner) run(done, stopped chan struct{}) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		s.mu.Lock()
+		message := s.message
+		s.mu.Unlock()
+		s.write(fmt.Sprintf("%s%s %s", clearLine, frames[i%len(frames)], message))
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// UpdateMessage changes the message shown next to the spinner
+func (s *Spinner) UpdateMessage(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.message = message
+}
+
+// Stop stops the spinner, clears its line and restores the cursor. It is safe
+// to call more than once, e.g. from a defer after Error.
+func (s *Spinner) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.done)
+	stopped := s.stopped
+	s.mu.Unlock()
+
+	// Wait for the last frame so it can't be drawn after the line is cleared
+	<-stopped
+	s.write(clearLine + showCursor)
+}
+
+// Clear erases the spinner's line so other output, such as a log entry, can
+// be written; the next frame redraws it
+func (s *Spinner) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		s.write(clearLine)
+	}
+}
+
+// Success stops the spinner and prints message as completed
+func (s *Spinner) Success(message string) {
+	s.finish("✓", message)
+}
+
+// Error stops the spinner and prints message as failed
+func (s *Spinner) Error(message string) {
+	s.finish("✗", message)
+}
+
+func (s *Spinner) finish(symbol, message string) {
+	s.Stop()
+	if s.enabled {
+		s.write(fmt.Sprintf("%s %s\n", symbol, message))
+	}
+}
+
+func (s *Spinner) write(text string) {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	fmt.Fprint(s.out, text)
+}
@@ -0,0 +1,92 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer is a bytes.Buffer that can be read while the spinner writes
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestSpinner_StopRestoresTerminal(t *testing.T) {
+	out := &syncBuffer{}
+	s := newSpinner("Working", out, true)
+	s.interval = time.Millisecond
+
+	s.Start()
+	time.Sleep(5 * time.Millisecond)
+	s.Stop()
+	s.Stop()
+
+	text := out.String()
+	assert.True(t, strings.HasPrefix(text, hideCursor))
+	assert.True(t, strings.HasSuffix(text, clearLine+showCursor))
+	assert.Contains(t, text, "Working")
+	assert.Equal(t, 1, strings.Count(text, showCursor))
+}
+
+func TestSpinner_ErrorClearsLineBeforeMessage(t *testing.T) {
+	out := &syncBuffer{}
+	s := newSpinner("Working", out, true)
+
+	s.Start()
+	s.Error("Failed")
+	s.Stop()
+
+	assert.True(t, strings.HasSuffix(out.String(), clearLine+showCursor+"✗ Failed\n"))
+}
+
+func TestSpinner_DisabledWritesNothing(t *testing.T) {
+	out := &syncBuffer{}
+	s := newSpinner("Working", out, false)
+
+	s.Start()
+	s.UpdateMessage("Still working")
+	s.Success("Done")
+	s.Stop()
+
+	assert.Empty(t, out.String())
+}
+
+func TestSpinner_ConcurrentUse(t *testing.T) {
+	out := &syncBuffer{}
+	s := newSpinner("Working", out, true)
+	s.interval = time.Millisecond
+	s.Start()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				s.UpdateMessage("Step")
+				s.Clear()
+			}
+			s.Stop()
+		}()
+	}
+	wg.Wait()
+
+	assert.True(t, strings.HasSuffix(out.String(), clearLine+showCursor))
+}
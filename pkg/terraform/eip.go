@@ -0,0 +1,53 @@
+package terraform
+
+import (
+	"sort"
+
+	"github.com/katungi/aws-terror/aws"
+)
+
+// elasticIPAllocations maps instance IDs to the sorted allocation IDs of the
+// Elastic IPs the states associate with them
+func elasticIPAllocations(states ...map[string]any) map[string][]string {
+	seen := make(map[string]map[string]bool)
+	add := func(instanceID, allocationID string) {
+		if instanceID == "" || allocationID == "" {
+			return
+		}
+		if seen[instanceID] == nil {
+			seen[instanceID] = make(map[string]bool)
+		}
+		seen[instanceID][allocationID] = true
+	}
+
+	for _, state := range states {
+		for _, inst := range stateInstances(state, "aws_eip_association") {
+			add(stringField(inst.attributes, "instance_id"), stringField(inst.attributes, "allocation_id"))
+		}
+		// An aws_eip can also be associated through its instance argument
+		for _, inst := range stateInstances(state, "aws_eip") {
+			allocationID := stringField(inst.attributes, "allocation_id")
+			if allocationID == "" {
+				allocationID = stringField(inst.attributes, "id")
+			}
+			add(stringField(inst.attributes, "instance"), allocationID)
+		}
+	}
+
+	allocations := make(map[string][]string, len(seen))
+	for instanceID, ids := range seen {
+		for id := range ids {
+			allocations[instanceID] = append(allocations[instanceID], id)
+		}
+		sort.Strings(allocations[instanceID])
+	}
+	return allocations
+}
+
+// addElasticIPs sets aws.ElasticIPsAttribute on an instance's attributes if
+// any Elastic IP is associated with it
+func addElasticIPs(attributes map[string]any, allocations map[string][]string) {
+	if ids := allocations[stringField(attributes, "id")]; len(ids) > 0 {
+		attributes[aws.ElasticIPsAttribute] = ids
+	}
+}
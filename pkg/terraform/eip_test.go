@@ -0,0 +1,59 @@
+package terraform
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/katungi/aws-terror/aws"
+)
+
+func eipResource(resourceType, name, attributes string) string {
+	return `{"mode": "managed", "type": "` + resourceType + `", "name": "` + name + `", "instances": [{"attributes": ` + attributes + `}]}`
+}
+
+func TestParseState_ElasticIPs(t *testing.T) {
+	state := `{"version": 4, "resources": [` +
+		instanceResource("web", `{"id": "i-web"}`) + "," +
+		instanceResource("db", `{"id": "i-db"}`) + "," +
+		eipResource("aws_eip_association", "web", `{"instance_id": "i-web", "allocation_id": "eipalloc-2"}`) + "," +
+		eipResource("aws_eip", "web", `{"id": "eipalloc-1", "allocation_id": "eipalloc-1", "instance": "i-web"}`) + "," +
+		eipResource("aws_eip", "spare", `{"id": "eipalloc-3", "allocation_id": "eipalloc-3", "instance": ""}`) + `]}`
+
+	attributes, err := ParseState(strings.NewReader(state), "i-web")
+	if err != nil {
+		t.Fatalf("ParseState() error = %v", err)
+	}
+	want := []string{"eipalloc-1", "eipalloc-2"}
+	if got := attributes[aws.ElasticIPsAttribute]; !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", aws.ElasticIPsAttribute, got, want)
+	}
+
+	// Instances without an Elastic IP don't get the attribute
+	attributes, err = ParseState(strings.NewReader(state), "i-db")
+	if err != nil {
+		t.Fatalf("ParseState() error = %v", err)
+	}
+	if got, ok := attributes[aws.ElasticIPsAttribute]; ok {
+		t.Errorf("%s = %v, want unset", aws.ElasticIPsAttribute, got)
+	}
+}
+
+func TestBuildStateIndex_ElasticIPsAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeStateFile(t, dir, "compute.tfstate", instanceResource("web", `{"id": "i-web"}`))
+	writeStateFile(t, dir, "network.tfstate", eipResource("aws_eip_association", "web", `{"instance_id": "i-web", "allocation_id": "eipalloc-1"}`))
+
+	index, err := BuildStateIndex(filepath.Join(dir, "*.tfstate"), 2, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("BuildStateIndex() error = %v", err)
+	}
+	attributes, _, err := index.Lookup("i-web")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if got, want := attributes[aws.ElasticIPsAttribute], []string{"eipalloc-1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", aws.ElasticIPsAttribute, got, want)
+	}
+}
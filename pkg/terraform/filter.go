@@ -0,0 +1,52 @@
+package terraform
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// MatchAddress reports whether a resource address matches any of the glob
+// patterns, e.g. aws_instance.web* or module.app.*. A pattern also matches
+// every instance of a counted resource when it matches the address without
+// the instance key, and an exact address always matches even though its
+// brackets would otherwise be read as a character class.
+func MatchAddress(address string, patterns []string) (bool, error) {
+	baseAddress := address
+	if i := strings.Index(address, "["); i >= 0 {
+		baseAddress = address[:i]
+	}
+
+	for _, pattern := range patterns {
+		if pattern == address {
+			return true, nil
+		}
+		for _, candidate := range []string{address, baseAddress} {
+			matched, err := path.Match(pattern, candidate)
+			if err != nil {
+				return false, fmt.Errorf("invalid resource filter %q: %w", pattern, err)
+			}
+			if matched {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// FilterResources returns the resources whose address matches any of the
+// glob patterns
+func FilterResources(resources []StateResource, patterns []string) ([]StateResource, error) {
+	var filtered []StateResource
+	for _, resource := range resources {
+		matched, err := MatchAddress(resource.Address, patterns)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			filtered = append(filtered, resource)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,78 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		patterns []string
+		expected bool
+	}{
+		{address: "aws_instance.web", patterns: []string{"aws_instance.web*"}, expected: true},
+		{address: "aws_instance.web_blue", patterns: []string{"aws_instance.web*"}, expected: true},
+		{address: "aws_instance.api", patterns: []string{"aws_instance.web*"}, expected: false},
+		{address: "aws_instance.web[0]", patterns: []string{"aws_instance.web"}, expected: true},
+		{address: `aws_instance.web["blue"]`, patterns: []string{`aws_instance.web["blue"]`}, expected: true},
+		{address: `aws_instance.web["green"]`, patterns: []string{`aws_instance.web["blue"]`}, expected: false},
+		{address: "module.app.aws_instance.web", patterns: []string{"aws_instance.web*"}, expected: false},
+		{address: "module.app.aws_instance.web", patterns: []string{"module.app.*"}, expected: true},
+		{address: "aws_instance.api", patterns: []string{"aws_instance.web", "aws_instance.a*"}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			matched, err := MatchAddress(tt.address, tt.patterns)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if matched != tt.expected {
+				t.Errorf("expected %v for %v but got %v", tt.expected, tt.patterns, matched)
+			}
+		})
+	}
+}
+
+func TestMatchAddress_InvalidPattern(t *testing.T) {
+	if _, err := MatchAddress("aws_instance.web", []string{"aws_instance.[web"}); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+}
+
+func TestFilterResources(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "terraform.tfstate")
+	state := `{
+		"version": 4,
+		"resources": [
+			{"type": "aws_instance", "name": "web", "instances": [
+				{"index_key": 0, "attributes": {"id": "i-0bbb"}},
+				{"index_key": 1, "attributes": {"id": "i-0aaa"}}
+			]},
+			{"type": "aws_instance", "name": "api", "instances": [{"attributes": {"id": "i-0ccc"}}]}
+		]
+	}`
+	if err := os.WriteFile(statePath, []byte(state), 0644); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	resources, err := StateResources(statePath, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filtered, err := FilterResources(resources, []string{"aws_instance.web*"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []StateResource{
+		{ID: "i-0bbb", Address: "aws_instance.web[0]"},
+		{ID: "i-0aaa", Address: "aws_instance.web[1]"},
+	}
+	if !reflect.DeepEqual(filtered, expected) {
+		t.Errorf("expected %v but got %v", expected, filtered)
+	}
+}
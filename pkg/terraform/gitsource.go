@@ -0,0 +1,154 @@
+package terraform
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitSourcePrefix marks a configuration path as a git repository, in the
+// syntax of Terraform module sources
+const gitSourcePrefix = "git::"
+
+// IsGitSource reports whether a configuration path is a git source such as
+// git::https://example.com/infra.git//prod?ref=main
+func IsGitSource(path string) bool {
+	return strings.HasPrefix(path, gitSourcePrefix)
+}
+
+// gitSource is a parsed git source: the repository to clone, the directory
+// within it and the branch, tag or full commit SHA to check out (the default
+// branch if empty)
+type gitSource struct {
+	repository string
+	subdir     string
+	ref        string
+}
+
+// parseGitSource splits a git source into its repository, the subdirectory
+// after a "//" and the ref query parameter
+func parseGitSource(source string) (gitSource, error) {
+	raw := strings.TrimPrefix(source, gitSourcePrefix)
+
+	var parsed gitSource
+	if base, query, ok := strings.Cut(raw, "?"); ok {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return gitSource{}, fmt.Errorf("invalid git source %s: %w", source, err)
+		}
+		parsed.ref = values.Get("ref")
+		raw = base
+	}
+
+	// The "//" of the scheme isn't a subdirectory separator
+	schemeEnd := 0
+	if i := strings.Index(raw, "://"); i >= 0 {
+		schemeEnd = i + len("://")
+	}
+	if i := strings.Index(raw[schemeEnd:], "//"); i >= 0 {
+		parsed.subdir = strings.Trim(raw[schemeEnd+i+2:], "/")
+		raw = raw[:schemeEnd+i]
+	}
+	parsed.repository = raw
+
+	if parsed.repository == "" {
+		return gitSource{}, fmt.Errorf("invalid git source %s: missing repository", source)
+	}
+	if filepath.IsAbs(parsed.subdir) || strings.HasPrefix(filepath.Clean(parsed.subdir), "..") {
+		return gitSource{}, fmt.Errorf("invalid git source %s: subdirectory must be within the repository", source)
+	}
+	return parsed, nil
+}
+
+// CheckoutGitSource shallow-clones the repository of a git source into a
+// temporary directory and returns the configuration directory within it,
+// for ParseHCLConfig. cleanup removes the clone.
+func CheckoutGitSource(source string) (dir string, cleanup func(), err error) {
+	parsed, err := parseGitSource(source)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "aws-terror-config-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create checkout directory: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(tmp) }
+
+	if err := cloneGitSource(parsed, tmp); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	dir = filepath.Join(tmp, parsed.subdir)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		cleanup()
+		return "", nil, fmt.Errorf("%s has no directory %s", parsed.repository, parsed.subdir)
+	}
+	return dir, cleanup, nil
+}
+
+// cloneGitSource shallow-clones the ref of a git source into dir. Branches
+// and tags are cloned directly; a commit SHA can't be, so it is fetched into
+// an empty repository and checked out.
+func cloneGitSource(source gitSource, dir string) error {
+	if !isCommitSHA(source.ref) {
+		args := []string{"clone", "--quiet", "--depth", "1"}
+		if source.ref != "" {
+			args = append(args, "--branch", source.ref)
+		}
+		if err := runGit("", append(args, "--", source.repository, dir)...); err != nil {
+			if isHex(source.ref) {
+				return fmt.Errorf("failed to clone %s: %w (abbreviated commit SHAs are not supported; use the full SHA)", source.repository, err)
+			}
+			return fmt.Errorf("failed to clone %s: %w", source.repository, err)
+		}
+		return nil
+	}
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth", "1", "--", source.repository, source.ref},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	} {
+		if err := runGit(dir, args...); err != nil {
+			return fmt.Errorf("failed to fetch commit %s from %s: %w", source.ref, source.repository, err)
+		}
+	}
+	return nil
+}
+
+// runGit runs git in dir, or the working directory if empty, including its
+// error output in the returned error
+func runGit(dir string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// isCommitSHA reports whether ref is a full SHA-1 or SHA-256 commit ID
+func isCommitSHA(ref string) bool {
+	return (len(ref) == 40 || len(ref) == 64) && isHex(ref)
+}
+
+// isHex reports whether s is a non-empty string of hexadecimal digits
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
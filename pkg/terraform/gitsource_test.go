@@ -0,0 +1,104 @@
+package terraform
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGitSource(t *testing.T) {
+	tests := []struct {
+		source  string
+		want    gitSource
+		wantErr bool
+	}{
+		{"git::https://example.com/infra.git", gitSource{repository: "https://example.com/infra.git"}, false},
+		{"git::https://example.com/infra.git//envs/prod?ref=main", gitSource{repository: "https://example.com/infra.git", subdir: "envs/prod", ref: "main"}, false},
+		{"git::https://example.com/infra.git?ref=v1.2.0", gitSource{repository: "https://example.com/infra.git", ref: "v1.2.0"}, false},
+		{"git::git@github.com:acme/infra.git//prod", gitSource{repository: "git@github.com:acme/infra.git", subdir: "prod"}, false},
+		{"git::https://example.com/infra.git//../etc", gitSource{}, true},
+		{"git::", gitSource{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseGitSource(tt.source)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseGitSource(%q) error = %v, wantErr %v", tt.source, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseGitSource(%q) = %+v, want %+v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestCheckoutGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "prod"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+}
+`
+	if err := os.WriteFile(filepath.Join(repo, "prod", "main.tf"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", "main"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "config"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	dir, cleanup, err := CheckoutGitSource("git::file://" + repo + "//prod?ref=main")
+	if err != nil {
+		t.Fatalf("CheckoutGitSource() error = %v", err)
+	}
+	attributes, err := ParseHCLConfig(dir, "aws_instance.web")
+	if err != nil {
+		t.Fatalf("ParseHCLConfig() error = %v", err)
+	}
+	if attributes["instance_type"] != "t3.micro" {
+		t.Errorf("instance_type = %v, want t3.micro", attributes["instance_type"])
+	}
+
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("checkout %s still exists after cleanup", dir)
+	}
+
+	head, err := exec.Command("git", "-C", repo, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("git rev-parse: %v", err)
+	}
+	sha := strings.TrimSpace(string(head))
+	dir, cleanup, err = CheckoutGitSource("git::file://" + repo + "//prod?ref=" + sha)
+	if err != nil {
+		t.Fatalf("CheckoutGitSource() with a commit SHA error = %v", err)
+	}
+	defer cleanup()
+	if _, err := os.Stat(filepath.Join(dir, "main.tf")); err != nil {
+		t.Errorf("checkout of commit %s has no main.tf: %v", sha, err)
+	}
+
+	_, _, err = CheckoutGitSource("git::file://" + repo + "//prod?ref=" + sha[:7])
+	if err == nil || !strings.Contains(err.Error(), "use the full SHA") {
+		t.Errorf("CheckoutGitSource() with an abbreviated SHA error = %v, want a full SHA hint", err)
+	}
+
+	if _, _, err := CheckoutGitSource("git::file://" + repo + "//staging"); err == nil {
+		t.Error("CheckoutGitSource() with a missing directory succeeded")
+	}
+}
@@ -0,0 +1,152 @@
+package terraform
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// StateIndex holds the aws_instance resources of the state files a state
+// path names, so each instance check looks an instance up instead of reading
+// and decoding every file again. It is read-only once built, so it is safe
+// for concurrent use.
+type StateIndex struct {
+	byID      map[string]*indexedInstance
+	byAddress map[string][]*indexedInstance
+	version   string
+}
+
+// indexedInstance is an instance's attributes merged across the files it is
+// found in. err records a conflict between two of those files.
+type indexedInstance struct {
+	attributes map[string]any
+	address    string
+	path       string
+	err        error
+}
+
+// BuildStateIndex decodes the state files a state path names concurrently,
+// at most concurrency at a time, and indexes their aws_instance resources by
+// ID and resource address. An instance found in several files has its
+// attributes merged; if the files disagree, looking it up fails. State URLs
+// are fetched with httpState.
+func BuildStateIndex(statePath string, concurrency int, httpState HTTPStateConfig) (*StateIndex, error) {
+	paths, err := ExpandStatePaths(statePath)
+	if err != nil {
+		return nil, err
+	}
+	states, err := decodeStateFiles(paths, concurrency, httpState)
+	if err != nil {
+		return nil, err
+	}
+
+	// Merged in path order once every file is decoded, so conflicts are
+	// reported the same way whichever file finished first
+	index := &StateIndex{
+		byID:      make(map[string]*indexedInstance),
+		byAddress: make(map[string][]*indexedInstance),
+	}
+	index.version = statesVersion(states)
+	allocations := elasticIPAllocations(states...)
+	for i, state := range states {
+		for _, inst := range stateInstances(state, "aws_instance") {
+			addElasticIPs(inst.attributes, allocations)
+			index.add(inst, paths[i])
+		}
+	}
+	return index, nil
+}
+
+func (idx *StateIndex) add(inst stateInstance, path string) {
+	address := resourceAddress(inst.resource, inst.instance)
+	entry := &indexedInstance{attributes: inst.attributes, address: address, path: path}
+
+	// Without an index key, "aws_instance.web" also matches the only
+	// instance of a counted resource
+	idx.byAddress[address] = append(idx.byAddress[address], entry)
+	if inst.count == 1 {
+		if base := resourceAddress(inst.resource, nil); base != address {
+			idx.byAddress[base] = append(idx.byAddress[base], entry)
+		}
+	}
+
+	id := stringField(inst.attributes, "id")
+	if id == "" {
+		return
+	}
+	existing, ok := idx.byID[id]
+	if !ok {
+		idx.byID[id] = &indexedInstance{attributes: maps.Clone(inst.attributes), address: address, path: path}
+		return
+	}
+	if existing.err == nil {
+		existing.err = mergeStateAttributes(existing.attributes, inst.attributes, id, existing.path, path)
+	}
+}
+
+// statesVersion joins the lineage and serial of each state, in path order.
+// It is empty if any state lacks a lineage, since its content could then
+// change without its version changing.
+func statesVersion(states []map[string]any) string {
+	versions := make([]string, len(states))
+	for i, state := range states {
+		lineage := stringField(state, "lineage")
+		if lineage == "" {
+			return ""
+		}
+		serial, _ := state["serial"].(float64)
+		versions[i] = fmt.Sprintf("%s@%d", lineage, int64(serial))
+	}
+	return strings.Join(versions, ",")
+}
+
+// Version identifies the indexed state files by their lineages and serials,
+// which Terraform changes whenever it writes new state. It is empty when the
+// state files don't record a lineage.
+func (idx *StateIndex) Version() string {
+	return idx.version
+}
+
+// Lookup finds an instance by ID, falling back to its resource address for
+// state without IDs, and returns a deep copy of its attributes with its
+// address, so callers can modify nested blocks and tags too
+func (idx *StateIndex) Lookup(instanceID string) (map[string]any, string, error) {
+	if entry, ok := idx.byID[instanceID]; ok {
+		if entry.err != nil {
+			return nil, "", entry.err
+		}
+		return copyValue(entry.attributes).(map[string]any), entry.address, nil
+	}
+
+	entries := idx.byAddress[instanceID]
+	if len(entries) == 0 {
+		return nil, "", fmt.Errorf("instance %s %w", instanceID, errInstanceNotFound)
+	}
+	attributes := maps.Clone(entries[0].attributes)
+	for _, entry := range entries[1:] {
+		if err := mergeStateAttributes(attributes, entry.attributes, instanceID, entries[0].path, entry.path); err != nil {
+			return nil, "", err
+		}
+	}
+	return copyValue(attributes).(map[string]any), entries[0].address, nil
+}
+
+// copyValue deep-copies the maps and lists of a decoded JSON value
+func copyValue(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(value))
+		for k, item := range value {
+			copied[k] = copyValue(item)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(value))
+		for i, item := range value {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	default:
+		return value
+	}
+}
@@ -0,0 +1,165 @@
+package terraform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBuildStateIndex_MergesStates(t *testing.T) {
+	dir := t.TempDir()
+	web := writeStateFile(t, dir, "web.tfstate", instanceResource("web", `{"id": "i-web", "instance_type": "t3.micro"}`))
+	writeStateFile(t, dir, "db.tfstate", instanceResource("db", `{"id": "i-db", "instance_type": "r5.large"}`))
+	writeStateFile(t, dir, "partial.tfstate", instanceResource("web", `{"id": "i-web", "ami": "ami-123"}`)+","+instanceResource("legacy", `{"instance_type": "t2.micro"}`))
+
+	index, err := BuildStateIndex(filepath.Join(dir, "*.tfstate"), 2, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("BuildStateIndex() error = %v", err)
+	}
+
+	attributes, address, err := index.Lookup("i-web")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if address != "aws_instance.web" || attributes["instance_type"] != "t3.micro" || attributes["ami"] != "ami-123" {
+		t.Errorf("Lookup() = %v, %q, want instance_type and ami merged", attributes, address)
+	}
+
+	// Lookups return copies, so callers can add attributes
+	attributes["instance_state"] = "running"
+	if again, _, _ := index.Lookup("i-web"); again["instance_state"] != nil {
+		t.Errorf("Lookup() shares attributes between callers: %v", again)
+	}
+
+	if attributes, _, err := index.Lookup("aws_instance.legacy"); err != nil || attributes["instance_type"] != "t2.micro" {
+		t.Errorf("Lookup() by address = %v, %v, want the legacy instance", attributes, err)
+	}
+
+	if _, _, err := index.Lookup("i-missing"); !errors.Is(err, errInstanceNotFound) {
+		t.Errorf("Lookup() error = %v, want not found", err)
+	}
+
+	if _, err := BuildStateIndex(web+","+filepath.Join(dir, "missing.tfstate"), 2, DefaultHTTPState()); err == nil {
+		t.Error("BuildStateIndex() with a missing file succeeded")
+	}
+}
+
+func TestBuildStateIndex_Conflicts(t *testing.T) {
+	dir := t.TempDir()
+	a := writeStateFile(t, dir, "a.tfstate", instanceResource("web", `{"id": "i-web", "instance_type": "t3.micro"}`)+","+instanceResource("db", `{"id": "i-db"}`))
+	b := writeStateFile(t, dir, "b.tfstate", instanceResource("web", `{"id": "i-web", "instance_type": "t3.large"}`))
+
+	index, err := BuildStateIndex(a+","+b, 2, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("BuildStateIndex() error = %v", err)
+	}
+
+	want := fmt.Sprintf("instance i-web has conflicting instance_type in %s and %s", a, b)
+	if _, _, err := index.Lookup("i-web"); err == nil || err.Error() != want {
+		t.Errorf("Lookup() error = %v, want %q", err, want)
+	}
+	// Only the conflicting instance fails
+	if _, _, err := index.Lookup("i-db"); err != nil {
+		t.Errorf("Lookup() error = %v, want i-db", err)
+	}
+}
+
+func TestStateIndex_ConcurrentLookups(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("web%d", i)
+		paths = append(paths, writeStateFile(t, dir, name+".tfstate", instanceResource(name, `{"id": "i-`+name+`"}`)))
+	}
+
+	index, err := BuildStateIndex(strings.Join(paths, ","), 4, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("BuildStateIndex() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			attributes, _, err := index.Lookup(fmt.Sprintf("i-web%d", i))
+			if err != nil {
+				t.Errorf("Lookup() error = %v", err)
+				return
+			}
+			attributes["instance_state"] = "running"
+		}()
+	}
+	wg.Wait()
+}
+
+func TestStateIndex_LookupCopiesNestedValues(t *testing.T) {
+	dir := t.TempDir()
+	path := writeStateFile(t, dir, "web.tfstate", instanceResource("web", `{"id": "i-web", "tags": {"Name": "web"}, "ebs_block_device": [{"device_name": "/dev/sdf"}]}`))
+
+	index, err := BuildStateIndex(path, 1, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("BuildStateIndex() error = %v", err)
+	}
+
+	attributes, _, err := index.Lookup("i-web")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	attributes["tags"].(map[string]any)["Name"] = "changed"
+	attributes["ebs_block_device"].([]any)[0].(map[string]any)["device_name"] = "/dev/sdg"
+
+	attributes, _, err = index.Lookup("i-web")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if name := attributes["tags"].(map[string]any)["Name"]; name != "web" {
+		t.Errorf("tags.Name = %v, want web", name)
+	}
+	if device := attributes["ebs_block_device"].([]any)[0].(map[string]any)["device_name"]; device != "/dev/sdf" {
+		t.Errorf("ebs_block_device.0.device_name = %v, want /dev/sdf", device)
+	}
+}
+
+func TestStateIndex_Version(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "terraform.tfstate")
+	writeState := func(serial int) {
+		state := fmt.Sprintf(`{"version": 4, "serial": %d, "lineage": "abc", "resources": [%s]}`,
+			serial, instanceResource("web", `{"id": "i-web"}`))
+		if err := os.WriteFile(path, []byte(state), 0644); err != nil {
+			t.Fatalf("Failed to write state: %v", err)
+		}
+	}
+
+	writeState(7)
+	index, err := BuildStateIndex(path, 1, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("BuildStateIndex() error = %v", err)
+	}
+	if got := index.Version(); got != "abc@7" {
+		t.Errorf("Version() = %q, want abc@7", got)
+	}
+
+	writeState(8)
+	index, err = BuildStateIndex(path, 1, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("BuildStateIndex() error = %v", err)
+	}
+	if got := index.Version(); got != "abc@8" {
+		t.Errorf("Version() = %q after a new serial, want abc@8", got)
+	}
+
+	// Without a lineage, state can't be told apart across writes
+	index, err = BuildStateIndex(writeStateFile(t, dir, "bare.tfstate", ""), 1, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("BuildStateIndex() error = %v", err)
+	}
+	if got := index.Version(); got != "" {
+		t.Errorf("Version() = %q without a lineage, want empty", got)
+	}
+}
@@ -0,0 +1,110 @@
+package terraform
+
+import "sort"
+
+// StateInstanceIDs returns the IDs of all aws_instance resources in the state
+// files of a state path, sorted, so every managed instance can be checked
+// without listing them
+func StateInstanceIDs(path string, httpState HTTPStateConfig) ([]string, error) {
+	states, err := decodeStates(path, httpState)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, state := range states {
+		for _, id := range stateInstanceIDs(state) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// stateInstanceIDs returns the sorted IDs of the aws_instance resources in a
+// decoded state
+func stateInstanceIDs(state map[string]any) []string {
+	var ids []string
+	for _, inst := range stateInstances(state, "aws_instance") {
+		if id := stringField(inst.attributes, "id"); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+// StateResource identifies a resource in state by ID and resource address
+type StateResource struct {
+	ID      string
+	Address string
+	// Attributes are the resource's attributes in state, as returned by
+	// StateResourcesOfType
+	Attributes map[string]any
+}
+
+// StateResources returns the ID and address of every aws_instance in the
+// state files of a state path, sorted by address
+func StateResources(path string, httpState HTTPStateConfig) ([]StateResource, error) {
+	states, err := decodeStates(path, httpState)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []StateResource
+	seen := make(map[[2]string]bool)
+	for _, state := range states {
+		for _, inst := range stateInstances(state, "aws_instance") {
+			id := stringField(inst.attributes, "id")
+			address := resourceAddress(inst.resource, inst.instance)
+			key := [2]string{id, address}
+			if id != "" && !seen[key] {
+				seen[key] = true
+				resources = append(resources, StateResource{ID: id, Address: address})
+			}
+		}
+	}
+
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Address < resources[j].Address })
+	return resources, nil
+}
+
+// StateResourcesOfType returns every instance of a managed resource type in
+// the state files of a state path, with its attributes, sorted by address.
+// An address found in several files is returned once, from the first file,
+// and instances without an ID are left out.
+func StateResourcesOfType(path, resourceType string, httpState HTTPStateConfig) ([]StateResource, error) {
+	states, err := decodeStates(path, httpState)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []StateResource
+	seen := make(map[string]bool)
+	for _, state := range states {
+		for _, inst := range stateInstances(state, resourceType) {
+			if mode := stringField(inst.resource, "mode"); mode != "" && mode != "managed" {
+				continue
+			}
+			id := stringField(inst.attributes, "id")
+			address := resourceAddress(inst.resource, inst.instance)
+			if id == "" || seen[address] {
+				continue
+			}
+			seen[address] = true
+			resources = append(resources, StateResource{
+				ID:         id,
+				Address:    address,
+				Attributes: inst.attributes,
+			})
+		}
+	}
+
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Address < resources[j].Address })
+	return resources, nil
+}
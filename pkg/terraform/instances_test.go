@@ -0,0 +1,101 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStateInstanceIDs(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "terraform.tfstate")
+	state := `{
+		"version": 4,
+		"resources": [
+			{
+				"type": "aws_instance",
+				"name": "web",
+				"instances": [
+					{"index_key": 0, "attributes": {"id": "i-0bbb"}},
+					{"index_key": 1, "attributes": {"id": "i-0aaa"}}
+				]
+			},
+			{
+				"type": "aws_security_group",
+				"name": "web",
+				"instances": [{"attributes": {"id": "sg-0123"}}]
+			}
+		]
+	}`
+	if err := os.WriteFile(statePath, []byte(state), 0644); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	ids, err := StateInstanceIDs(statePath, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"i-0aaa", "i-0bbb"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v but got %v", expected, ids)
+	}
+}
+
+func TestStateResourcesOfType(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "terraform.tfstate")
+	state := `{
+		"version": 4,
+		"resources": [
+			{
+				"mode": "managed",
+				"type": "aws_eip",
+				"name": "web",
+				"instances": [{"attributes": {"id": "eipalloc-2", "public_ip": "203.0.113.2"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_eip",
+				"name": "nat",
+				"module": "module.vpc",
+				"instances": [{"index_key": 0, "attributes": {"id": "eipalloc-1", "public_ip": "203.0.113.1"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_eip",
+				"name": "pending",
+				"instances": [{"attributes": {"id": ""}}]
+			},
+			{
+				"mode": "data",
+				"type": "aws_eip",
+				"name": "lookup",
+				"instances": [{"attributes": {"id": "eipalloc-3"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_instance",
+				"name": "web",
+				"instances": [{"attributes": {"id": "i-0aaa"}}]
+			}
+		]
+	}`
+	if err := os.WriteFile(statePath, []byte(state), 0644); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	resources, err := StateResourcesOfType(statePath, "aws_eip", DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resources) != 2 {
+		t.Fatalf("expected 2 managed aws_eip resources, got %v", resources)
+	}
+	if resources[0].Address != "aws_eip.web" || resources[0].ID != "eipalloc-2" {
+		t.Errorf("unexpected first resource %+v", resources[0])
+	}
+	if resources[1].Address != "module.vpc.aws_eip.nat[0]" || resources[1].Attributes["public_ip"] != "203.0.113.1" {
+		t.Errorf("unexpected second resource %+v", resources[1])
+	}
+}
@@ -0,0 +1,81 @@
+package terraform
+
+import (
+	"sort"
+	"strings"
+)
+
+// MergeConfig fills attributes that are missing or null in the state config
+// from the HCL config, so state takes precedence. It returns the names of the
+// attributes taken from HCL.
+func MergeConfig(stateConfig, hclConfig map[string]any) []string {
+	var filled []string
+	for name, value := range hclConfig {
+		if current, ok := stateConfig[name]; ok && current != nil {
+			continue
+		}
+		stateConfig[name] = value
+		filled = append(filled, name)
+	}
+
+	sort.Strings(filled)
+	return filled
+}
+
+// ConfigAddress returns the address a state resource is declared with in its
+// module's configuration, without the module path or instance key, e.g.
+// module.app.aws_instance.web["blue"] becomes aws_instance.web. Use
+// ParseModuleConfig to find it in the right module.
+func ConfigAddress(address string) string {
+	_, address = splitModulePath(address)
+	if i := strings.Index(address, "["); i >= 0 {
+		address = address[:i]
+	}
+	return address
+}
+
+// TrimBlockFields drops the fields of AWS blocks, such as root_block_device
+// or network_interface, that the HCL config's blocks of the same type don't
+// set. HCL sets only some fields of a block and Terraform computes the rest
+// (e.g. volume_id), so comparing against HCL alone must ignore them.
+func TrimBlockFields(awsConfig, hclConfig map[string]any) {
+	for name, value := range hclConfig {
+		blocks, ok := value.([]any)
+		if !ok {
+			continue
+		}
+		fields := make(map[string]bool)
+		for _, block := range blocks {
+			block, ok := block.(map[string]any)
+			if !ok {
+				fields = nil
+				break
+			}
+			for field := range block {
+				fields[field] = true
+			}
+		}
+		if fields == nil {
+			continue
+		}
+
+		if awsBlocks, ok := awsConfig[name].([]map[string]any); ok {
+			trimmed := make([]map[string]any, 0, len(awsBlocks))
+			for _, block := range awsBlocks {
+				trimmed = append(trimmed, trimFields(block, fields))
+			}
+			awsConfig[name] = trimmed
+		}
+	}
+}
+
+// trimFields returns a copy of block with only the given fields
+func trimFields(block map[string]any, fields map[string]bool) map[string]any {
+	trimmed := make(map[string]any, len(fields))
+	for field, value := range block {
+		if fields[field] {
+			trimmed[field] = value
+		}
+	}
+	return trimmed
+}
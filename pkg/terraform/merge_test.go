@@ -0,0 +1,179 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+func TestMergeConfig(t *testing.T) {
+	stateConfig := map[string]any{
+		"instance_type": "t2.micro",
+		"user_data":     nil,
+	}
+	hclConfig := map[string]any{
+		"instance_type": "t2.small",
+		"user_data":     "abc123",
+		"monitoring":    true,
+	}
+
+	filled := MergeConfig(stateConfig, hclConfig)
+
+	if expected := []string{"monitoring", "user_data"}; !reflect.DeepEqual(filled, expected) {
+		t.Errorf("expected filled attributes %v but got %v", expected, filled)
+	}
+	if stateConfig["instance_type"] != "t2.micro" {
+		t.Errorf("expected state value to take precedence but got %v", stateConfig["instance_type"])
+	}
+	if stateConfig["user_data"] != "abc123" {
+		t.Errorf("expected null state value to be filled from HCL but got %v", stateConfig["user_data"])
+	}
+}
+
+func TestConfigAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected string
+	}{
+		{address: "aws_instance.web", expected: "aws_instance.web"},
+		{address: "aws_instance.web[0]", expected: "aws_instance.web"},
+		{address: `module.app.aws_instance.web["blue"]`, expected: "aws_instance.web"},
+		{address: `module.app["a.b"].module.db[0].aws_instance.web`, expected: "aws_instance.web"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if got := ConfigAddress(tt.address); got != tt.expected {
+				t.Errorf("expected %s but got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTrimBlockFields(t *testing.T) {
+	awsConfig := map[string]any{
+		"root_block_device": []map[string]any{
+			{"device_name": "/dev/xvda", "volume_id": "vol-1", "volume_size": int32(8), "volume_type": "gp3"},
+		},
+		"ebs_block_device": []map[string]any{
+			{"device_name": "/dev/sdf", "volume_id": "vol-2"},
+		},
+		"vpc_security_group_ids": []string{"sg-1"},
+	}
+	hclConfig := map[string]any{
+		"root_block_device":      []any{map[string]any{"volume_type": "gp3"}},
+		"vpc_security_group_ids": []any{"sg-1"},
+	}
+
+	TrimBlockFields(awsConfig, hclConfig)
+
+	expected := map[string]any{
+		"root_block_device": []map[string]any{{"volume_type": "gp3"}},
+		"ebs_block_device": []map[string]any{
+			{"device_name": "/dev/sdf", "volume_id": "vol-2"},
+		},
+		"vpc_security_group_ids": []string{"sg-1"},
+	}
+	if !reflect.DeepEqual(awsConfig, expected) {
+		t.Errorf("expected %v but got %v", expected, awsConfig)
+	}
+}
+
+func TestTrimBlockFields_RootBlockDeviceFromHCL(t *testing.T) {
+	awsConfig := func() map[string]any {
+		return map[string]any{
+			"root_block_device": []map[string]any{
+				{"device_name": "/dev/xvda", "volume_id": "vol-1", "volume_size": int32(8), "volume_type": "gp3"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		hcl       string
+		wantDrift bool
+	}{
+		{
+			name: "matching volume",
+			hcl: `
+			resource "aws_instance" "web" {
+				root_block_device {
+					volume_size = 8
+					volume_type = "gp3"
+				}
+			}
+			`,
+		},
+		{
+			name: "resized volume",
+			hcl: `
+			resource "aws_instance" "web" {
+				root_block_device {
+					volume_size = 20
+					volume_type = "gp3"
+				}
+			}
+			`,
+			wantDrift: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hclConfig, err := ParseHCLConfig(writeHCL(t, tt.hcl), "aws_instance.web")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actual := awsConfig()
+			TrimBlockFields(actual, hclConfig)
+
+			drifts, err := drift.DetectDrift(actual, hclConfig, []string{"root_block_device"}, drift.Options{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, drifted := drifts["root_block_device"]; drifted != tt.wantDrift {
+				t.Errorf("expected drift %v but got %v", tt.wantDrift, drifts)
+			}
+		})
+	}
+}
+
+func TestTrimBlockFields_NetworkInterfaceFromHCL(t *testing.T) {
+	hclConfig, err := ParseHCLConfig(writeHCL(t, `
+	resource "aws_instance" "web" {
+		instance_type = "t3.micro"
+
+		network_interface {
+			network_interface_id = "eni-1"
+			device_index         = 0
+		}
+	}
+	`), "aws_instance.web")
+	if err != nil {
+		t.Fatalf("expected the instance to be found despite the block but got: %v", err)
+	}
+
+	awsConfig := map[string]any{
+		"instance_type": "t3.micro",
+		"network_interface": []map[string]any{
+			{
+				"network_interface_id":  "eni-1",
+				"device_index":          int32(0),
+				"delete_on_termination": true,
+				"subnet_id":             "subnet-1",
+				"private_ips":           []string{"10.0.0.4"},
+				"security_groups":       []string{"sg-1"},
+			},
+		},
+	}
+	TrimBlockFields(awsConfig, hclConfig)
+
+	drifts, err := drift.DetectDrift(awsConfig, hclConfig, []string{"instance_type", "network_interface"}, drift.Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("expected no drift but got %v", drifts)
+	}
+}
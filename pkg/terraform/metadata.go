@@ -0,0 +1,51 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// StateMetadata holds the top-level bookkeeping fields of a state file
+type StateMetadata struct {
+	Version          int    `json:"version"`
+	TerraformVersion string `json:"terraform_version"`
+	Serial           int64  `json:"serial"`
+	Lineage          string `json:"lineage"`
+}
+
+// ReadStateMetadata reads the version, serial and lineage of a state file,
+// fetching state URLs with httpState
+func ReadStateMetadata(path string, httpState HTTPStateConfig) (StateMetadata, error) {
+	var metadata StateMetadata
+
+	file, err := openState(path, httpState)
+	if err != nil {
+		return metadata, err
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&metadata); err != nil {
+		return metadata, fmt.Errorf("failed to parse state file: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// StalenessWarnings returns a warning for each way the state looks stale:
+// a serial lower than minSerial or a terraform_version other than
+// expectedVersion. Zero values disable the corresponding check.
+func (m StateMetadata) StalenessWarnings(minSerial int64, expectedVersion string) []string {
+	var warnings []string
+
+	if minSerial > 0 && m.Serial < minSerial {
+		warnings = append(warnings, fmt.Sprintf(
+			"state serial %d is older than expected serial %d; the state may be stale", m.Serial, minSerial))
+	}
+
+	if expectedVersion != "" && m.TerraformVersion != expectedVersion {
+		warnings = append(warnings, fmt.Sprintf(
+			"state was written by Terraform %s but %s was expected", m.TerraformVersion, expectedVersion))
+	}
+
+	return warnings
+}
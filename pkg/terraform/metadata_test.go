@@ -0,0 +1,51 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadStateMetadata(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "terraform.tfstate")
+	content := `{"version": 4, "terraform_version": "1.5.7", "serial": 42, "lineage": "abc", "resources": []}`
+	if err := os.WriteFile(statePath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	metadata, err := ReadStateMetadata(statePath, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := StateMetadata{Version: 4, TerraformVersion: "1.5.7", Serial: 42, Lineage: "abc"}
+	if metadata != expected {
+		t.Errorf("expected %+v but got %+v", expected, metadata)
+	}
+}
+
+func TestStalenessWarnings(t *testing.T) {
+	metadata := StateMetadata{TerraformVersion: "1.5.7", Serial: 42}
+
+	tests := []struct {
+		name            string
+		minSerial       int64
+		expectedVersion string
+		expectWarnings  int
+	}{
+		{name: "No checks", expectWarnings: 0},
+		{name: "Serial is current", minSerial: 42, expectWarnings: 0},
+		{name: "Serial is stale", minSerial: 50, expectWarnings: 1},
+		{name: "Version matches", expectedVersion: "1.5.7", expectWarnings: 0},
+		{name: "Stale serial and version mismatch", minSerial: 50, expectedVersion: "1.6.0", expectWarnings: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := metadata.StalenessWarnings(tt.minSerial, tt.expectedVersion)
+			if len(warnings) != tt.expectWarnings {
+				t.Errorf("expected %d warnings but got %d: %v", tt.expectWarnings, len(warnings), warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ParseModuleConfig finds a resource from state in HCL configuration by its
+// full address, e.g. module.app.aws_instance.web["blue"]. Terraform reads a
+// module from the .tf files of a single directory, so only those are
+// searched: configPath for the root module, and the local source of each
+// module block for resources in modules. A resource with the same name in
+// another module is never matched.
+func ParseModuleConfig(configPath, address string) (map[string]any, error) {
+	path, _ := splitModulePath(address)
+	files, err := moduleFiles(configPath, path)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	for _, file := range files {
+		if _, diags := parser.ParseHCLFile(file); diags.HasErrors() {
+			return nil, fmt.Errorf("failed to parse HCL file %s: %v", file, diags)
+		}
+	}
+
+	config, _, err := extractInstanceConfig(parser, ConfigAddress(address))
+	return config, err
+}
+
+// splitModulePath splits a resource address into the names of its modules,
+// outermost first, and the address within the innermost module, e.g.
+// module.app["blue"].module.db.aws_instance.web into [app db] and
+// aws_instance.web
+func splitModulePath(address string) ([]string, string) {
+	var names []string
+	rest := address
+	for strings.HasPrefix(rest, "module.") {
+		name := strings.TrimPrefix(rest, "module.")
+		end := strings.IndexAny(name, ".[")
+		if end < 0 {
+			break
+		}
+		names = append(names, name[:end])
+		rest = strings.TrimPrefix(skipInstanceKey(name[end:]), ".")
+	}
+	return names, rest
+}
+
+// skipInstanceKey removes the instance key, such as [0] or ["a.b"], that rest
+// starts with, if any
+func skipInstanceKey(rest string) string {
+	if !strings.HasPrefix(rest, "[") {
+		return rest
+	}
+	closing := "]"
+	if strings.HasPrefix(rest, `["`) {
+		closing = `"]`
+	}
+	if i := strings.Index(rest, closing); i >= 0 {
+		return rest[i+len(closing):]
+	}
+	return ""
+}
+
+// moduleFiles returns the .tf files of the module at a module path,
+// following the local source of each module block from configPath, which is
+// the root module's directory or a single .tf file in it
+func moduleFiles(configPath string, path []string) ([]string, error) {
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat config path: %w", err)
+	}
+	dir := configPath
+	if !info.IsDir() {
+		if len(path) == 0 {
+			return []string{configPath}, nil
+		}
+		dir = filepath.Dir(configPath)
+	}
+
+	for _, name := range path {
+		source, err := moduleSource(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(dir, source)
+	}
+	return tfFiles(dir)
+}
+
+// moduleSource returns the local source of the module block called name in
+// the module at dir
+func moduleSource(dir, name string) (string, error) {
+	files, err := tfFiles(dir)
+	if err != nil {
+		return "", err
+	}
+
+	parser := hclparse.NewParser()
+	for _, file := range files {
+		parsed, diags := parser.ParseHCLFile(file)
+		if diags.HasErrors() {
+			return "", fmt.Errorf("failed to parse HCL file %s: %v", file, diags)
+		}
+		content, _, _ := parsed.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{{Type: "module", LabelNames: []string{"name"}}},
+		})
+		for _, block := range content.Blocks {
+			if block.Labels[0] != name {
+				continue
+			}
+			attrs, _, _ := block.Body.PartialContent(&hcl.BodySchema{
+				Attributes: []hcl.AttributeSchema{{Name: "source"}},
+			})
+			attr, ok := attrs.Attributes["source"]
+			if !ok {
+				return "", fmt.Errorf("module %s in %s has no source", name, dir)
+			}
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+				return "", fmt.Errorf("module %s in %s has no literal source", name, dir)
+			}
+			source := val.AsString()
+			if !strings.HasPrefix(source, "./") && !strings.HasPrefix(source, "../") {
+				return "", fmt.Errorf("module %s has source %s, but only local module sources can be read", name, source)
+			}
+			return source, nil
+		}
+	}
+	return "", fmt.Errorf("no module %s in %s", name, dir)
+}
+
+// tfFiles returns the .tf files directly in dir, which make up a module
+func tfFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .tf files found in %s", dir)
+	}
+	return files, nil
+}
@@ -0,0 +1,91 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitModulePath(t *testing.T) {
+	tests := []struct {
+		address  string
+		modules  []string
+		resource string
+	}{
+		{address: "aws_instance.web", modules: nil, resource: "aws_instance.web"},
+		{address: `module.app.aws_instance.web["blue"]`, modules: []string{"app"}, resource: `aws_instance.web["blue"]`},
+		{address: `module.app["a.b"].module.db[0].aws_instance.web`, modules: []string{"app", "db"}, resource: "aws_instance.web"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			modules, resource := splitModulePath(tt.address)
+			if !reflect.DeepEqual(modules, tt.modules) || resource != tt.resource {
+				t.Errorf("splitModulePath() = %v, %s, want %v, %s", modules, resource, tt.modules, tt.resource)
+			}
+		})
+	}
+}
+
+func TestParseModuleConfig(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.tf": `resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+}
+
+module "app" {
+  source = "./modules/app"
+}
+
+module "registry" {
+  source = "terraform-aws-modules/ec2-instance/aws"
+}
+`,
+		"modules/app/main.tf": `resource "aws_instance" "web" {
+  instance_type = "t3.large"
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		address      string
+		instanceType string
+		wantErr      string
+	}{
+		{address: "aws_instance.web", instanceType: "t3.micro"},
+		{address: "aws_instance.web[0]", instanceType: "t3.micro"},
+		{address: `module.app.aws_instance.web["blue"]`, instanceType: "t3.large"},
+		{address: "module.registry.aws_instance.this", wantErr: "only local module sources"},
+		{address: "module.missing.aws_instance.web", wantErr: "no module missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			config, err := ParseModuleConfig(root, tt.address)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseModuleConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseModuleConfig() error = %v", err)
+			}
+			if config["instance_type"] != tt.instanceType {
+				t.Errorf("instance_type = %v, want %s", config["instance_type"], tt.instanceType)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// stateInstance is one resource instance found in a decoded state file
+type stateInstance struct {
+	resource   map[string]any
+	instance   map[string]any
+	attributes map[string]any
+	// count is the number of instances of the resource
+	count int
+}
+
+// decodeState decodes and validates a state document, which may also be
+// `terraform show -json` output
+func decodeState(r io.Reader) (map[string]any, error) {
+	var rawState map[string]any
+	if err := json.NewDecoder(r).Decode(&rawState); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	if rawState == nil {
+		return nil, fmt.Errorf("invalid state file: expected a JSON object, got null")
+	}
+	if isShowOutput(rawState) {
+		var err error
+		if rawState, err = stateFromShowOutput(rawState); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateState(rawState); err != nil {
+		return nil, err
+	}
+	return rawState, nil
+}
+
+// asObject asserts that v, found at path in the state, is a JSON object
+func asObject(v any, path string) (map[string]any, error) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid state file: %s must be an object, got %s", path, jsonType(v))
+	}
+	return obj, nil
+}
+
+// asArray asserts that v, found at path in the state, is a JSON array
+func asArray(v any, path string) ([]any, error) {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid state file: %s must be an array, got %s", path, jsonType(v))
+	}
+	return arr, nil
+}
+
+// stringField returns obj[key] if it is a string, or ""
+func stringField(obj map[string]any, key string) string {
+	s, _ := obj[key].(string)
+	return s
+}
+
+// jsonType names the JSON type of a decoded value for error messages
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// stateInstances returns every instance of resources of resourceType that
+// has attributes, skipping anything that isn't shaped like a state resource
+func stateInstances(state map[string]any, resourceType string) []stateInstance {
+	resources, _ := state["resources"].([]any)
+
+	var found []stateInstance
+	for _, res := range resources {
+		resource, ok := res.(map[string]any)
+		if !ok || stringField(resource, "type") != resourceType {
+			continue
+		}
+
+		instances, _ := resource["instances"].([]any)
+		for _, inst := range instances {
+			instance, ok := inst.(map[string]any)
+			if !ok {
+				continue
+			}
+			attributes, ok := instance["attributes"].(map[string]any)
+			if !ok {
+				continue
+			}
+			found = append(found, stateInstance{
+				resource:   resource,
+				instance:   instance,
+				attributes: attributes,
+				count:      len(instances),
+			})
+		}
+	}
+
+	return found
+}
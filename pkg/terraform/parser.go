@@ -1,24 +1,46 @@
 package terraform
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/katungi/aws-terror/aws"
 	"github.com/katungi/aws-terror/pkg/progress"
 	"github.com/zclconf/go-cty/cty"
 
-	"encoding/json"
-
 	tfjson "github.com/hashicorp/terraform-json"
 )
 
-func ParseStateFile(filepath, instanceID string) (map[string]any, error) {
+// errInstanceNotFound is returned when no state file has the instance
+var errInstanceNotFound = errors.New("not found in Terraform state")
+
+func ParseStateFile(filepath, instanceID string, httpState HTTPStateConfig) (map[string]any, error) {
+	attributes, _, err := ParseStateFileWithAddress(filepath, instanceID, httpState)
+	return attributes, err
+}
+
+// ParseStateFileWithAddress finds an instance in a state file by ID or
+// resource address, returning its attributes and resource address. The state
+// path may name several state files (see ExpandStatePaths); an instance found
+// in more than one of them has its attributes merged. State URLs are fetched
+// with httpState.
+func ParseStateFileWithAddress(filepath, instanceID string, httpState HTTPStateConfig) (map[string]any, string, error) {
 	if filepath == "" || instanceID == "" {
-		return nil, fmt.Errorf("filepath and instanceID must not be empty")
+		return nil, "", fmt.Errorf("filepath and instanceID must not be empty")
+	}
+
+	paths, err := ExpandStatePaths(filepath)
+	if err != nil {
+		return nil, "", err
 	}
 
 	// Initialize progress spinner
@@ -26,63 +48,123 @@ func ParseStateFile(filepath, instanceID string) (map[string]any, error) {
 	s.Start()
 	defer s.Stop()
 
-	file, err := os.Open(filepath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open state file: %w", err)
+	var attributes map[string]any
+	var address, foundIn string
+	for _, path := range paths {
+		file, err := openState(path, httpState)
+		if err != nil {
+			s.Error(fmt.Sprintf("Failed to read state: %v", err))
+			return nil, "", err
+		}
+
+		s.UpdateMessage("Analyzing state file contents")
+		found, foundAddress, err := parseState(file, instanceID)
+		file.Close()
+		if errors.Is(err, errInstanceNotFound) && len(paths) > 1 {
+			continue
+		}
+		if err != nil {
+			if len(paths) > 1 {
+				err = fmt.Errorf("%s: %w", path, err)
+			}
+			s.Error(err.Error())
+			return nil, "", err
+		}
+
+		if attributes == nil {
+			attributes, address, foundIn = found, foundAddress, path
+			continue
+		}
+		if err := mergeStateAttributes(attributes, found, instanceID, foundIn, path); err != nil {
+			s.Error(err.Error())
+			return nil, "", err
+		}
+	}
+
+	if attributes == nil {
+		err := fmt.Errorf("instance %s %w", instanceID, errInstanceNotFound)
+		s.Error(err.Error())
+		return nil, "", err
 	}
-	defer file.Close()
 
-	// Parse raw JSON first to handle the actual state file structure
-	var rawState map[string]any
-	if err := json.NewDecoder(file).Decode(&rawState); err != nil {
-		s.Error(fmt.Sprintf("Failed to parse state file: %v", err))
-		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	if attributes["id"] == instanceID {
+		s.Success("Successfully parsed Terraform state file")
+	} else {
+		s.Success(fmt.Sprintf("Matched %s by resource address %s", instanceID, address))
 	}
-	s.UpdateMessage("Analyzing state file contents")
+	return attributes, address, nil
+}
 
-	// Navigate through the state structure
-	resources, ok := rawState["resources"].([]any)
-	if !ok {
-		return nil, fmt.Errorf("invalid state file: resources not found or invalid format")
+// ParseState finds an instance in a state document read from r, e.g. piped
+// from terraform state pull
+func ParseState(r io.Reader, instanceID string) (map[string]any, error) {
+	attributes, _, err := parseState(r, instanceID)
+	return attributes, err
+}
+
+func parseState(r io.Reader, instanceID string) (map[string]any, string, error) {
+	rawState, err := decodeState(r)
+	if err != nil {
+		return nil, "", err
 	}
+	instances := stateInstances(rawState, "aws_instance")
+
+	// Find the instance by its ID, falling back to its resource address for
+	// state without IDs (e.g. after a refactor with moved blocks)
+	attributes, address := findStateInstance(instances, instanceID, false)
+	if attributes == nil {
+		attributes, address = findStateInstance(instances, instanceID, true)
+	}
+	if attributes != nil {
+		addElasticIPs(attributes, elasticIPAllocations(rawState))
+		return attributes, address, nil
+	}
+
+	return nil, "", fmt.Errorf("instance %s %w", instanceID, errInstanceNotFound)
+}
 
-	// Iterate through resources to find the matching instance
-	for _, res := range resources {
-		resource, ok := res.(map[string]any)
-		if !ok {
+// findStateInstance searches aws_instance instances for one whose id
+// attribute (or, with byAddress, whose resource address) equals identifier.
+// It returns the instance attributes and its resource address.
+func findStateInstance(instances []stateInstance, identifier string, byAddress bool) (map[string]any, string) {
+	for _, inst := range instances {
+		address := resourceAddress(inst.resource, inst.instance)
+		if byAddress {
+			// Without an index key, "aws_instance.web" also matches
+			// the only instance of a counted resource
+			baseAddress := resourceAddress(inst.resource, nil)
+			if identifier == address || (inst.count == 1 && identifier == baseAddress) {
+				return inst.attributes, address
+			}
 			continue
 		}
 
-		// Check if this is an AWS instance resource
-		if resourceType, ok := resource["type"].(string); ok && resourceType == "aws_instance" {
-			// Check the instances array
-			instances, ok := resource["instances"].([]any)
-			if !ok || len(instances) == 0 {
-				continue
-			}
+		if id := stringField(inst.attributes, "id"); id != "" && id == identifier {
+			return inst.attributes, address
+		}
+	}
 
-			// Look at the first instance (usually there's only one)
-			instance, ok := instances[0].(map[string]any)
-			if !ok {
-				continue
-			}
+	return nil, ""
+}
 
-			// Get the attributes
-			attributes, ok := instance["attributes"].(map[string]any)
-			if !ok {
-				continue
-			}
+// resourceAddress builds the address of a resource instance in state, e.g.
+// module.app.aws_instance.web["blue"]
+func resourceAddress(resource, instance map[string]any) string {
+	address := stringField(resource, "type") + "." + stringField(resource, "name")
+	if module := stringField(resource, "module"); module != "" {
+		address = module + "." + address
+	}
 
-			// Check if this is the instance we're looking for
-			if id, ok := attributes["id"].(string); ok && id == instanceID {
-				s.Success("Successfully parsed Terraform state file")
-	return attributes, nil
-			}
+	if instance != nil {
+		switch key := instance["index_key"].(type) {
+		case float64:
+			address += fmt.Sprintf("[%d]", int(key))
+		case string:
+			address += fmt.Sprintf("[%q]", key)
 		}
 	}
 
-	s.Error(fmt.Sprintf("Instance %s not found in Terraform state", instanceID))
-	return nil, fmt.Errorf("instance %s not found in Terraform state", instanceID)
+	return address
 }
 
 func findResourceInModule(module *tfjson.StateModule, instanceID string) map[string]any {
@@ -111,13 +193,20 @@ func findResourceInModule(module *tfjson.StateModule, instanceID string) map[str
 }
 
 func ParseHCLConfig(configPath, instanceID string) (map[string]any, error) {
+	config, _, err := ParseHCLConfigWithAddress(configPath, instanceID)
+	return config, err
+}
+
+// ParseHCLConfigWithAddress finds an instance in HCL configuration by ID or
+// resource address, returning its attributes and resource address
+func ParseHCLConfigWithAddress(configPath, instanceID string) (map[string]any, string, error) {
 	if configPath == "" || instanceID == "" {
-		return nil, fmt.Errorf("configPath and instanceID must not be empty")
+		return nil, "", fmt.Errorf("configPath and instanceID must not be empty")
 	}
 
 	fileInfo, err := os.Stat(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to stat config path: %w", err)
+		return nil, "", fmt.Errorf("failed to stat config path: %w", err)
 	}
 
 	var configFiles []string
@@ -137,53 +226,58 @@ func ParseHCLConfig(configPath, instanceID string) (map[string]any, error) {
 			return nil
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to walk config directory: %w", err)
+			return nil, "", fmt.Errorf("failed to walk config directory: %w", err)
 		}
 	} else {
 		// Single file
 		if !strings.HasSuffix(configPath, ".tf") {
-			return nil, fmt.Errorf("config file must have .tf extension")
+			return nil, "", fmt.Errorf("config file must have .tf extension")
 		}
 		absPath, err := filepath.Abs(configPath)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get absolute path: %w", err)
+			return nil, "", fmt.Errorf("failed to get absolute path: %w", err)
 		}
 		configFiles = []string{absPath}
 	}
 
 	if len(configFiles) == 0 {
-		return nil, fmt.Errorf("no .tf files found in %s", configPath)
+		return nil, "", fmt.Errorf("no .tf files found in %s", configPath)
 	}
 
 	parser := hclparse.NewParser()
 	for _, file := range configFiles {
 		_, diags := parser.ParseHCLFile(file)
 		if diags.HasErrors() {
-			return nil, fmt.Errorf("failed to parse HCL file %s: %v", file, diags)
+			return nil, "", fmt.Errorf("failed to parse HCL file %s: %v", file, diags)
 		}
 	}
 
 	// Extract instance configuration from parsed files
-	config, err := extractInstanceConfig(parser, instanceID)
-	if err != nil {
-		return nil, err
-	}
+	return extractInstanceConfig(parser, instanceID)
+}
 
-	return config, nil
+// UnknownAttributesKey holds the HCL attributes, if any, whose values can't
+// be known without running Terraform, such as references to variables or
+// other resources. They are left out of the config rather than compared.
+const UnknownAttributesKey = "unknown_attributes"
+
+// TakeUnknownAttributes removes UnknownAttributesKey from an HCL config and
+// returns the attributes it held
+func TakeUnknownAttributes(config map[string]any) []string {
+	unknown, _ := config[UnknownAttributesKey].([]string)
+	delete(config, UnknownAttributesKey)
+	return unknown
 }
 
-func extractInstanceConfig(parser *hclparse.Parser, instanceID string) (map[string]any, error) {
-	fmt.Println("....Parsing......")
+func extractInstanceConfig(parser *hclparse.Parser, instanceID string) (map[string]any, string, error) {
 	if parser == nil || instanceID == "" {
-		return nil, fmt.Errorf("parser and instanceID must not be nil")
+		return nil, "", fmt.Errorf("parser and instanceID must not be nil")
 	}
 
-	config := make(map[string]any)
-
 	// Get all parsed files
 	files := parser.Files()
 	if len(files) == 0 {
-		return nil, fmt.Errorf("no parsed files available")
+		return nil, "", fmt.Errorf("no parsed files available")
 	}
 
 	// Iterate through all parsed files
@@ -194,7 +288,7 @@ func extractInstanceConfig(parser *hclparse.Parser, instanceID string) (map[stri
 		}
 
 		// Get content blocks
-		content, diags := body.Content(&hcl.BodySchema{
+		content, _, diags := body.PartialContent(&hcl.BodySchema{
 			Blocks: []hcl.BlockHeaderSchema{
 				{
 					Type:       "resource",
@@ -210,50 +304,127 @@ func extractInstanceConfig(parser *hclparse.Parser, instanceID string) (map[stri
 		// Look for aws_instance resources
 		for _, block := range content.Blocks {
 			if block.Type == "resource" && len(block.Labels) >= 2 && block.Labels[0] == "aws_instance" {
-				// Get the instance attributes
-				attrs, diags := block.Body.JustAttributes()
-				if diags.HasErrors() {
-					continue
-				}
-
-				// Check for instance ID in the id attribute
-				if idAttr, exists := attrs["id"]; exists {
-					idVal, diags := idAttr.Expr.Value(nil)
-					if !diags.HasErrors() && idVal.Type() == cty.String && idVal.AsString() == instanceID {
-						// Found matching instance, extract all attributes
-						for name, attr := range attrs {
-							val, diags := attr.Expr.Value(nil)
-							if !diags.HasErrors() {
-								switch {
-								case val.Type() == cty.String:
-									config[name] = val.AsString()
-								case val.Type().IsMapType() || val.Type().IsObjectType():
-									tagsMap := make(map[string]interface{})
-									if val.Type().IsMapType() {
-										for k, v := range val.AsValueMap() {
-											tagsMap[k] = v.AsString()
-										}
-									} else {
-										for k, v := range val.AsValueMap() {
-											tagsMap[k] = v.AsString()
-										}
-									}
-									config[name] = tagsMap
-								case val.Type() == cty.Number:
-									config[name] = val.AsBigFloat()
-								case val.Type() == cty.Bool:
-									config[name] = val.True()
-								default:
-									config[name] = val.AsString()
-								}
-							}
-						}
-						return config, nil
+				// Match on the resource address or the instance ID in the id attribute
+				address := block.Labels[0] + "." + block.Labels[1]
+				config, unknown := bodyValues(block.Body)
+				if instanceID == address || config["id"] == instanceID {
+					// State stores a hash of user_data, so compare against the same
+					if userData, ok := config["user_data"].(string); ok {
+						config["user_data"] = aws.HashUserData(userData)
+					}
+					if len(unknown) > 0 {
+						config[UnknownAttributesKey] = unknown
 					}
+					return config, address, nil
 				}
 			}
 		}
 	}
 
-	return nil, fmt.Errorf("instance %s not found in Terraform configuration", instanceID)
+	return nil, "", fmt.Errorf("instance %s not found in Terraform configuration", instanceID)
+}
+
+// nonAttributeBlocks are resource blocks that configure Terraform itself
+// rather than the instance
+var nonAttributeBlocks = map[string]bool{
+	"connection":  true,
+	"lifecycle":   true,
+	"provisioner": true,
+}
+
+// bodyValues decodes the attributes and nested blocks of a resource body.
+// Nested blocks become lists of maps keyed by block type, as in state.
+// Attributes and blocks whose values reference variables or other resources
+// can't be evaluated here and are returned as unknown instead.
+func bodyValues(body hcl.Body) (map[string]any, []string) {
+	values := make(map[string]any)
+	attrs := make(hcl.Attributes)
+	var blocks []*hclsyntax.Block
+	if syntaxBody, ok := body.(*hclsyntax.Body); ok {
+		for name, attr := range syntaxBody.Attributes {
+			attrs[name] = attr.AsHCLAttribute()
+		}
+		blocks = syntaxBody.Blocks
+	} else if justAttrs, diags := body.JustAttributes(); !diags.HasErrors() {
+		attrs = justAttrs
+	}
+
+	var unknown []string
+	for name, attr := range attrs {
+		val, diags := attr.Expr.Value(nil)
+		if !val.IsWhollyKnown() {
+			unknown = append(unknown, name)
+			continue
+		}
+		// Terraform treats null as unset
+		if val.IsNull() || diags.HasErrors() {
+			continue
+		}
+		values[name] = ctyToGo(val)
+	}
+
+	unknownBlocks := make(map[string]bool)
+	for _, block := range blocks {
+		switch {
+		case nonAttributeBlocks[block.Type]:
+			continue
+		case block.Type == "dynamic":
+			// The content of dynamic blocks depends on for_each
+			if len(block.Labels) > 0 {
+				unknownBlocks[block.Labels[0]] = true
+			}
+			continue
+		}
+		nested, nestedUnknown := bodyValues(block.Body)
+		if len(nestedUnknown) > 0 {
+			unknownBlocks[block.Type] = true
+			continue
+		}
+		list, _ := values[block.Type].([]any)
+		values[block.Type] = append(list, nested)
+	}
+	for blockType := range unknownBlocks {
+		delete(values, blockType)
+		unknown = append(unknown, blockType)
+	}
+
+	sort.Strings(unknown)
+	return values, slices.Compact(unknown)
+}
+
+// ctyToGo converts a known, non-null HCL value to the Go types state
+// attributes of the same kind decode to. Null map and object elements are
+// left out, as Terraform treats them as unset.
+func ctyToGo(val cty.Value) any {
+	ty := val.Type()
+	switch {
+	case ty == cty.String:
+		return val.AsString()
+	case ty == cty.Number:
+		return val.AsBigFloat()
+	case ty == cty.Bool:
+		return val.True()
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		items := make([]any, 0, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			_, item := it.Element()
+			if item.IsNull() {
+				items = append(items, nil)
+				continue
+			}
+			items = append(items, ctyToGo(item))
+		}
+		return items
+	case ty.IsMapType() || ty.IsObjectType():
+		fields := make(map[string]any)
+		for it := val.ElementIterator(); it.Next(); {
+			key, field := it.Element()
+			if !field.IsNull() {
+				fields[key.AsString()] = ctyToGo(field)
+			}
+		}
+		return fields
+	default:
+		return nil
+	}
 }
@@ -2,9 +2,11 @@ package terraform
 
 import (
 	"encoding/json"
+	"math/big"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -80,7 +82,7 @@ func TestParseStateFile(t *testing.T) {
 			}
 
 			// Test ParseStateFile
-			config, err := ParseStateFile(statePath, tt.instanceID)
+			config, err := ParseStateFile(statePath, tt.instanceID, DefaultHTTPState())
 
 			if tt.expectError {
 				if err == nil {
@@ -180,4 +182,267 @@ func TestParseHCLConfig(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestParseHCLConfig_NullAndUnknownValues(t *testing.T) {
+	config, err := ParseHCLConfig(writeHCL(t, `
+	resource "aws_instance" "web" {
+		id            = null
+		instance_type = "t2.micro"
+		ami           = var.ami_id
+		key_name      = null
+		subnet_id     = aws_subnet.private.id
+		tags = {
+			Name  = "web"
+			Owner = null
+		}
+	}
+	`), "aws_instance.web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]any{
+		"instance_type":      "t2.micro",
+		"tags":               map[string]any{"Name": "web"},
+		UnknownAttributesKey: []string{"ami", "subnet_id"},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("expected %v but got %v", want, config)
+	}
+
+	unknown := TakeUnknownAttributes(config)
+	if !reflect.DeepEqual(unknown, []string{"ami", "subnet_id"}) {
+		t.Errorf("expected unknown ami and subnet_id but got %v", unknown)
+	}
+	if _, ok := config[UnknownAttributesKey]; ok {
+		t.Errorf("expected %s to be removed", UnknownAttributesKey)
+	}
+}
+
+func TestParseHCLConfig_TypedTagValues(t *testing.T) {
+	config, err := ParseHCLConfig(writeHCL(t, `
+	resource "aws_instance" "web" {
+		tags = {
+			Name   = "web"
+			Port   = 8080
+			Public = true
+			Owner  = null
+		}
+	}
+	`), "aws_instance.web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tags, ok := config["tags"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected tags map but got %T", config["tags"])
+	}
+	if tags["Name"] != "web" {
+		t.Errorf("expected Name web but got %v", tags["Name"])
+	}
+	if port, ok := tags["Port"].(*big.Float); !ok || port.Cmp(big.NewFloat(8080)) != 0 {
+		t.Errorf("expected Port 8080 as *big.Float but got %#v", tags["Port"])
+	}
+	if tags["Public"] != true {
+		t.Errorf("expected Public true but got %#v", tags["Public"])
+	}
+	if _, ok := tags["Owner"]; ok {
+		t.Errorf("expected null Owner to be absent but got %v", tags["Owner"])
+	}
+}
+
+func TestParseHCLConfig_ListAttributes(t *testing.T) {
+	config, err := ParseHCLConfig(writeHCL(t, `
+	resource "aws_instance" "web" {
+		vpc_security_group_ids = ["sg-1", "sg-2"]
+		secondary_private_ips  = toset(["10.0.0.5"])
+		tags_all               = ["a", 1, true]
+	}
+	`), "aws_instance.web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []any{"sg-1", "sg-2"}; !reflect.DeepEqual(config["vpc_security_group_ids"], want) {
+		t.Errorf("expected vpc_security_group_ids %v but got %#v", want, config["vpc_security_group_ids"])
+	}
+	if want := []string{"secondary_private_ips"}; !reflect.DeepEqual(config[UnknownAttributesKey], want) {
+		t.Errorf("expected function calls to be unknown %v but got %v", want, config[UnknownAttributesKey])
+	}
+	tuple, ok := config["tags_all"].([]any)
+	if !ok || len(tuple) != 3 || tuple[0] != "a" || tuple[2] != true {
+		t.Fatalf("expected tuple [a 1 true] but got %#v", config["tags_all"])
+	}
+	if n, ok := tuple[1].(*big.Float); !ok || n.Cmp(big.NewFloat(1)) != 0 {
+		t.Errorf("expected tuple number as *big.Float but got %#v", tuple[1])
+	}
+}
+
+func TestParseHCLConfig_NestedBlocks(t *testing.T) {
+	config, err := ParseHCLConfig(writeHCL(t, `
+	resource "aws_instance" "web" {
+		instance_type = "t3.micro"
+
+		ebs_block_device {
+			device_name = "/dev/sdf"
+		}
+		ebs_block_device {
+			device_name = "/dev/sdg"
+		}
+		metadata_options {
+			http_tokens = var.http_tokens
+		}
+		dynamic "network_interface" {
+			for_each = var.interfaces
+			content {
+				device_index = network_interface.key
+			}
+		}
+		lifecycle {
+			ignore_changes = [tags]
+		}
+	}
+	`), "aws_instance.web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]any{
+		"instance_type": "t3.micro",
+		"ebs_block_device": []any{
+			map[string]any{"device_name": "/dev/sdf"},
+			map[string]any{"device_name": "/dev/sdg"},
+		},
+		UnknownAttributesKey: []string{"metadata_options", "network_interface"},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("expected %v but got %v", want, config)
+	}
+}
+
+func TestParseStateFile_ResourceAddressFallback(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "terraform.tfstate")
+	state := `{
+		"version": 4,
+		"resources": [
+			{
+				"module": "module.app",
+				"type": "aws_instance",
+				"name": "web",
+				"instances": [
+					{"index_key": "blue", "attributes": {"instance_type": "t2.micro"}},
+					{"index_key": "green", "attributes": {"instance_type": "t3.small"}}
+				]
+			},
+			{
+				"type": "aws_instance",
+				"name": "bastion",
+				"instances": [{"attributes": {"instance_type": "t3.nano"}}]
+			}
+		]
+	}`
+	if err := os.WriteFile(statePath, []byte(state), 0644); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	tests := []struct {
+		identifier   string
+		instanceType string
+		expectError  bool
+	}{
+		{identifier: `module.app.aws_instance.web["green"]`, instanceType: "t3.small"},
+		{identifier: "aws_instance.bastion", instanceType: "t3.nano"},
+		{identifier: "module.app.aws_instance.web", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.identifier, func(t *testing.T) {
+			config, err := ParseStateFile(statePath, tt.identifier, DefaultHTTPState())
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config["instance_type"] != tt.instanceType {
+				t.Errorf("expected instance_type %s but got %v", tt.instanceType, config["instance_type"])
+			}
+		})
+	}
+}
+
+func TestParseState_Reader(t *testing.T) {
+	config, err := ParseState(strings.NewReader(remoteStateFixture), "i-1234567890abcdef0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config["instance_type"] != "t2.micro" {
+		t.Errorf("expected instance_type t2.micro but got %v", config["instance_type"])
+	}
+}
+
+func TestParseStateFile_Stdin(t *testing.T) {
+	stdin = strings.NewReader(remoteStateFixture)
+	defer func() { stdin = os.Stdin }()
+
+	// Stdin is buffered, so every instance of a run can be looked up
+	for i := 0; i < 2; i++ {
+		config, err := ParseStateFile("-", "i-1234567890abcdef0", DefaultHTTPState())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if config["instance_type"] != "t2.micro" {
+			t.Errorf("expected instance_type t2.micro but got %v", config["instance_type"])
+		}
+	}
+}
+
+func TestParseState_Malformed(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       string
+		expectError string
+	}{
+		{name: "null document", state: `null`, expectError: "expected a JSON object, got null"},
+		{name: "truncated document", state: `{"version": 4, "resources": [`, expectError: "failed to parse state file"},
+		{name: "instances not an array", state: `{"version": 4, "resources": [{"type": "aws_instance", "instances": "i-1"}]}`, expectError: "resources[0].instances must be an array, got string"},
+		{name: "null instance", state: `{"version": 4, "resources": [{"type": "aws_instance", "instances": [null]}]}`, expectError: "resources[0].instances[0] must be an object, got null"},
+		{name: "non-string id", state: `{"version": 4, "resources": [{"type": "aws_instance", "instances": [{"attributes": {"id": 1}}]}]}`, expectError: "instance i-1 not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseState(strings.NewReader(tt.state), "i-1")
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("expected error containing %q but got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+// FuzzParseState checks that malformed state only ever produces errors.
+// Run with go test -fuzz=FuzzParseState ./pkg/terraform
+func FuzzParseState(f *testing.F) {
+	f.Add([]byte(remoteStateFixture), "i-1234567890abcdef0")
+	f.Add([]byte(`{"version": 4, "resources": [{"type": "aws_instance", "name": "web", "module": "module.app", "instances": [{"index_key": 1e300, "attributes": {"id": "i-1"}}]}]}`), "module.app.aws_instance.web")
+	f.Add([]byte(`{"version": 4, "resources": [{"type": "aws_instance", "instances": [{"index_key": "blue", "attributes": null}]}]}`), "aws_instance.")
+	f.Add([]byte(`{"version": 3, "resources": [{"type": 1}]}`), "i-1")
+	f.Add([]byte(`{"version": 4, "resources": [`), "i-1")
+	f.Add([]byte(`null`), "i-1")
+
+	f.Fuzz(func(t *testing.T, data []byte, identifier string) {
+		attributes, _, err := parseState(strings.NewReader(string(data)), identifier)
+		if err == nil && attributes == nil {
+			t.Errorf("expected attributes or an error for %q", data)
+		}
+
+		if state, err := decodeState(strings.NewReader(string(data))); err == nil {
+			stateInstanceIDs(state)
+		}
+	})
+}
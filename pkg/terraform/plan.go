@@ -0,0 +1,194 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+)
+
+// PlanDrift is an aws_instance that Terraform found changed outside of
+// Terraform when it refreshed state for a plan
+type PlanDrift struct {
+	Address    string
+	InstanceID string
+	// Attributes are the top-level attributes whose refreshed value differs
+	// from the value in state
+	Attributes []string
+}
+
+// ParsePlanDrift reads the resource_drift section of a plan in the JSON
+// format written by terraform show -json. Only instances changed in place
+// are returned; an instance deleted outside of Terraform has no attributes
+// to compare.
+func ParsePlanDrift(path string) ([]PlanDrift, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plan: %w", err)
+	}
+	defer file.Close()
+
+	return parsePlanDrift(file)
+}
+
+func parsePlanDrift(r io.Reader) ([]PlanDrift, error) {
+	plan, err := decodePlan(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []PlanDrift
+	for _, change := range plan.ResourceDrift {
+		if change == nil || change.Change == nil || change.Type != "aws_instance" || change.Mode != tfjson.ManagedResourceMode {
+			continue
+		}
+		if !change.Change.Actions.Update() {
+			continue
+		}
+
+		before, _ := change.Change.Before.(map[string]any)
+		after, _ := change.Change.After.(map[string]any)
+		id := stringField(before, "id")
+		if id == "" {
+			id = stringField(after, "id")
+		}
+		drifts = append(drifts, PlanDrift{
+			Address:    change.Address,
+			InstanceID: id,
+			Attributes: changedAttributes(before, after),
+		})
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Address < drifts[j].Address
+	})
+	return drifts, nil
+}
+
+// decodePlan decodes a plan in the JSON format written by terraform show -json
+func decodePlan(r io.Reader) (*tfjson.Plan, error) {
+	var raw map[string]any
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	// terraform plan -json streams log messages rather than the plan, and
+	// its resource_drift messages don't say which attributes drifted
+	if _, ok := raw["@message"]; ok {
+		return nil, fmt.Errorf("plan looks like terraform plan -json log output; save the plan with terraform plan -out and pass the output of terraform show -json instead")
+	}
+
+	var plan tfjson.Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// ConfiguredAttributes are the top-level attributes each aws_instance sets
+// in its configuration, by resource address without instance keys (e.g.
+// module.app.aws_instance.web). Attributes missing from it are left to the
+// provider to compute, such as arn or private_ip.
+type ConfiguredAttributes map[string][]string
+
+// Lookup returns the configured attributes of an instance by its resource
+// address, which may have instance keys
+func (c ConfiguredAttributes) Lookup(address string) ([]string, bool) {
+	attributes, ok := c[withoutInstanceKeys(address)]
+	return attributes, ok
+}
+
+// ParsePlanConfiguredAttributes reads the configuration section of a plan
+// in the JSON format written by terraform show -json
+func ParsePlanConfiguredAttributes(path string) (ConfiguredAttributes, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plan: %w", err)
+	}
+	defer file.Close()
+
+	plan, err := decodePlan(file)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Config == nil || plan.Config.RootModule == nil {
+		return nil, fmt.Errorf("plan has no configuration section")
+	}
+
+	configured := make(ConfiguredAttributes)
+	addConfiguredAttributes(configured, plan.Config.RootModule, "")
+	return configured, nil
+}
+
+func addConfiguredAttributes(configured ConfiguredAttributes, module *tfjson.ConfigModule, prefix string) {
+	for _, resource := range module.Resources {
+		if resource == nil || resource.Type != "aws_instance" || resource.Mode != tfjson.ManagedResourceMode {
+			continue
+		}
+		attributes := make([]string, 0, len(resource.Expressions))
+		for name := range resource.Expressions {
+			attributes = append(attributes, name)
+		}
+		sort.Strings(attributes)
+		configured[prefix+resource.Address] = attributes
+	}
+	for name, call := range module.ModuleCalls {
+		if call != nil && call.Module != nil {
+			addConfiguredAttributes(configured, call.Module, prefix+"module."+name+".")
+		}
+	}
+}
+
+// withoutInstanceKeys removes the [...] instance keys from a resource
+// address, including those of module instances
+func withoutInstanceKeys(address string) string {
+	var sb strings.Builder
+	depth := 0
+	inString := false
+	for i := 0; i < len(address); i++ {
+		c := address[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"' && depth > 0:
+			inString = true
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// changedAttributes returns the sorted top-level keys whose values differ
+// between before and after
+func changedAttributes(before, after map[string]any) []string {
+	changed := []string{}
+	for key, value := range before {
+		if !reflect.DeepEqual(value, after[key]) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok && after[key] != nil {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,162 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const planWithDrift = `{
+  "format_version": "1.2",
+  "resource_drift": [
+    {
+      "address": "aws_instance.web",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "change": {
+        "actions": ["update"],
+        "before": {"id": "i-123", "instance_type": "t2.micro", "tags": {"Name": "web"}, "monitoring": false},
+        "after": {"id": "i-123", "instance_type": "t2.small", "tags": {"Name": "web", "Owner": "ops"}, "monitoring": false, "ipv6_addresses": null}
+      }
+    },
+    {
+      "address": "aws_instance.gone",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "gone",
+      "change": {"actions": ["delete"], "before": {"id": "i-456"}, "after": null}
+    },
+    {
+      "address": "aws_s3_bucket.logs",
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "logs",
+      "change": {"actions": ["update"], "before": {"id": "logs", "acl": "private"}, "after": {"id": "logs", "acl": "public-read"}}
+    }
+  ]
+}`
+
+func TestParsePlanDrift(t *testing.T) {
+	drifts, err := parsePlanDrift(strings.NewReader(planWithDrift))
+	if err != nil {
+		t.Fatalf("parsePlanDrift() error = %v", err)
+	}
+
+	want := []PlanDrift{{
+		Address:    "aws_instance.web",
+		InstanceID: "i-123",
+		Attributes: []string{"instance_type", "tags"},
+	}}
+	if !reflect.DeepEqual(drifts, want) {
+		t.Errorf("parsePlanDrift() = %+v, want %+v", drifts, want)
+	}
+}
+
+func TestParsePlanDrift_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    string
+		wantErr string
+	}{
+		{"not JSON", "plan", "failed to parse plan"},
+		{"plan -json log output", `{"@level":"info","@message":"Terraform 1.9.0","type":"version"}`, "terraform show -json"},
+		{"missing format version", `{"resource_drift": []}`, "format version is missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePlanDrift(strings.NewReader(tt.plan))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parsePlanDrift() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+const planWithConfiguration = `{
+  "format_version": "1.2",
+  "configuration": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "aws_instance.web",
+          "mode": "managed",
+          "type": "aws_instance",
+          "name": "web",
+          "expressions": {
+            "ami": {"constant_value": "ami-123"},
+            "instance_type": {"references": ["var.instance_type"]},
+            "root_block_device": [{"volume_size": {"constant_value": 20}}]
+          },
+          "count_expression": {"constant_value": 2}
+        },
+        {
+          "address": "data.aws_instance.lookup",
+          "mode": "data",
+          "type": "aws_instance",
+          "name": "lookup",
+          "expressions": {"instance_id": {"constant_value": "i-123"}}
+        }
+      ],
+      "module_calls": {
+        "app": {
+          "source": "./app",
+          "module": {
+            "resources": [
+              {
+                "address": "aws_instance.server",
+                "mode": "managed",
+                "type": "aws_instance",
+                "name": "server",
+                "expressions": {"tags": {"constant_value": {"Name": "app"}}}
+              }
+            ]
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestParsePlanConfiguredAttributes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(planWithConfiguration), 0644); err != nil {
+		t.Fatalf("Failed to write plan: %v", err)
+	}
+
+	configured, err := ParsePlanConfiguredAttributes(path)
+	if err != nil {
+		t.Fatalf("ParsePlanConfiguredAttributes() error = %v", err)
+	}
+
+	tests := []struct {
+		address string
+		want    []string
+		wantOK  bool
+	}{
+		{"aws_instance.web[1]", []string{"ami", "instance_type", "root_block_device"}, true},
+		{`module.app["blue"].aws_instance.server`, []string{"tags"}, true},
+		{"aws_instance.lookup", nil, false},
+		{"aws_instance.other", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := configured.Lookup(tt.address)
+		if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lookup(%q) = %v, %v, want %v, %v", tt.address, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParsePlanConfiguredAttributes_NoConfiguration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(planWithDrift), 0644); err != nil {
+		t.Fatalf("Failed to write plan: %v", err)
+	}
+
+	if _, err := ParsePlanConfiguredAttributes(path); err == nil {
+		t.Error("expected an error for a plan without configuration")
+	}
+}
@@ -0,0 +1,109 @@
+package terraform
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPStateConfig controls how state files are fetched from http(s) URLs
+type HTTPStateConfig struct {
+	Username string
+	Password string
+	// Token is sent as a bearer token instead of basic auth when set
+	Token   string
+	Timeout time.Duration
+}
+
+// DefaultHTTPState fetches state URLs with a 30 second timeout and the
+// credentials in the TF_HTTP_USERNAME and TF_HTTP_PASSWORD environment
+// variables used by Terraform's http backend, which is also where empty
+// credentials of any HTTPStateConfig fall back to
+func DefaultHTTPState() HTTPStateConfig {
+	return HTTPStateConfig{Timeout: 30 * time.Second}
+}
+
+func isRemoteState(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// stdin is read, once, for the state path "-"
+var (
+	stdin          io.Reader = os.Stdin
+	stdinStateOnce sync.Once
+	stdinState     []byte
+	stdinStateErr  error
+)
+
+// readStdinState buffers stdin so every instance of a run can parse it
+func readStdinState() (io.ReadCloser, error) {
+	stdinStateOnce.Do(func() {
+		stdinState, stdinStateErr = io.ReadAll(stdin)
+	})
+	if stdinStateErr != nil {
+		return nil, fmt.Errorf("failed to read state from stdin: %w", stdinStateErr)
+	}
+	return io.NopCloser(bytes.NewReader(stdinState)), nil
+}
+
+func openState(path string, httpState HTTPStateConfig) (io.ReadCloser, error) {
+	if path == "-" {
+		return readStdinState()
+	}
+	if !isRemoteState(path) {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open state file: %w", err)
+		}
+		return file, nil
+	}
+
+	return fetchRemoteState(path, httpState)
+}
+
+func fetchRemoteState(url string, cfg HTTPStateConfig) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state request: %w", err)
+	}
+
+	if cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+		return doStateRequest(req, cfg)
+	}
+
+	username := cfg.Username
+	if username == "" {
+		username = os.Getenv("TF_HTTP_USERNAME")
+	}
+	password := cfg.Password
+	if password == "" {
+		password = os.Getenv("TF_HTTP_PASSWORD")
+	}
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	return doStateRequest(req, cfg)
+}
+
+func doStateRequest(req *http.Request, cfg HTTPStateConfig) (io.ReadCloser, error) {
+	url := req.URL.String()
+	client := &http.Client{Timeout: cfg.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote state from %s: %w", url, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to fetch remote state from %s: unexpected status %s", url, resp.Status)
+	}
+
+	return resp.Body, nil
+}
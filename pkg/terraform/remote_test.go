@@ -0,0 +1,55 @@
+package terraform
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const remoteStateFixture = `{
+	"version": 4,
+	"resources": [
+		{
+			"type": "aws_instance",
+			"name": "web",
+			"instances": [
+				{"attributes": {"id": "i-1234567890abcdef0", "instance_type": "t2.micro"}}
+			]
+		}
+	]
+}`
+
+func TestParseStateFile_HTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "terraform" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(remoteStateFixture))
+	}))
+	defer server.Close()
+
+	t.Setenv("TF_HTTP_USERNAME", "terraform")
+	t.Setenv("TF_HTTP_PASSWORD", "secret")
+
+	config, err := ParseStateFile(server.URL+"/state", "i-1234567890abcdef0", DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config["instance_type"] != "t2.micro" {
+		t.Errorf("expected instance_type t2.micro but got %v", config["instance_type"])
+	}
+}
+
+func TestParseStateFile_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := ParseStateFile(server.URL+"/state", "i-1234567890abcdef0", DefaultHTTPState())
+	if err == nil {
+		t.Fatal("expected error for non-200 response but got none")
+	}
+}
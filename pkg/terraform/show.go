@@ -0,0 +1,95 @@
+package terraform
+
+import "fmt"
+
+// isShowOutput reports whether a decoded document is `terraform show -json`
+// output, which has values rather than a state file's version and resources
+func isShowOutput(doc map[string]any) bool {
+	_, hasVersion := doc["version"]
+	_, hasValues := doc["values"]
+	return !hasVersion && hasValues
+}
+
+// stateFromShowOutput converts `terraform show -json` output to the shape of a
+// state file. Its resources are nested under values.root_module and its
+// child_modules, one entry per instance; they are regrouped into resources
+// with instances, as state files hold them.
+func stateFromShowOutput(doc map[string]any) (map[string]any, error) {
+	state := map[string]any{
+		"version":           float64(4),
+		"terraform_version": doc["terraform_version"],
+		"resources":         []any{},
+	}
+	values, err := asObject(doc["values"], `"values"`)
+	if err != nil {
+		return nil, err
+	}
+	rootModule, ok := values["root_module"]
+	if !ok {
+		return state, nil
+	}
+
+	var resources []any
+	byKey := make(map[string]map[string]any)
+	if err := addShowModule(rootModule, "values.root_module", &resources, byKey); err != nil {
+		return nil, err
+	}
+	if resources != nil {
+		state["resources"] = resources
+	}
+	return state, nil
+}
+
+// addShowModule adds the resource instances of a module in show output, and
+// of its child modules, to resources
+func addShowModule(rawModule any, path string, resources *[]any, byKey map[string]map[string]any) error {
+	module, err := asObject(rawModule, path)
+	if err != nil {
+		return err
+	}
+	moduleAddress := stringField(module, "address")
+
+	rawResources, _ := module["resources"].([]any)
+	for i, rawResource := range rawResources {
+		resourcePath := fmt.Sprintf("%s.resources[%d]", path, i)
+		resource, err := asObject(rawResource, resourcePath)
+		if err != nil {
+			return err
+		}
+		attributes, err := asObject(resource["values"], resourcePath+".values")
+		if err != nil {
+			return err
+		}
+
+		key := moduleAddress + "|" + stringField(resource, "mode") + "|" + stringField(resource, "type") + "|" + stringField(resource, "name")
+		entry, ok := byKey[key]
+		if !ok {
+			entry = map[string]any{
+				"mode":      resource["mode"],
+				"type":      resource["type"],
+				"name":      resource["name"],
+				"provider":  resource["provider_name"],
+				"instances": []any{},
+			}
+			if moduleAddress != "" {
+				entry["module"] = moduleAddress
+			}
+			byKey[key] = entry
+			*resources = append(*resources, entry)
+		}
+
+		instance := map[string]any{"attributes": attributes}
+		if index, ok := resource["index"]; ok {
+			instance["index_key"] = index
+		}
+		entry["instances"] = append(entry["instances"].([]any), instance)
+	}
+
+	childModules, _ := module["child_modules"].([]any)
+	for i, child := range childModules {
+		if err := addShowModule(child, fmt.Sprintf("%s.child_modules[%d]", path, i), resources, byKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
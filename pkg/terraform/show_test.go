@@ -0,0 +1,61 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/katungi/aws-terror/aws"
+)
+
+func TestBuildStateIndex_ShowOutput(t *testing.T) {
+	index, err := BuildStateIndex("testdata/show.json", 1, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("BuildStateIndex() error = %v", err)
+	}
+
+	attributes, address, err := index.Lookup("i-0a1b2c3d4e5f60001")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if address != "aws_instance.web[0]" || attributes["instance_type"] != "t3.micro" {
+		t.Errorf("Lookup() = %v, %q, want the first web instance", attributes, address)
+	}
+	if ids := attributes[aws.ElasticIPsAttribute]; !reflect.DeepEqual(ids, []string{"eipalloc-0123"}) {
+		t.Errorf("%s = %v, want the associated aws_eip", aws.ElasticIPsAttribute, ids)
+	}
+
+	attributes, address, err = index.Lookup(`module.app.aws_instance.api["blue"]`)
+	if err != nil {
+		t.Fatalf("Lookup() by address error = %v", err)
+	}
+	if address != `module.app.aws_instance.api["blue"]` || attributes["instance_type"] != "m5.large" {
+		t.Errorf("Lookup() by address = %v, %q, want the module instance", attributes, address)
+	}
+}
+
+func TestStateResources_ShowOutput(t *testing.T) {
+	resources, err := StateResources("testdata/show.json", DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("StateResources() error = %v", err)
+	}
+
+	expected := []StateResource{
+		{ID: "i-0a1b2c3d4e5f60009", Address: "aws_instance.bastion"},
+		{ID: "i-0a1b2c3d4e5f60001", Address: "aws_instance.web[0]"},
+		{ID: "i-0a1b2c3d4e5f60002", Address: "aws_instance.web[1]"},
+		{ID: "i-0a1b2c3d4e5f60003", Address: `module.app.aws_instance.api["blue"]`},
+	}
+	if !reflect.DeepEqual(resources, expected) {
+		t.Errorf("StateResources() = %v, want %v", resources, expected)
+	}
+}
+
+func TestDecodeState_ShowOutputWithoutResources(t *testing.T) {
+	resources, err := StateResources("testdata/show_empty.json", DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("StateResources() error = %v", err)
+	}
+	if len(resources) != 0 {
+		t.Errorf("StateResources() = %v, want none", resources)
+	}
+}
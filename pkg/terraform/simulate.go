@@ -0,0 +1,142 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+// SimulateDrift compares an instance across two state files without
+// contacting AWS. The source state plays the role of the live (AWS) side
+// and the target state the Terraform side of the comparison.
+func SimulateDrift(sourceStatePath, targetStatePath, instanceID string, opts drift.Options, httpState HTTPStateConfig) (map[string]drift.DriftDetail, error) {
+	sourceConfig, err := ParseStateFile(sourceStatePath, instanceID, httpState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source state: %w", err)
+	}
+
+	targetConfig, err := ParseStateFile(targetStatePath, instanceID, httpState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target state: %w", err)
+	}
+
+	// Attributes unknown on either side can't be compared
+	unknown := append(TakeUnknownAttributes(sourceConfig), TakeUnknownAttributes(targetConfig)...)
+	drifts, err := drift.DetectDrift(sourceConfig, targetConfig, unionKeys(sourceConfig, targetConfig), opts)
+	drift.RemoveUnchecked(drifts, unknown)
+	return drifts, err
+}
+
+// SimulateHCLDrift compares the same resource across two HCL configurations,
+// e.g. a feature branch against main. The resource is identified either by
+// its id attribute or by its address (aws_instance.<name>).
+func SimulateHCLDrift(sourceConfigPath, targetConfigPath, resource string, opts drift.Options) (map[string]drift.DriftDetail, error) {
+	sourceConfig, err := ParseHCLConfig(sourceConfigPath, resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source config: %w", err)
+	}
+
+	targetConfig, err := ParseHCLConfig(targetConfigPath, resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target config: %w", err)
+	}
+
+	// Attributes unknown on either side can't be compared
+	unknown := append(TakeUnknownAttributes(sourceConfig), TakeUnknownAttributes(targetConfig)...)
+	drifts, err := drift.DetectDrift(sourceConfig, targetConfig, unionKeys(sourceConfig, targetConfig), opts)
+	drift.RemoveUnchecked(drifts, unknown)
+	return drifts, err
+}
+
+// ResourceStatus says how a resource differs between two states
+type ResourceStatus string
+
+const (
+	ResourceChanged ResourceStatus = "changed"
+	ResourceAdded   ResourceStatus = "added"
+	ResourceRemoved ResourceStatus = "removed"
+)
+
+// ResourceDiff is how one aws_instance differs between two states. Drifts is
+// only set for changed resources.
+type ResourceDiff struct {
+	Address string
+	ID      string
+	Status  ResourceStatus
+	Drifts  map[string]drift.DriftDetail
+}
+
+// SimulateStateDiff compares every aws_instance in two state files, e.g.
+// before and after a release, matching resources by address so replaced
+// instances are compared too. Resources without changes are left out; the
+// rest are returned ordered by address.
+func SimulateStateDiff(sourceStatePath, targetStatePath string, opts drift.Options, httpState HTTPStateConfig) ([]ResourceDiff, error) {
+	source, err := stateInstancesByAddress(sourceStatePath, httpState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source state: %w", err)
+	}
+	target, err := stateInstancesByAddress(targetStatePath, httpState)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target state: %w", err)
+	}
+
+	var diffs []ResourceDiff
+	for address, sourceConfig := range source {
+		targetConfig, ok := target[address]
+		if !ok {
+			diffs = append(diffs, ResourceDiff{Address: address, ID: stringField(sourceConfig, "id"), Status: ResourceRemoved})
+			continue
+		}
+
+		drifts, err := drift.DetectDrift(sourceConfig, targetConfig, unionKeys(sourceConfig, targetConfig), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s: %w", address, err)
+		}
+		if len(drifts) > 0 {
+			diffs = append(diffs, ResourceDiff{Address: address, ID: stringField(targetConfig, "id"), Status: ResourceChanged, Drifts: drifts})
+		}
+	}
+	for address, targetConfig := range target {
+		if _, ok := source[address]; !ok {
+			diffs = append(diffs, ResourceDiff{Address: address, ID: stringField(targetConfig, "id"), Status: ResourceAdded})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Address < diffs[j].Address })
+	return diffs, nil
+}
+
+// stateInstancesByAddress returns the attributes of every aws_instance in
+// the state files of a state path, keyed by resource address
+func stateInstancesByAddress(path string, httpState HTTPStateConfig) (map[string]map[string]any, error) {
+	states, err := decodeStates(path, httpState)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make(map[string]map[string]any)
+	for _, state := range states {
+		for _, inst := range stateInstances(state, "aws_instance") {
+			instances[resourceAddress(inst.resource, inst.instance)] = inst.attributes
+		}
+	}
+	return instances, nil
+}
+
+func unionKeys(a, b map[string]any) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
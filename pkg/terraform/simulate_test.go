@@ -0,0 +1,131 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+func writeHCL(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write HCL file: %v", err)
+	}
+	return dir
+}
+
+func TestSimulateHCLDrift(t *testing.T) {
+	mainConfig := writeHCL(t, `
+	resource "aws_instance" "web" {
+		instance_type = "t2.micro"
+		ami           = "ami-123"
+	}
+	`)
+	branchConfig := writeHCL(t, `
+	resource "aws_instance" "web" {
+		instance_type = "t3.small"
+		ami           = "ami-123"
+		monitoring    = true
+	}
+	`)
+
+	drifts, err := SimulateHCLDrift(mainConfig, branchConfig, "aws_instance.web", drift.Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(drifts) != 2 {
+		t.Fatalf("expected 2 drifts but got %d: %v", len(drifts), drifts)
+	}
+	if _, ok := drifts["instance_type"]; !ok {
+		t.Error("expected drift in instance_type")
+	}
+	if detail, ok := drifts["monitoring"]; !ok || detail.InAWS {
+		t.Errorf("expected monitoring to exist only in the target config, got %+v", detail)
+	}
+}
+
+func TestSimulateHCLDrift_SkipsUnknownValues(t *testing.T) {
+	mainConfig := writeHCL(t, `
+	resource "aws_instance" "web" {
+		instance_type = "t2.micro"
+		ami           = var.ami_id
+	}
+	`)
+	branchConfig := writeHCL(t, `
+	resource "aws_instance" "web" {
+		instance_type = "t2.micro"
+		ami           = "ami-123"
+	}
+	`)
+
+	drifts, err := SimulateHCLDrift(mainConfig, branchConfig, "aws_instance.web", drift.Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("expected no drift but got %v", drifts)
+	}
+}
+
+func TestSimulateHCLDrift_ResourceNotFound(t *testing.T) {
+	config := writeHCL(t, `
+	resource "aws_instance" "web" {
+		instance_type = "t2.micro"
+	}
+	`)
+
+	if _, err := SimulateHCLDrift(config, config, "aws_instance.db", drift.Options{}); err == nil {
+		t.Error("expected error but got none")
+	}
+}
+
+func writeState(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+	return path
+}
+
+func TestSimulateStateDiff(t *testing.T) {
+	before := writeState(t, `{"version": 4, "resources": [
+		{"type": "aws_instance", "name": "web", "instances": [{"attributes": {"id": "i-1", "instance_type": "t2.micro"}}]},
+		{"type": "aws_instance", "name": "db", "instances": [{"attributes": {"id": "i-2", "instance_type": "r5.large"}}]},
+		{"type": "aws_instance", "name": "old", "instances": [{"attributes": {"id": "i-3"}}]}
+	]}`)
+	after := writeState(t, `{"version": 4, "resources": [
+		{"type": "aws_instance", "name": "web", "instances": [{"attributes": {"id": "i-1", "instance_type": "t3.small"}}]},
+		{"type": "aws_instance", "name": "db", "instances": [{"attributes": {"id": "i-2", "instance_type": "r5.large"}}]},
+		{"type": "aws_instance", "name": "new", "instances": [{"attributes": {"id": "i-4"}}]}
+	]}`)
+
+	diffs, err := SimulateStateDiff(before, after, drift.Options{}, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		address string
+		status  ResourceStatus
+	}{
+		{"aws_instance.new", ResourceAdded},
+		{"aws_instance.old", ResourceRemoved},
+		{"aws_instance.web", ResourceChanged},
+	}
+	if len(diffs) != len(expected) {
+		t.Fatalf("expected %d diffs but got %d: %+v", len(expected), len(diffs), diffs)
+	}
+	for i, want := range expected {
+		if diffs[i].Address != want.address || diffs[i].Status != want.status {
+			t.Errorf("diff %d: expected %s %s but got %s %s", i, want.address, want.status, diffs[i].Address, diffs[i].Status)
+		}
+	}
+	if _, ok := diffs[2].Drifts["instance_type"]; !ok {
+		t.Errorf("expected drift in instance_type, got %v", diffs[2].Drifts)
+	}
+}
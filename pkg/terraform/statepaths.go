@@ -0,0 +1,109 @@
+package terraform
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ExpandStatePaths splits a state path into the state files it names: a
+// comma-separated list of paths, URLs or "-", where local paths may be globs
+// (e.g. "states/*.tfstate")
+func ExpandStatePaths(statePath string) ([]string, error) {
+	var paths []string
+	for _, path := range strings.Split(statePath, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if path == "-" || isRemoteState(path) || !strings.ContainsAny(path, "*?[") {
+			paths = append(paths, path)
+			continue
+		}
+
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid state glob %q: %w", path, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no state files match %q", path)
+		}
+		paths = append(paths, matches...)
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no state file given")
+	}
+	return paths, nil
+}
+
+// defaultStateParseConcurrency bounds how many state files are read and
+// decoded at once
+const defaultStateParseConcurrency = 4
+
+// decodeStates decodes every state file a state path names
+func decodeStates(statePath string, httpState HTTPStateConfig) ([]map[string]any, error) {
+	paths, err := ExpandStatePaths(statePath)
+	if err != nil {
+		return nil, err
+	}
+	return decodeStateFiles(paths, defaultStateParseConcurrency, httpState)
+}
+
+// decodeStateFiles decodes state files concurrently, at most concurrency at
+// a time, returning them in the order of paths
+func decodeStateFiles(paths []string, concurrency int, httpState HTTPStateConfig) ([]map[string]any, error) {
+	states := make([]map[string]any, len(paths))
+	var g errgroup.Group
+	g.SetLimit(max(concurrency, 1))
+	for i, path := range paths {
+		g.Go(func() error {
+			file, err := openState(path, httpState)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			state, err := decodeState(file)
+			if err != nil {
+				if len(paths) > 1 {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				return err
+			}
+			// Each goroutine writes only its own element
+			states[i] = state
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// mergeStateAttributes adds the attributes an instance has in another state
+// file to those already found, failing if the two files disagree
+func mergeStateAttributes(merged, attributes map[string]any, instanceID, mergedPath, path string) error {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		existing, ok := merged[key]
+		if !ok {
+			merged[key] = attributes[key]
+			continue
+		}
+		if !reflect.DeepEqual(existing, attributes[key]) {
+			return fmt.Errorf("instance %s has conflicting %s in %s and %s", instanceID, key, mergedPath, path)
+		}
+	}
+	return nil
+}
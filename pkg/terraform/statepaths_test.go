@@ -0,0 +1,109 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeStateFile(t *testing.T, dir, name, resources string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	state := `{"version": 4, "resources": [` + resources + `]}`
+	if err := os.WriteFile(path, []byte(state), 0644); err != nil {
+		t.Fatalf("Failed to write state: %v", err)
+	}
+	return path
+}
+
+func instanceResource(name, attributes string) string {
+	return `{"mode": "managed", "type": "aws_instance", "name": "` + name + `", "instances": [{"attributes": ` + attributes + `}]}`
+}
+
+func TestExpandStatePaths(t *testing.T) {
+	dir := t.TempDir()
+	a := writeStateFile(t, dir, "a.tfstate", "")
+	b := writeStateFile(t, dir, "b.tfstate", "")
+
+	tests := []struct {
+		name      string
+		statePath string
+		want      []string
+		wantErr   bool
+	}{
+		{"single path", a, []string{a}, false},
+		{"comma list", a + ", " + b, []string{a, b}, false},
+		{"glob", filepath.Join(dir, "*.tfstate"), []string{a, b}, false},
+		{"stdin and URL are kept", "-,https://example.com/state", []string{"-", "https://example.com/state"}, false},
+		{"glob without matches", filepath.Join(dir, "*.json"), nil, true},
+		{"empty", ",", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandStatePaths(tt.statePath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandStatePaths() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandStatePaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStateFile_MultipleStates(t *testing.T) {
+	dir := t.TempDir()
+	web := writeStateFile(t, dir, "web.tfstate", instanceResource("web", `{"id": "i-web", "instance_type": "t3.micro"}`))
+	db := writeStateFile(t, dir, "db.tfstate", instanceResource("db", `{"id": "i-db", "instance_type": "r5.large"}`))
+	// A partial copy of web, e.g. left behind by a state migration
+	partial := writeStateFile(t, dir, "partial.tfstate", instanceResource("web", `{"id": "i-web", "ami": "ami-123"}`))
+
+	attributes, address, err := ParseStateFileWithAddress(strings.Join([]string{web, db, partial}, ","), "i-web", DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("ParseStateFileWithAddress() error = %v", err)
+	}
+	if address != "aws_instance.web" {
+		t.Errorf("address = %q, want aws_instance.web", address)
+	}
+	if attributes["instance_type"] != "t3.micro" || attributes["ami"] != "ami-123" {
+		t.Errorf("attributes = %v, want instance_type and ami merged", attributes)
+	}
+
+	attributes, err = ParseStateFile(filepath.Join(dir, "*.tfstate"), "i-db", DefaultHTTPState())
+	if err != nil || attributes["instance_type"] != "r5.large" {
+		t.Errorf("ParseStateFile() = %v, %v, want i-db from db.tfstate", attributes, err)
+	}
+
+	_, err = ParseStateFile(web+","+db, "i-missing", DefaultHTTPState())
+	if err == nil || !strings.Contains(err.Error(), "instance i-missing not found in Terraform state") {
+		t.Errorf("ParseStateFile() error = %v, want not found", err)
+	}
+}
+
+func TestParseStateFile_ConflictingStates(t *testing.T) {
+	dir := t.TempDir()
+	a := writeStateFile(t, dir, "a.tfstate", instanceResource("web", `{"id": "i-web", "instance_type": "t3.micro"}`))
+	b := writeStateFile(t, dir, "b.tfstate", instanceResource("web", `{"id": "i-web", "instance_type": "t3.large"}`))
+
+	_, err := ParseStateFile(a+","+b, "i-web", DefaultHTTPState())
+	if err == nil || !strings.Contains(err.Error(), "conflicting instance_type") {
+		t.Errorf("ParseStateFile() error = %v, want conflicting instance_type", err)
+	}
+}
+
+func TestStateInstanceIDs_MultipleStates(t *testing.T) {
+	dir := t.TempDir()
+	a := writeStateFile(t, dir, "a.tfstate", instanceResource("web", `{"id": "i-web"}`))
+	b := writeStateFile(t, dir, "b.tfstate", instanceResource("db", `{"id": "i-db"}`)+","+instanceResource("web", `{"id": "i-web"}`))
+
+	ids, err := StateInstanceIDs(a+","+b, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("StateInstanceIDs() error = %v", err)
+	}
+	if want := []string{"i-db", "i-web"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("StateInstanceIDs() = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,99 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultTFCAddress is the Terraform Cloud API address; set TFE_ADDRESS or
+// TFCWorkspace.Address for Terraform Enterprise
+const DefaultTFCAddress = "https://app.terraform.io"
+
+// TFCWorkspace identifies a Terraform Cloud or Enterprise workspace. An empty
+// Address or Token falls back to the TFE_ADDRESS and TFE_TOKEN environment
+// variables.
+type TFCWorkspace struct {
+	Address      string
+	Organization string
+	Name         string
+	Token        string
+}
+
+// UseTFCState looks up the current state version of a workspace, calling
+// the API with the timeout of httpState. It returns the state download URL
+// and httpState with the workspace token, to pass to ParseStateFile.
+func UseTFCState(workspace TFCWorkspace, httpState HTTPStateConfig) (string, HTTPStateConfig, error) {
+	if workspace.Address == "" {
+		workspace.Address = os.Getenv("TFE_ADDRESS")
+	}
+	if workspace.Address == "" {
+		workspace.Address = DefaultTFCAddress
+	}
+	if workspace.Token == "" {
+		workspace.Token = os.Getenv("TFE_TOKEN")
+	}
+	if workspace.Organization == "" || workspace.Name == "" {
+		return "", httpState, fmt.Errorf("terraform cloud organization and workspace are required")
+	}
+	if workspace.Token == "" {
+		return "", httpState, fmt.Errorf("terraform cloud token is required (set TFE_TOKEN)")
+	}
+	httpState.Token = workspace.Token
+
+	base := strings.TrimSuffix(workspace.Address, "/") + "/api/v2"
+
+	var ws struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	wsURL := fmt.Sprintf("%s/organizations/%s/workspaces/%s", base, url.PathEscape(workspace.Organization), url.PathEscape(workspace.Name))
+	if err := getTFC(wsURL, httpState, &ws); err != nil {
+		return "", httpState, fmt.Errorf("failed to look up workspace %s/%s: %w", workspace.Organization, workspace.Name, err)
+	}
+
+	var stateVersion struct {
+		Data struct {
+			Attributes struct {
+				DownloadURL string `json:"hosted-state-download-url"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	svURL := fmt.Sprintf("%s/workspaces/%s/current-state-version", base, url.PathEscape(ws.Data.ID))
+	if err := getTFC(svURL, httpState, &stateVersion); err != nil {
+		return "", httpState, fmt.Errorf("failed to get current state version of %s/%s: %w", workspace.Organization, workspace.Name, err)
+	}
+
+	downloadURL := stateVersion.Data.Attributes.DownloadURL
+	if downloadURL == "" {
+		return "", httpState, fmt.Errorf("workspace %s/%s has no downloadable state", workspace.Organization, workspace.Name)
+	}
+	return downloadURL, httpState, nil
+}
+
+// getTFC calls the Terraform Cloud API with the token and timeout of
+// httpState and decodes the JSON:API response
+func getTFC(apiURL string, httpState HTTPStateConfig, v any) error {
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+httpState.Token)
+	req.Header.Set("Accept", "application/vnd.api+json")
+
+	client := &http.Client{Timeout: httpState.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,62 @@
+package terraform
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUseTFCState(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tfc-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.URL.Path != "/state/sv-456" && r.Header.Get("Accept") != "application/vnd.api+json" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v2/organizations/acme/workspaces/prod":
+			fmt.Fprint(w, `{"data": {"id": "ws-123"}}`)
+		case "/api/v2/workspaces/ws-123/current-state-version":
+			fmt.Fprintf(w, `{"data": {"attributes": {"hosted-state-download-url": "%s/state/sv-456"}}}`, server.URL)
+		case "/state/sv-456":
+			fmt.Fprint(w, remoteStateFixture)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("TFE_TOKEN", "tfc-token")
+
+	stateURL, httpState, err := UseTFCState(TFCWorkspace{Address: server.URL, Organization: "acme", Name: "prod"}, DefaultHTTPState())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if httpState.Token != "tfc-token" {
+		t.Errorf("expected the workspace token in the HTTP state config but got %q", httpState.Token)
+	}
+
+	config, err := ParseStateFile(stateURL, "i-1234567890abcdef0", httpState)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config["instance_type"] != "t2.micro" {
+		t.Errorf("expected instance_type t2.micro but got %v", config["instance_type"])
+	}
+}
+
+func TestUseTFCState_MissingToken(t *testing.T) {
+	t.Setenv("TFE_TOKEN", "")
+
+	_, _, err := UseTFCState(TFCWorkspace{Organization: "acme", Name: "prod"}, DefaultHTTPState())
+	if err == nil {
+		t.Fatal("expected error without a token but got none")
+	}
+}
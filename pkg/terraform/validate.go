@@ -0,0 +1,71 @@
+package terraform
+
+import "fmt"
+
+// supportedStateVersions are the state format versions whose structure
+// ParseStateFile understands. Version 3 (Terraform 0.11 and earlier) keeps
+// resources under modules[].resources and is not supported.
+var supportedStateVersions = map[float64]bool{4: true}
+
+// validateState checks the structure of a decoded state file and returns an
+// error describing the first problem found and where it is
+func validateState(state map[string]any) error {
+	rawVersion, ok := state["version"]
+	if !ok {
+		if _, hasValues := state["values"]; hasValues {
+			return fmt.Errorf("invalid state file: this looks like `terraform show -json` output, not a state file")
+		}
+		return fmt.Errorf("invalid state file: missing top-level \"version\" field; is this a Terraform state file?")
+	}
+
+	version, ok := rawVersion.(float64)
+	if !ok {
+		return fmt.Errorf("invalid state file: \"version\" must be a number, got %s", jsonType(rawVersion))
+	}
+	if !supportedStateVersions[version] {
+		return fmt.Errorf("invalid state file: unsupported state version %v", version)
+	}
+
+	rawResources, ok := state["resources"]
+	if !ok {
+		return fmt.Errorf("invalid state file: missing top-level \"resources\" field")
+	}
+	resources, err := asArray(rawResources, `"resources"`)
+	if err != nil {
+		return err
+	}
+
+	for i, res := range resources {
+		resource, err := asObject(res, fmt.Sprintf("resources[%d]", i))
+		if err != nil {
+			return err
+		}
+		if _, ok := resource["type"].(string); !ok {
+			return fmt.Errorf("invalid state file: resources[%d] is missing a string \"type\"", i)
+		}
+
+		rawInstances, ok := resource["instances"]
+		if !ok {
+			continue
+		}
+		instances, err := asArray(rawInstances, fmt.Sprintf("resources[%d].instances", i))
+		if err != nil {
+			return err
+		}
+
+		for j, inst := range instances {
+			path := fmt.Sprintf("resources[%d].instances[%d]", i, j)
+			instance, err := asObject(inst, path)
+			if err != nil {
+				return err
+			}
+			if rawAttrs, ok := instance["attributes"]; ok {
+				if _, err := asObject(rawAttrs, path+".attributes"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
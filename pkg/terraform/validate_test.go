@@ -0,0 +1,81 @@
+package terraform
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateState(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       string
+		expectError string
+	}{
+		{
+			name:  "Valid state",
+			state: `{"version": 4, "resources": [{"type": "aws_instance", "instances": [{"attributes": {"id": "i-1"}}]}]}`,
+		},
+		{
+			name:        "Missing version",
+			state:       `{"resources": []}`,
+			expectError: "missing top-level \"version\"",
+		},
+		{
+			name:        "terraform show -json output",
+			state:       `{"format_version": "1.0", "values": {}}`,
+			expectError: "terraform show -json",
+		},
+		{
+			name:        "Unsupported version",
+			state:       `{"version": 2, "resources": []}`,
+			expectError: "unsupported state version 2",
+		},
+		{
+			name:        "Version 3 with modules",
+			state:       `{"version": 3, "modules": [{"path": ["root"], "resources": {}}]}`,
+			expectError: "unsupported state version 3",
+		},
+		{
+			name:        "Missing resources",
+			state:       `{"version": 4}`,
+			expectError: "missing top-level \"resources\"",
+		},
+		{
+			name:        "Resources not an array",
+			state:       `{"version": 4, "resources": {}}`,
+			expectError: "\"resources\" must be an array",
+		},
+		{
+			name:        "Resource missing type",
+			state:       `{"version": 4, "resources": [{"instances": []}]}`,
+			expectError: "resources[0] is missing a string \"type\"",
+		},
+		{
+			name:        "Attributes not an object",
+			state:       `{"version": 4, "resources": [{"type": "aws_instance", "instances": [{"attributes": []}]}]}`,
+			expectError: "resources[0].instances[0].attributes must be an object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var state map[string]any
+			if err := json.Unmarshal([]byte(tt.state), &state); err != nil {
+				t.Fatalf("failed to unmarshal state: %v", err)
+			}
+
+			err := validateState(state)
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("expected error containing %q but got %v", tt.expectError, err)
+			}
+		})
+	}
+}
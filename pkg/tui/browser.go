@@ -0,0 +1,165 @@
+//go:build tui
+
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+// Run shows the reports in a terminal UI until the user quits. The instance
+// list drills into an instance's drifts, and each drift expands to show its
+// full AWS and Terraform values.
+func Run(reports []drift.Report) error {
+	_, err := tea.NewProgram(newModel(reports), tea.WithAltScreen()).Run()
+	return err
+}
+
+// model is the browser state: the instance list, or the drifts of the
+// selected instance when one is open
+type model struct {
+	reports  []drift.Report
+	selected int // index into reports, or -1 on the instance list
+	cursor   int
+	offset   int
+	expanded map[int]bool // expanded drifts of the open instance
+	height   int
+}
+
+func newModel(reports []drift.Report) model {
+	return model{reports: reports, selected: -1, expanded: map[int]bool{}, height: 24}
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < m.itemCount()-1 {
+				m.cursor++
+			}
+		case "enter", "right", "l":
+			if m.selected < 0 {
+				if len(m.reports) > 0 {
+					m.selected, m.cursor, m.offset = m.cursor, 0, 0
+					m.expanded = map[int]bool{}
+				}
+			} else if m.itemCount() > 0 {
+				m.expanded[m.cursor] = !m.expanded[m.cursor]
+			}
+		case "esc", "backspace", "left", "h":
+			if m.selected >= 0 {
+				m.cursor, m.selected, m.offset = m.selected, -1, 0
+			}
+		}
+	}
+
+	m.scroll()
+	return m, nil
+}
+
+// itemCount is the number of rows the cursor moves over on the current screen
+func (m model) itemCount() int {
+	if m.selected < 0 {
+		return len(m.reports)
+	}
+	return len(m.reports[m.selected].Drifts)
+}
+
+// scroll keeps the cursor row within the visible window
+func (m *model) scroll() {
+	visible := m.visibleRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+}
+
+// visibleRows leaves room for the header and help lines
+func (m model) visibleRows() int {
+	if rows := m.height - 4; rows > 0 {
+		return rows
+	}
+	return 1
+}
+
+func (m model) View() string {
+	var sb strings.Builder
+	if m.selected < 0 {
+		sb.WriteString(fmt.Sprintf("aws-terror: %d instances\n\n", len(m.reports)))
+		for i := m.offset; i < len(m.reports) && i < m.offset+m.visibleRows(); i++ {
+			sb.WriteString(m.row(i, instanceSummary(m.reports[i])))
+		}
+		sb.WriteString("\n↑/↓ move • enter open • q quit\n")
+		return sb.String()
+	}
+
+	report := m.reports[m.selected]
+	sb.WriteString(fmt.Sprintf("%s: %d drifted attributes\n\n", report.InstanceID, len(report.Drifts)))
+	if report.Err != nil {
+		sb.WriteString(fmt.Sprintf("  Error: %v\n", report.Err))
+	}
+	for i := m.offset; i < len(report.Drifts) && i < m.offset+m.visibleRows(); i++ {
+		detail := report.Drifts[i]
+		sb.WriteString(m.row(i, fmt.Sprintf("%-40s %-6s %s", detail.Attribute, detail.Severity, detail.Reason)))
+		if m.expanded[i] {
+			sb.WriteString(indent("AWS: "+formatValue(detail.InAWS, detail.AWSValue), "      "))
+			sb.WriteString(indent("Terraform: "+formatValue(detail.InTerraform, detail.TerraformValue), "      "))
+		}
+	}
+	sb.WriteString("\n↑/↓ move • enter expand • esc back • q quit\n")
+	return sb.String()
+}
+
+func (m model) row(i int, text string) string {
+	marker := "  "
+	if i == m.cursor {
+		marker = "> "
+	}
+	return marker + text + "\n"
+}
+
+// instanceSummary is the instance list row for a report
+func instanceSummary(report drift.Report) string {
+	switch {
+	case report.Err != nil:
+		return fmt.Sprintf("%-22s error: %v", report.InstanceID, report.Err)
+	case !report.HasDrift():
+		return fmt.Sprintf("%-22s no drift", report.InstanceID)
+	}
+	return fmt.Sprintf("%-22s %d drifts (max %s)", report.InstanceID, len(report.Drifts), report.MaxSeverity())
+}
+
+// formatValue renders a drift value with its nested structure
+func formatValue(exists bool, value any) string {
+	if !exists {
+		return "(not set)"
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+func indent(text, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix) + "\n"
+}
@@ -0,0 +1,79 @@
+//go:build tui
+
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/katungi/aws-terror/pkg/drift"
+)
+
+func testReports() []drift.Report {
+	return []drift.Report{
+		drift.NewReport("i-1", nil),
+		drift.NewReport("i-2", map[string]drift.DriftDetail{
+			"instance_type": {InAWS: true, InTerraform: true, AWSValue: "t2.micro", TerraformValue: "t3.micro", Severity: drift.SeverityMedium},
+			"tags": {
+				InAWS: true, InTerraform: true, Severity: drift.SeverityLow,
+				AWSValue:       map[string]string{"Env": "dev"},
+				TerraformValue: map[string]any{"Env": "prod"},
+			},
+		}),
+	}
+}
+
+func press(m tea.Model, keys ...string) tea.Model {
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func TestBrowser_InstanceList(t *testing.T) {
+	view := newModel(testReports()).View()
+
+	assert.Contains(t, view, "> i-1")
+	assert.Contains(t, view, "no drift")
+	assert.Contains(t, view, "2 drifts (max medium)")
+}
+
+func TestBrowser_DrillIntoDrift(t *testing.T) {
+	m := press(newModel(testReports()), "j", "enter", "j", "enter")
+	view := m.View()
+
+	assert.Contains(t, view, "i-2: 2 drifted attributes")
+	assert.Contains(t, view, "> tags")
+	assert.Contains(t, view, `"Env": "prod"`)
+	assert.NotContains(t, view, `"t3.micro"`)
+
+	// Going back returns to the instance that was open
+	m = press(m, "esc")
+	assert.Contains(t, m.View(), "> i-2")
+}
+
+func TestBrowser_Scroll(t *testing.T) {
+	reports := make([]drift.Report, 30)
+	for i := range reports {
+		reports[i] = drift.NewReport("i-"+string(rune('a'+i)), nil)
+	}
+	m, _ := newModel(reports).Update(tea.WindowSizeMsg{Height: 10})
+	for i := 0; i < 20; i++ {
+		m = press(m, "j")
+	}
+
+	view := m.View()
+	assert.Contains(t, view, "> i-"+string(rune('a'+20)))
+	assert.NotContains(t, view, "i-a ")
+}